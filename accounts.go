@@ -52,3 +52,13 @@ type Account struct {
 	Ready            *bool            `json:"ready"`             // Whether or not this account is ready to trade.
 	Hold             Hold             `json:"hold"`
 }
+
+// Decimal parses the amount of the balance.
+func (b AvailableBalance) Decimal() (Decimal, error) {
+	return parseDecimalField("available_balance", &b.Value)
+}
+
+// Decimal parses the amount on hold.
+func (h Hold) Decimal() (Decimal, error) {
+	return parseDecimalField("hold", &h.Value)
+}
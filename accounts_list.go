@@ -43,3 +43,27 @@ func (s *AccountService) List(ctx context.Context, options *AccountListOptions)
 
 	return &accountsResp, err
 }
+
+// All returns a pager over every account of the current user, following the cursor until the last page.
+// The options are not modified.
+func (s *AccountService) All(ctx context.Context, options *AccountListOptions, opts ...PagerOption) *Pager[Account] {
+	var query AccountListOptions
+	if options != nil {
+		query = *options
+	}
+
+	return newPager(ctx, query.Cursor, func(ctx context.Context, cursor *string) ([]Account, *string, error) {
+		query.Cursor = cursor
+
+		resp, err := s.List(ctx, &query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !resp.HasNext {
+			return resp.Accounts, nil, nil
+		}
+
+		return resp.Accounts, resp.Cursor, nil
+	}, opts)
+}
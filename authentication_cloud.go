@@ -8,15 +8,20 @@
 package coinbase
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"math"
 	"math/big"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -27,62 +32,176 @@ const (
 	serviceName    = "retail_rest_api_proxy" // Name of the Coinbase service we are generating the token for.
 	jwtExpiration  = time.Minute * 2         // Coinbase specifies JWTs will expire after two minutes, after which all requests are unauthenticated.
 	creationBuffer = time.Minute * -2        // Minimum buffer required for coinbase to accept an auth tokens issued time.
+
+	// Buffer of tokens issued with a synced clock, for the error of the measured offset and the drift since.
+	syncedCreationBuffer = time.Second * -5
 )
 
 // Handles cloud API key authentication used to access the Advanced Trade API.
 type cloudAuthenticator struct {
-	apiKey     string        // The API key used to authenticate requests.
-	signingKey crypto.Signer // Signing key, used to sign JWTs if using cloud trading keys.
+	apiKey        string            // The API key used to authenticate requests.
+	signingKey    crypto.Signer     // Signing key, used to sign JWTs if using cloud trading keys.
+	signingMethod jwt.SigningMethod // ES256 for EC keys, EdDSA for Ed25519 keys.
+	clock         *clock            // Time the JWTs are issued at, corrected by Client.SyncTime.
 }
 
-func parsePrivateKey(secret string) (*ecdsa.PrivateKey, error) {
+func newCloudAuthenticator(apiKey string, key crypto.Signer, clock *clock) (cloudAuthenticator, error) {
+	if key == nil {
+		return cloudAuthenticator{}, fmt.Errorf("missing signing key")
+	}
+
+	a := cloudAuthenticator{apiKey: apiKey, signingKey: key, clock: clock}
+
+	switch pub := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return cloudAuthenticator{}, fmt.Errorf("unsupported EC curve %s, cloud API keys use P-256", pub.Curve.Params().Name)
+		}
+
+		a.signingMethod = jwt.SigningMethodES256
+
+		// Keys held elsewhere, such as in a KMS, are only reachable through their Sign method.
+		if _, ok := key.(*ecdsa.PrivateKey); !ok {
+			a.signingMethod = signingMethodES256Signer
+		}
+	case ed25519.PublicKey:
+		a.signingMethod = jwt.SigningMethodEdDSA
+	default:
+		return cloudAuthenticator{}, fmt.Errorf("unsupported private key type %T, cloud API keys are EC or Ed25519 keys", pub)
+	}
+
+	return a, nil
+}
+
+// String redacts the signing key, so it never ends up in logs.
+func (a cloudAuthenticator) String() string {
+	return fmt.Sprintf("cloudAuthenticator{apiKey: %s, signingKey: %s}", a.apiKey, redacted)
+}
+
+func (a cloudAuthenticator) GoString() string {
+	return a.String()
+}
+
+// ParsePrivateKey parses the private key of a cloud API key. Accepted are PEM encoded SEC1 EC keys
+// ("EC PRIVATE KEY") and PKCS#8 EC or Ed25519 keys ("PRIVATE KEY"), and the base64 encoded Ed25519
+// keys of newer CDP API keys. Escaped newlines, as found in environment variables, are accepted too.
+// Errors never include the key.
+func ParsePrivateKey(secret string) (crypto.Signer, error) {
+	secret = strings.TrimSpace(strings.ReplaceAll(secret, `\n`, "\n"))
+
 	block, _ := pem.Decode([]byte(secret))
 	if block == nil {
-		return nil, fmt.Errorf("failed to decode private key")
+		return parseEd25519Key(secret)
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse EC private key: %w", err)
+		}
+
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PKCS#8 private key: %w", err)
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type '%s'", block.Type)
 	}
+}
 
-	key, err := x509.ParseECPrivateKey(block.Bytes)
+// parseEd25519Key parses a base64 encoded Ed25519 private key, either the 64 bytes of the seed
+// followed by the public key, as CDP hands them out, or the 32 bytes of the seed alone.
+func parseEd25519Key(secret string) (ed25519.PrivateKey, error) {
+	buf, err := base64.StdEncoding.DecodeString(secret)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, fmt.Errorf("failed to decode private key: not PEM or base64 encoded")
 	}
 
-	return key, nil
+	switch len(buf) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(buf), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(buf[:ed25519.SeedSize])
+
+		if !bytes.Equal(key[ed25519.SeedSize:], buf[ed25519.SeedSize:]) {
+			return nil, fmt.Errorf("failed to decode private key: Ed25519 public key does not match the seed")
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("failed to decode private key: %d bytes is not an Ed25519 key", len(buf))
+	}
 }
 
-// Authenticate adds required cloud API authentication headers to the HTTP request.
-func (a cloudAuthenticator) Authenticate(req *http.Request) error {
+// sign generates a JWT signed with the API secret. The uri claim is only included if one is provided,
+// WebSocket tokens are not bound to a request.
+func (a cloudAuthenticator) sign(uri string) (string, error) {
 	nonce, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
 	if err != nil {
-		return fmt.Errorf("failed to generate JWT nonce: %w", err)
+		return "", fmt.Errorf("failed to generate JWT nonce: %w", err)
 	}
 
-	// If we use the time right now coinbase will reject the token.
-	// Need to add a negative buffer to the time in order for it to be accepted.
+	now := a.clock.now()
+
+	// If we use the time right now coinbase will reject the token when the local clock is ahead.
+	// Need to add a negative buffer to the time in order for it to be accepted, a synced clock only
+	// needs a few seconds. The token still expires two minutes from now.
 	// Super annoying....
-	now := time.Now().Add(creationBuffer)
-
-	t := jwt.NewWithClaims(
-		jwt.SigningMethodES256,
-		jwt.MapClaims{
-			"sub": a.apiKey,
-			"iss": issuer,
-			"iat": now.Unix(),
-			"nbf": now.Unix(),
-			"exp": now.Add(jwtExpiration).Unix(),
-			"aud": serviceName,
-			"uri": fmt.Sprintf("%s %s%s", req.Method, req.URL.Host, req.URL.Path),
-		},
-	)
+	issued := now.Add(creationBuffer)
+	if a.clock.isSynced() {
+		issued = now.Add(syncedCreationBuffer)
+	}
+
+	claims := jwt.MapClaims{
+		"sub": a.apiKey,
+		"iss": issuer,
+		"aud": serviceName,
+		"iat": issued.Unix(),
+		"nbf": issued.Unix(),
+		"exp": now.Add(jwtExpiration).Unix(),
+	}
+
+	if uri != "" {
+		claims["uri"] = uri
+	}
+
+	t := jwt.NewWithClaims(a.signingMethod, claims)
 
 	t.Header["kid"] = a.apiKey
 	t.Header["nonce"] = nonce.String()
 
 	s, err := t.SignedString(a.signingKey)
 	if err != nil {
-		return fmt.Errorf("failed to sign JWT token: %w", err)
+		return "", fmt.Errorf("failed to sign JWT token: %w", err)
+	}
+
+	return s, nil
+}
+
+// Authenticate adds required cloud API authentication headers to the HTTP request.
+func (a cloudAuthenticator) Authenticate(req *http.Request) error {
+	s, err := a.sign(fmt.Sprintf("%s %s%s", req.Method, req.URL.Host, req.URL.Path))
+	if err != nil {
+		return err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+s)
 
 	return nil
 }
+
+// webSocketJWT generates a JWT used to subscribe to the WebSocket feed.
+// Tokens expire after two minutes so a new one is generated for every subscription.
+func (a cloudAuthenticator) webSocketJWT() (string, error) {
+	return a.sign("")
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestCloudAuthenticatorClaims(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		clock  *clock
+		issued time.Duration // Expected offset of iat and nbf from the time of Coinbase's clock.
+	}{
+		{
+			name:   "unsynced clock is backdated",
+			clock:  &clock{},
+			issued: creationBuffer,
+		},
+		{
+			name:   "synced clock keeps a small margin",
+			clock:  &clock{offset: 5 * time.Minute, synced: true},
+			issued: syncedCreationBuffer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := newCloudAuthenticator("organizations/test/apiKeys/test", key, tt.clock)
+			if err != nil {
+				t.Fatalf("failed to create authenticator: %v", err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/accounts", nil)
+
+			err = a.Authenticate(req)
+			if err != nil {
+				t.Fatalf("failed to authenticate: %v", err)
+			}
+
+			now := tt.clock.now()
+
+			claims := jwt.MapClaims{}
+
+			// The token is backdated, verify it at the time it was issued.
+			_, err = jwt.ParseWithClaims(req.Header.Get("Authorization")[len("Bearer "):], claims, func(*jwt.Token) (any, error) {
+				return &key.PublicKey, nil
+			}, jwt.WithTimeFunc(func() time.Time { return now.Add(tt.issued) }))
+			if err != nil {
+				t.Fatalf("failed to verify token: %v", err)
+			}
+
+			for claim, want := range map[string]time.Time{
+				"iat": now.Add(tt.issued),
+				"nbf": now.Add(tt.issued),
+				"exp": now.Add(jwtExpiration),
+			} {
+				got, ok := claims[claim].(float64)
+				if !ok {
+					t.Fatalf("claim %s missing", claim)
+				}
+
+				if diff := time.Unix(int64(got), 0).Sub(want); diff < -time.Second || diff > time.Second {
+					t.Errorf("claim %s is %s off", claim, diff)
+				}
+			}
+
+			if claims["uri"] != "GET api.coinbase.com/api/v3/brokerage/accounts" || claims["aud"] != serviceName {
+				t.Errorf("uri claim %v and aud claim %v", claims["uri"], claims["aud"])
+			}
+		})
+	}
+}
+
+func TestCloudAuthenticatorWebSocketJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	a, err := newCloudAuthenticator("organizations/test/apiKeys/test", key, &clock{})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	token, err := a.webSocketJWT()
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+
+	// The token is backdated, verify it at the time it was issued.
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithTimeFunc(func() time.Time { return time.Now().Add(creationBuffer) }))
+	if err != nil {
+		t.Fatalf("failed to verify token: %v", err)
+	}
+
+	// WebSocket tokens are not bound to a request, but are still issued for the API.
+	if _, ok := claims["uri"]; ok || claims["aud"] != serviceName {
+		t.Fatalf("uri claim %v and aud claim %v, want no uri and aud '%s'", claims["uri"], claims["aud"], serviceName)
+	}
+}
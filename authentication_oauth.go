@@ -0,0 +1,293 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// OAuthEndpoint is the OAuth2 endpoint of Sign In With Coinbase.
+var OAuthEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://login.coinbase.com/oauth2/auth",
+	TokenURL:  "https://login.coinbase.com/oauth2/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// oauthRefreshTimeout bounds the exchange of a refresh token and the save of the new token.
+const oauthRefreshTimeout = 30 * time.Second
+
+// ErrTokenNotFound - the TokenStore holds no token, the user has to authorize the application first.
+var ErrTokenNotFound = errors.New("no OAuth token stored, the user has to authorize the application first")
+
+// TokenStore persists the OAuth2 token of a user. Coinbase rotates refresh tokens, so every refreshed
+// token is saved and the previous one must not be used again. Implementations must be safe for concurrent use.
+type TokenStore interface {
+	Load(ctx context.Context) (*oauth2.Token, error)     // Returns ErrTokenNotFound if no token was saved.
+	Save(ctx context.Context, token *oauth2.Token) error // Replaces the saved token.
+}
+
+// MemoryTokenStore keeps a token in memory, it is lost when the process exits.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore creates a store holding the token, which may be nil.
+func NewMemoryTokenStore(token *oauth2.Token) *MemoryTokenStore {
+	return &MemoryTokenStore{token: token}
+}
+
+func (s *MemoryTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return s.token, nil
+}
+
+func (s *MemoryTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token
+
+	return nil
+}
+
+// FileTokenStore keeps a token in a JSON file readable only by its owner, for CLI tools.
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileTokenStore creates a store keeping the token in the file at the path.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth token file: %w", err)
+	}
+
+	var token oauth2.Token
+
+	// Decoding errors may quote the file, which holds the token.
+	if json.Unmarshal(buf, &token) != nil {
+		return nil, fmt.Errorf("failed to decode OAuth token file '%s'", s.path)
+	}
+
+	return &token, nil
+}
+
+func (s *FileTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode OAuth token: %w", err)
+	}
+
+	// Write a temporary file and rename it, so a crash never leaves a truncated token behind.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save OAuth token: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(buf)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to save OAuth token: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("failed to save OAuth token: %w", err)
+	}
+
+	return nil
+}
+
+// Handles OAuth2 authentication used by applications acting on behalf of Coinbase users.
+// https://docs.cdp.coinbase.com/coinbase-app/docs/coinbase-app-integration
+type oauthAuthenticator struct {
+	config *oauth2.Config
+	store  TokenStore
+
+	mu    sync.Mutex    // Held while refreshing, so a rotated refresh token is only used once.
+	token *oauth2.Token // Last token loaded or refreshed.
+}
+
+// refresher is implemented by authenticators that can recover from a 401 by refreshing their credentials.
+type refresher interface {
+	// refresh replaces the credentials the request was authenticated with, unless they were already replaced.
+	refresh(req *http.Request) error
+}
+
+// String redacts the client secret and the tokens, so they never end up in logs.
+func (a *oauthAuthenticator) String() string {
+	return fmt.Sprintf("oauthAuthenticator{clientID: %s, clientSecret: %s}", a.config.ClientID, redacted)
+}
+
+func (a *oauthAuthenticator) GoString() string {
+	return a.String()
+}
+
+// Authenticate adds the access token of the user to the HTTP request, refreshing it first if it expired.
+func (a *oauthAuthenticator) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	token, err := a.current(req.Context())
+	if err != nil {
+		return err
+	}
+
+	if !token.Valid() {
+		token, err = a.renew(req.Context(), token)
+		if err != nil {
+			return err
+		}
+	}
+
+	token.SetAuthHeader(req)
+
+	return nil
+}
+
+func (a *oauthAuthenticator) refresh(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	token, err := a.current(req.Context())
+	if err != nil {
+		return err
+	}
+
+	// Another request refreshed the token already.
+	if used := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); used != token.AccessToken {
+		return nil
+	}
+
+	_, err = a.renew(req.Context(), token)
+
+	return err
+}
+
+// current returns the last token, loading it from the store the first time. The lock must be held.
+func (a *oauthAuthenticator) current(ctx context.Context) (*oauth2.Token, error) {
+	if a.token != nil {
+		return a.token, nil
+	}
+
+	token, err := a.store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OAuth token: %w", err)
+	}
+
+	a.token = token
+
+	return token, nil
+}
+
+// renew exchanges the refresh token for a new token and saves it. The lock must be held.
+func (a *oauthAuthenticator) renew(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	// Once Coinbase rotated the refresh token, the new one has to be saved even if ctx was cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), oauthRefreshTimeout)
+	defer cancel()
+
+	// Another process sharing the store may have refreshed the token, using the cached refresh token
+	// again would fail as it was rotated.
+	stored, err := a.store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OAuth token: %w", err)
+	}
+
+	if stored.AccessToken != token.AccessToken && stored.Valid() {
+		a.token = stored
+
+		return stored, nil
+	}
+
+	if stored.RefreshToken == "" {
+		return nil, fmt.Errorf("OAuth token expired and has no refresh token")
+	}
+
+	// A token without an access token is always refreshed by the token source.
+	renewed, err := a.config.TokenSource(ctx, &oauth2.Token{RefreshToken: stored.RefreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh OAuth token: %w", err)
+	}
+
+	err = a.store.Save(ctx, renewed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save refreshed OAuth token: %w", err)
+	}
+
+	a.token = renewed
+
+	return renewed, nil
+}
+
+// NewOAuthConfig creates the OAuth2 configuration of an application registered with Sign In With Coinbase.
+func NewOAuthConfig(clientID string, clientSecret string, redirectURL string, scopes ...string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Endpoint:     OAuthEndpoint,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+	}
+}
+
+// NewWithOAuth creates a new Coinbase Advanced Trade REST API client, acting on behalf of the user whose
+// token is kept in the store. The token is refreshed when it expires or Coinbase rejects it, and every
+// refreshed token is saved to the store. Use AuthorizeWithLoopback or the authorization code flow of
+// the config to get the first token.
+func NewWithOAuth(config *oauth2.Config, store TokenStore, opts ...option) (*Client, error) {
+	if config == nil || store == nil {
+		return nil, fmt.Errorf("OAuth config and token store are required")
+	}
+
+	c := NewClient(opts...)
+
+	c.authenticator = &oauthAuthenticator{
+		config: config,
+		store:  store,
+	}
+
+	return c, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultLoopbackPath is the path of the redirect URL if the config has none.
+const defaultLoopbackPath = "/callback"
+
+// AuthCodeFlow is an authorization code flow with PKCE in progress. Web applications keep it in the
+// session of the user between sending them to URL and handling the redirect.
+type AuthCodeFlow struct {
+	URL      string // URL of the consent page the user is sent to.
+	State    string // Random state the redirect must carry, protecting against CSRF.
+	Verifier string // PKCE code verifier, sent when the code is exchanged.
+}
+
+// StartAuthCodeFlow generates a state and a PKCE verifier, and the URL of the consent page asking for them.
+func StartAuthCodeFlow(config *oauth2.Config, opts ...oauth2.AuthCodeOption) AuthCodeFlow {
+	flow := AuthCodeFlow{
+		State:    oauth2.GenerateVerifier(),
+		Verifier: oauth2.GenerateVerifier(),
+	}
+
+	flow.URL = config.AuthCodeURL(flow.State, append(opts, oauth2.S256ChallengeOption(flow.Verifier))...)
+
+	return flow
+}
+
+// Exchange checks the query of the redirect carries the state of the flow, and exchanges its code for a token.
+func (f AuthCodeFlow) Exchange(ctx context.Context, config *oauth2.Config, query url.Values) (*oauth2.Token, error) {
+	if reason := query.Get("error"); reason != "" {
+		return nil, fmt.Errorf("authorization denied: %s: %s", reason, query.Get("error_description"))
+	}
+
+	if query.Get("state") != f.State {
+		return nil, fmt.Errorf("authorization redirect has an unexpected state")
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("authorization redirect is missing the code")
+	}
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(f.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return token, nil
+}
+
+// AuthorizeWithLoopback runs the authorization code flow with PKCE for command line tools. It listens
+// for the redirect on the loopback address of the config's redirect URL, or on a random port at
+// http://127.0.0.1:{port}/callback if it has none. It then calls open with the URL of the consent page,
+// to print it or launch a browser, and waits for the user to be redirected back or the context to be done.
+func AuthorizeWithLoopback(ctx context.Context, config *oauth2.Config, open func(authURL string) error, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	cfg := *config
+
+	addr, path := "127.0.0.1:0", defaultLoopbackPath
+
+	if cfg.RedirectURL != "" {
+		u, err := url.Parse(cfg.RedirectURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redirect URL: %w", err)
+		}
+
+		if u.Scheme != "http" || !isLoopback(u.Hostname()) {
+			return nil, fmt.Errorf("redirect URL '%s' is not a loopback HTTP URL", cfg.RedirectURL)
+		}
+
+		addr, path = u.Host, u.Path
+
+		if path == "" {
+			path = "/"
+		}
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the authorization redirect: %w", err)
+	}
+
+	// The redirect URL must match the one registered with Coinbase exactly, so only fill it in if missing.
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = "http://" + ln.Addr().String() + path
+	}
+
+	flow := StartAuthCodeFlow(&cfg, opts...)
+
+	redirects := make(chan url.Values, 1)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != path {
+				http.NotFound(w, r)
+				return
+			}
+
+			select {
+			case redirects <- r.URL.Query():
+				fmt.Fprintln(w, "Authorization received, you may close this window.")
+			default:
+				http.Error(w, "authorization already received", http.StatusConflict)
+			}
+		}),
+	}
+
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	err = open(flow.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open authorization URL: %w", err)
+	}
+
+	select {
+	case query := <-redirects:
+		return flow.Exchange(ctx, &cfg, query)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for the authorization redirect: %w", ctx.Err())
+	}
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
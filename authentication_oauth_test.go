@@ -0,0 +1,310 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+	"golang.org/x/oauth2"
+)
+
+// oauthServer issues rotated tokens and serves the API to the access tokens it accepts.
+type oauthServer struct {
+	mu        sync.Mutex
+	accepted  map[string]bool // Access tokens the API accepts.
+	refresh   string          // Refresh token the token endpoint accepts, it is rotated on every use.
+	failToken bool            // Reject every refresh.
+	refreshes int
+	bodies    []string // Bodies of the API requests, in order.
+
+	api   *httptest.Server
+	token *httptest.Server
+}
+
+func newOAuthServer(t *testing.T) *oauthServer {
+	t.Helper()
+
+	s := &oauthServer{accepted: make(map[string]bool)}
+
+	s.api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.bodies = append(s.bodies, string(body))
+
+		if !s.accepted[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"UNAUTHENTICATED","message":"invalid token"}`)
+
+			return
+		}
+
+		io.WriteString(w, `{"portfolio":{"name":"Trading","uuid":"8bfc20d7-f7c6-4422-bf07-8243ca4169fe","type":"CONSUMER"}}`)
+	}))
+	t.Cleanup(s.api.Close)
+
+	s.token = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.failToken || r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != s.refresh {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":"invalid_grant"}`)
+
+			return
+		}
+
+		s.refreshes++
+
+		access := fmt.Sprintf("access-%d", s.refreshes)
+		s.refresh = fmt.Sprintf("refresh-%d", s.refreshes)
+		s.accepted[access] = true
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"%s","refresh_token":"%s","token_type":"bearer","expires_in":3600}`, access, s.refresh)
+	}))
+	t.Cleanup(s.token.Close)
+
+	return s
+}
+
+func (s *oauthServer) client(t *testing.T, store coinbase.TokenStore) *coinbase.Client {
+	t.Helper()
+
+	config := coinbase.NewOAuthConfig("client-id", "client-secret", "http://127.0.0.1/callback", "wallet:accounts:read")
+	config.Endpoint.TokenURL = s.token.URL
+
+	client, err := coinbase.NewWithOAuth(config, store, coinbase.WithBaseURL(s.api.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	coinbase.WithRetryPolicy(fastRetries)(client)
+
+	return client
+}
+
+func TestOAuthRefresh(t *testing.T) {
+	valid := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		token     *oauth2.Token // Token in the store, nil if there is none.
+		accepted  bool          // Whether the API accepts the stored access token.
+		failToken bool
+		refreshes int
+		requests  int
+		err       error  // Expected error, nil if the call succeeds.
+		saved     string // Access token in the store afterwards.
+	}{
+		{
+			name:     "valid token",
+			token:    &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: valid},
+			accepted: true,
+			requests: 1,
+			saved:    "access-0",
+		},
+		{
+			name:      "expired token refreshed before the request",
+			token:     &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Minute)},
+			refreshes: 1,
+			requests:  1,
+			saved:     "access-1",
+		},
+		{
+			name:      "revoked token refreshed and the request retried",
+			token:     &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: valid},
+			refreshes: 1,
+			requests:  2,
+			saved:     "access-1",
+		},
+		{
+			name:      "refresh rejected",
+			token:     &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: valid},
+			failToken: true,
+			requests:  1,
+			err:       coinbase.ErrUnauthorized,
+			saved:     "access-0",
+		},
+		{
+			name:     "revoked token without a refresh token",
+			token:    &oauth2.Token{AccessToken: "access-0", Expiry: valid},
+			requests: 1,
+			err:      coinbase.ErrUnauthorized,
+			saved:    "access-0",
+		},
+		{
+			name: "no token stored",
+			err:  coinbase.ErrTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newOAuthServer(t)
+			srv.refresh = "refresh-0"
+			srv.accepted["access-0"] = tt.accepted
+			srv.failToken = tt.failToken
+
+			store := coinbase.NewMemoryTokenStore(tt.token)
+
+			_, err := srv.client(t, store).Portfolio.Create(context.Background(), "Trading")
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error is %v, want %v", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if srv.refreshes != tt.refreshes || len(srv.bodies) != tt.requests {
+				t.Fatalf("%d refreshes and %d requests, want %d and %d", srv.refreshes, len(srv.bodies), tt.refreshes, tt.requests)
+			}
+
+			// The retried request carries the same body.
+			for i, body := range srv.bodies {
+				if !strings.Contains(body, `"name":"Trading"`) {
+					t.Fatalf("request %d has body %q", i, body)
+				}
+			}
+
+			saved, err := store.Load(context.Background())
+			if tt.saved == "" {
+				if !errors.Is(err, coinbase.ErrTokenNotFound) {
+					t.Fatalf("error is %v, want %v", err, coinbase.ErrTokenNotFound)
+				}
+
+				return
+			}
+
+			if err != nil || saved.AccessToken != tt.saved {
+				t.Fatalf("stored token is %v, %v, want access token '%s'", saved, err, tt.saved)
+			}
+		})
+	}
+}
+
+func TestOAuthRefreshSharedStore(t *testing.T) {
+	srv := newOAuthServer(t)
+	srv.refresh = "refresh-0"
+	srv.accepted["access-0"] = true
+
+	store := coinbase.NewMemoryTokenStore(&oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: time.Now().Add(time.Hour)})
+
+	first := srv.client(t, store)
+	second := srv.client(t, store)
+
+	for _, client := range []*coinbase.Client{first, second} {
+		if _, err := client.Portfolio.Create(context.Background(), "Trading"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Coinbase revokes the token, the first client refreshes it and rotates the refresh token.
+	srv.mu.Lock()
+	srv.accepted["access-0"] = false
+	srv.mu.Unlock()
+
+	if _, err := first.Portfolio.Create(context.Background(), "Trading"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The second client picks up the token the first one saved, instead of using the rotated refresh token.
+	if _, err := second.Portfolio.Create(context.Background(), "Trading"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if srv.refreshes != 1 {
+		t.Fatalf("%d refreshes, want 1", srv.refreshes)
+	}
+}
+
+func TestOAuthRefreshOutlivesContext(t *testing.T) {
+	srv := newOAuthServer(t)
+	srv.refresh = "refresh-0"
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The call is cancelled while the token is refreshed, the rotated refresh token is still saved.
+	exchange := srv.token.Config.Handler
+	srv.token.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		exchange.ServeHTTP(w, r)
+	})
+
+	store := coinbase.NewMemoryTokenStore(&oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: time.Now().Add(time.Hour)})
+
+	_, err := srv.client(t, store).Portfolio.Create(ctx, "Trading")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	saved, err := store.Load(context.Background())
+	if err != nil || saved.RefreshToken != "refresh-1" {
+		t.Fatalf("stored token is %v, %v, want refresh token 'refresh-1'", saved, err)
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	store := coinbase.NewFileTokenStore(path)
+
+	_, err := store.Load(context.Background())
+	if !errors.Is(err, coinbase.ErrTokenNotFound) {
+		t.Fatalf("error is %v, want %v", err, coinbase.ErrTokenNotFound)
+	}
+
+	token := &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", TokenType: "bearer", Expiry: time.Now().Add(time.Hour).Round(time.Second)}
+
+	if err := store.Save(context.Background(), token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat token file: %v", err)
+	}
+
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Fatalf("token file has permissions %s, want it readable only by its owner", perm)
+	}
+
+	loaded, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken || !loaded.Expiry.Equal(token.Expiry) {
+		t.Fatalf("loaded %+v, want %+v", loaded, token)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"access_token":"secret-token`), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	_, err = store.Load(context.Background())
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("error is %v, want an error not quoting the token", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// es256SignatureSize is the size of each of r and s in a raw P-256 signature.
+const es256SignatureSize = 32
+
+// signerES256 signs ES256 JWTs with any crypto.Signer holding a P-256 key, such as keys kept in a KMS
+// or an HSM. jwt.SigningMethodES256 only signs with an *ecdsa.PrivateKey.
+type signerES256 struct{}
+
+var signingMethodES256Signer jwt.SigningMethod = signerES256{}
+
+func (signerES256) Alg() string {
+	return jwt.SigningMethodES256.Alg()
+}
+
+func (signerES256) Verify(signingString string, sig []byte, key any) error {
+	return jwt.SigningMethodES256.Verify(signingString, sig, key)
+}
+
+// Sign signs the SHA-256 digest of the string. Signers return ASN.1 DER encoded ECDSA signatures,
+// while a JWS carries r and s as two fixed size big-endian integers.
+func (signerES256) Sign(signingString string, key any) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	digest := sha256.Sum256([]byte(signingString))
+
+	der, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with crypto.Signer: %w", err)
+	}
+
+	return derToRaw(der, es256SignatureSize)
+}
+
+// derToRaw converts an ASN.1 DER encoded ECDSA signature to r||s, each left padded to size bytes.
+func derToRaw(der []byte, size int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: %w", err)
+	}
+
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: %d trailing bytes", len(rest))
+	}
+
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > size*8 || sig.S.BitLen() > size*8 {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: r or s out of range")
+	}
+
+	raw := make([]byte, 2*size)
+
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+
+	return raw, nil
+}
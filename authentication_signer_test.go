@@ -0,0 +1,181 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestDERToRaw(t *testing.T) {
+	high := "80" + strings.Repeat("00", 31) // 32 byte integer with its top bit set, DER pads it with a zero byte.
+
+	tests := []struct {
+		name string
+		der  string
+		raw  string // Empty if decoding fails.
+	}{
+		{name: "small integers are left padded", der: "3006020101020102", raw: strings.Repeat("00", 31) + "01" + strings.Repeat("00", 31) + "02"},
+		{name: "zero byte of a positive integer dropped", der: "3026022100" + high + "020101", raw: high + strings.Repeat("00", 31) + "01"},
+		{name: "trailing bytes", der: "3006020101020101" + "00"},
+		{name: "zero r", der: "3006020100020101"},
+		{name: "negative s", der: "30060201010201ff"},
+		{name: "r larger than the curve", der: "3026022101" + strings.Repeat("00", 32) + "020101"},
+		{name: "not a sequence", der: "0201ff"},
+		{name: "empty", der: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der, err := hex.DecodeString(tt.der)
+			if err != nil {
+				t.Fatalf("invalid test vector: %v", err)
+			}
+
+			raw, err := derToRaw(der, es256SignatureSize)
+
+			if tt.raw == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %x", raw)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := hex.EncodeToString(raw); got != tt.raw {
+				t.Fatalf("raw signature is %s, want %s", got, tt.raw)
+			}
+		})
+	}
+}
+
+// opaqueSigner hides the concrete type of the key, as a KMS or an HSM would.
+type opaqueSigner struct {
+	crypto.Signer
+	err error
+}
+
+func (s opaqueSigner) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.Signer.Sign(r, digest, opts)
+}
+
+func TestSignerES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	// Signatures of a few hundred strings hit r and s shorter than 32 bytes, which must be padded.
+	for i := 0; i < 300; i++ {
+		signingString := strings.Repeat("a", i)
+
+		sig, err := signingMethodES256Signer.Sign(signingString, opaqueSigner{Signer: key})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(sig) != 2*es256SignatureSize {
+			t.Fatalf("signature is %d bytes, want %d", len(sig), 2*es256SignatureSize)
+		}
+
+		if err := jwt.SigningMethodES256.Verify(signingString, sig, &key.PublicKey); err != nil {
+			t.Fatalf("signature does not verify: %v", err)
+		}
+	}
+
+	errSigner := errors.New("token removed")
+
+	_, err = signingMethodES256Signer.Sign("header.claims", opaqueSigner{Signer: key, err: errSigner})
+	if !errors.Is(err, errSigner) {
+		t.Fatalf("error is %v, want %v", err, errSigner)
+	}
+
+	_, err = signingMethodES256Signer.Sign("header.claims", key.PublicKey)
+	if !errors.Is(err, jwt.ErrInvalidKeyType) {
+		t.Fatalf("error is %v, want %v", err, jwt.ErrInvalidKeyType)
+	}
+}
+
+func TestNewCloudAuthenticatorSigner(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		key    crypto.Signer
+		method jwt.SigningMethod // Nil if the key is rejected.
+	}{
+		{name: "ecdsa private key", key: p256, method: jwt.SigningMethodES256},
+		{name: "opaque p-256 signer", key: opaqueSigner{Signer: p256}, method: signingMethodES256Signer},
+		{name: "p-384", key: opaqueSigner{Signer: p384}},
+		{name: "rsa", key: rsaKey},
+		{name: "nil", key: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := newCloudAuthenticator("organizations/org/apiKeys/key", tt.key, &clock{})
+
+			if tt.method == nil {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if a.signingMethod != tt.method {
+				t.Fatalf("signing method is %v, want %v", a.signingMethod, tt.method)
+			}
+
+			// The JWT signed by the authenticator verifies with the public key.
+			sig, err := a.signingMethod.Sign("header.claims", a.signingKey)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := jwt.SigningMethodES256.Verify("header.claims", sig, &p256.PublicKey); err != nil {
+				t.Fatalf("signature does not verify: %v", err)
+			}
+		})
+	}
+}
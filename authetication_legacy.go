@@ -31,6 +31,16 @@ const (
 type legacyAuthenticator struct {
 	apiKey    string // The API key used to authenticate requests (that you create on coinbase.com).
 	apiSecret string // The API secret used to authenticate requests (that you create on coinbase.com).
+	clock     *clock // Time of the signatures, corrected by Client.SyncTime.
+}
+
+// String redacts the API secret, so it never ends up in logs.
+func (a legacyAuthenticator) String() string {
+	return fmt.Sprintf("legacyAuthenticator{apiKey: %s, apiSecret: %s}", a.apiKey, redacted)
+}
+
+func (a legacyAuthenticator) GoString() string {
+	return a.String()
 }
 
 // The CB-ACCESS-SIGN header is generated by creating a sha256 HMAC object using the API secret
@@ -47,14 +57,28 @@ type legacyAuthenticator struct {
 // 2. Create a sha256 HMAC object with your API secret on the signature string.
 // 3. Get the hexadecimal string representation of the sha256 HMAC object and pass that in as the CB-ACCESS-SIGN header.
 func (a *legacyAuthenticator) createSignature(req *http.Request, ts time.Time) (string, error) {
-	var buf string
+	var buf []byte
+
+	switch {
+	case req.GetBody != nil:
+		// Read a copy so the body that is sent is left untouched.
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("failed to read HTTP request body to add authenitcation headers: %w", err)
+		}
+
+		defer body.Close()
 
-	if req.Body != nil {
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read HTTP request body to add authenitcation headers: %w", err)
+		}
+	case req.Body != nil && req.Body != http.NoBody:
 		defer req.Body.Close()
 
 		var err error
 
-		buf, err := io.ReadAll(req.Body)
+		buf, err = io.ReadAll(req.Body)
 		if err != nil {
 			return "", fmt.Errorf("failed to read HTTP request body to add authenitcation headers: %w", err)
 		}
@@ -77,16 +101,16 @@ func (a legacyAuthenticator) Authenticate(req *http.Request) error {
 		return nil
 	}
 
-	now := time.Now()
+	now := a.clock.now()
 
 	sig, err := a.createSignature(req, now)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Add(coinbaseAccessKeyHeader, a.apiKey)
-	req.Header.Add(coinbaseAccessTimestampHeader, strconv.FormatInt(now.Unix(), 10))
-	req.Header.Add(coinbaseAccessSignHeader, sig)
+	req.Header.Set(coinbaseAccessKeyHeader, a.apiKey)
+	req.Header.Set(coinbaseAccessTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(coinbaseAccessSignHeader, sig)
 
 	return nil
 }
@@ -0,0 +1,245 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	maxCandlesPerRequest            = 350 // Coinbase rejects requests spanning more candles than this.
+	defaultCandleHistoryConcurrency = 4
+	maxCandleHistoryConcurrency     = 16
+)
+
+type GetCandleHistoryOptions struct {
+	ProductID   string          // The trading pair.
+	Start       time.Time       // Start of the range, the candle containing it is the first returned.
+	End         time.Time       // End of the range, exclusive, defaults to now.
+	Granularity TimeGranularity // The time slice value for each candle.
+	Concurrency int             // Maximum number of requests in flight at once, defaults to 4 and is capped at 16.
+}
+
+// CandleGap is a run of consecutive buckets with no candle, Coinbase omits buckets in which no trades happened.
+type CandleGap struct {
+	Start time.Time // Start of the first missing bucket.
+	End   time.Time // End of the last missing bucket, exclusive.
+}
+
+// Number of buckets missing in the gap.
+func (g CandleGap) Buckets(granularity TimeGranularity) int {
+	d := granularity.Duration()
+	if d == 0 {
+		return 0
+	}
+
+	return int(g.End.Sub(g.Start) / d)
+}
+
+type CandleHistory struct {
+	Candles []Candles   // Candles in the range, oldest first, without duplicates.
+	Gaps    []CandleGap // Ranges within the history for which Coinbase returned no candles.
+}
+
+// GetCandleHistory gets the candles for a product over a range of any length. The range is split into
+// windows small enough for a single request, which are fetched concurrently and joined into one series.
+func (s *ProductsService) GetCandleHistory(ctx context.Context, options GetCandleHistoryOptions) (*CandleHistory, error) {
+	return getCandleHistory(ctx, s.GetProductCandles, options)
+}
+
+// GetCandleHistory gets the candles for a product over a range of any length using the public endpoint.
+// The range is split into windows small enough for a single request, which are fetched concurrently and
+// joined into one series.
+func (s *PublicService) GetCandleHistory(ctx context.Context, options GetCandleHistoryOptions) (*CandleHistory, error) {
+	return getCandleHistory(ctx, s.GetProductCandles, options)
+}
+
+type candleFetcher func(ctx context.Context, options GetProductCandlesOptions) ([]Candles, error)
+
+type candleWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+func getCandleHistory(ctx context.Context, fetch candleFetcher, options GetCandleHistoryOptions) (*CandleHistory, error) {
+	granularity := options.Granularity.Duration()
+	if granularity == 0 {
+		return nil, fmt.Errorf("unsupported candle granularity '%s'", options.Granularity)
+	}
+
+	end := options.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	// Buckets are aligned to the unix epoch, starting at a boundary keeps every window at the maximum size.
+	start := options.Start.Truncate(granularity)
+	if !start.Before(end) {
+		return nil, fmt.Errorf("candle history start '%s' is not before end '%s'", options.Start, end)
+	}
+
+	windows := splitCandleRange(start, end, granularity)
+	results := make([][]Candles, len(windows))
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultCandleHistoryConcurrency
+	}
+
+	if concurrency > maxCandleHistoryConcurrency {
+		concurrency = maxCandleHistoryConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for i, w := range windows {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+
+		go func(i int, w candleWindow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			candles, err := fetch(ctx, GetProductCandlesOptions{
+				ProductID:   options.ProductID,
+				Start:       w.start,
+				End:         w.end,
+				Granularity: options.Granularity,
+			})
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+
+				return
+			}
+
+			results[i] = candles
+		}(i, w)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, fmt.Errorf("failed to fetch candle history for product '%s': %w", options.ProductID, firstErr)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch candle history for product '%s': %w", options.ProductID, err)
+	}
+
+	return joinCandles(results, start, end, granularity)
+}
+
+// splitCandleRange splits the range into windows of at most maxCandlesPerRequest buckets. The start and
+// end of a window are both inclusive, each window starts on the last bucket of the previous one so no
+// bucket is missed at the boundaries; the candle fetched twice is dropped when the windows are joined.
+func splitCandleRange(start time.Time, end time.Time, granularity time.Duration) []candleWindow {
+	var windows []candleWindow
+
+	for s := start; s.Before(end); {
+		e := s.Add((maxCandlesPerRequest - 1) * granularity)
+		if e.After(end) {
+			e = end
+		}
+
+		windows = append(windows, candleWindow{start: s, end: e})
+
+		s = e
+	}
+
+	return windows
+}
+
+type timedCandle struct {
+	start  time.Time
+	candle Candles
+}
+
+// joinCandles merges the windows into a single series sorted oldest first, dropping duplicates, and
+// records the buckets between start and end that have no candle.
+func joinCandles(windows [][]Candles, start time.Time, end time.Time, granularity time.Duration) (*CandleHistory, error) {
+	seen := make(map[int64]bool)
+
+	var candles []timedCandle
+
+	for _, window := range windows {
+		for _, c := range window {
+			if c.Start == nil {
+				continue
+			}
+
+			unix, err := strconv.ParseInt(*c.Start, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid candle start '%s': %w", *c.Start, err)
+			}
+
+			// Windows end on an inclusive bound, the candle starting at the end is outside the range.
+			if seen[unix] || unix < start.Unix() || unix >= end.Unix() {
+				continue
+			}
+
+			seen[unix] = true
+			candles = append(candles, timedCandle{start: time.Unix(unix, 0), candle: c})
+		}
+	}
+
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].start.Before(candles[j].start)
+	})
+
+	history := CandleHistory{Candles: make([]Candles, len(candles))}
+
+	// Walk the expected buckets alongside the candles, anything between two candles is a gap.
+	expected := start
+
+	for i, c := range candles {
+		history.Candles[i] = c.candle
+
+		if c.start.After(expected) {
+			history.Gaps = append(history.Gaps, CandleGap{Start: expected, End: c.start})
+		}
+
+		if next := c.start.Add(granularity); next.After(expected) {
+			expected = next
+		}
+	}
+
+	if expected.Before(end) {
+		last := end.Truncate(granularity)
+		if last.Before(end) {
+			last = last.Add(granularity)
+		}
+
+		history.Gaps = append(history.Gaps, CandleGap{Start: expected, End: last})
+	}
+
+	return &history, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+func TestGetCandleHistory(t *testing.T) {
+	origin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		granularity coinbase.TimeGranularity
+		buckets     int   // Buckets between start and end.
+		missing     []int // Buckets without a candle.
+		requests    int
+	}{
+		{name: "single window", granularity: coinbase.TimeGranularityOneMinute, buckets: 100, requests: 1},
+		{name: "exactly one full window", granularity: coinbase.TimeGranularityOneMinute, buckets: 349, requests: 1},
+		{name: "one bucket over a window", granularity: coinbase.TimeGranularityOneMinute, buckets: 350, requests: 2},
+		{name: "many windows", granularity: coinbase.TimeGranularityFiveMinutes, buckets: 1500, requests: 5},
+		{name: "daily candles", granularity: coinbase.TimeGranularityOneDay, buckets: 700, requests: 3},
+		{name: "gaps on window boundaries", granularity: coinbase.TimeGranularityOneHour, buckets: 1000, missing: []int{0, 348, 349, 697, 999}, requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := coinbasetest.NewServer()
+			defer srv.Close()
+
+			srv.AddProduct(coinbase.Product{ID: "BTC-USD"})
+
+			g := tt.granularity.Duration()
+			start := origin
+			end := start.Add(time.Duration(tt.buckets) * g)
+
+			missing := make(map[int]bool)
+			for _, i := range tt.missing {
+				missing[i] = true
+			}
+
+			// Candles around the range must not be returned.
+			for i := -10; i < tt.buckets+10; i++ {
+				if missing[i] {
+					continue
+				}
+
+				srv.AddCandles("BTC-USD", coinbase.Candles{Start: coinbase.String(strconv.FormatInt(start.Add(time.Duration(i)*g).Unix(), 10))})
+			}
+
+			client, err := srv.Client()
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			history, err := client.Products.GetCandleHistory(context.Background(), coinbase.GetCandleHistoryOptions{
+				ProductID:   "BTC-USD",
+				Start:       start,
+				End:         end,
+				Granularity: tt.granularity,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want := int(end.Sub(start)/g) - len(tt.missing); len(history.Candles) != want {
+				t.Fatalf("%d candles, want %d", len(history.Candles), want)
+			}
+
+			for i, c := range history.Candles {
+				if i > 0 && *c.Start <= *history.Candles[i-1].Start {
+					t.Fatalf("candle %d starts at %s, after %s", i, *c.Start, *history.Candles[i-1].Start)
+				}
+			}
+
+			var gapBuckets int
+			for _, gap := range history.Gaps {
+				gapBuckets += gap.Buckets(tt.granularity)
+			}
+
+			if gapBuckets != len(tt.missing) {
+				t.Fatalf("gaps %v cover %d buckets, want %d", history.Gaps, gapBuckets, len(tt.missing))
+			}
+
+			if len(srv.Requests()) != tt.requests {
+				t.Fatalf("%d requests, want %d", len(srv.Requests()), tt.requests)
+			}
+		})
+	}
+}
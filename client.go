@@ -8,6 +8,7 @@
 package coinbase
 
 import (
+	"crypto"
 	"fmt"
 	"net/http"
 	"time"
@@ -26,8 +27,16 @@ type Authenticator interface {
 type Client struct {
 	authenticator Authenticator // Handles authentication for the Advanced Trade REST API.
 
-	baseURL    string       // Base URL of the Advanced Trade REST API.
-	httpClient *http.Client // Client used to make HTTP calls.
+	baseURL          string       // Base URL of the Advanced Trade REST API.
+	webSocketURL     string       // URL of the Advanced Trade WebSocket feed.
+	userWebSocketURL string       // URL of the Advanced Trade WebSocket feed for user order data.
+	httpClient       *http.Client // Client used to make HTTP calls.
+	retryPolicy      *RetryPolicy // Retries transient failures if set.
+	publicLimiter    *tokenBucket // Throttles requests to public endpoints if set.
+	privateLimiter   *tokenBucket // Throttles requests to private endpoints if set.
+	strictOrders     bool         // Return an error for orders Coinbase did not carry out.
+	clock            *clock       // Time used to authenticate requests, corrected by SyncTime.
+	middleware       []Middleware // Wraps every request, the first is the outermost.
 
 	Accounts       *AccountService        // Interface with the Advanced Trade REST API Accounts APIs.
 	Orders         *OrdersService         // Interface with the Advanced Trade REST API Orders APIs.
@@ -38,6 +47,7 @@ type Client struct {
 	Public         *PublicService         // Interface with the Advanced Trade REST API's Public API.
 	Converts       *ConvertsService       // Interface with the Advanced Trade REST API Converts API.
 	PaymentMethods *PaymentMethodsService // Interface with the Advanced Trade REST API's Payment Methods API.
+	Stream         *StreamService         // Interface with the Advanced Trade WebSocket feed.
 }
 
 type service struct {
@@ -59,6 +69,30 @@ func WithBaseURL(url string) func(*Client) {
 	}
 }
 
+// WithWebSocketURL overrides the URL of the Advanced Trade WebSocket feed
+// on the client.
+func WithWebSocketURL(url string) func(*Client) {
+	return func(c *Client) {
+		if url == "" {
+			return
+		}
+
+		c.webSocketURL = url
+	}
+}
+
+// WithUserWebSocketURL overrides the URL of the Advanced Trade WebSocket feed
+// used for user order data on the client.
+func WithUserWebSocketURL(url string) func(*Client) {
+	return func(c *Client) {
+		if url == "" {
+			return
+		}
+
+		c.userWebSocketURL = url
+	}
+}
+
 // WithHTTPClient overrides the default HTTP client used by the client.
 func WithHTTPClient(httpClient *http.Client) func(*Client) {
 	return func(c *Client) {
@@ -86,9 +120,12 @@ func WithCustomAuthenticator(authenticator Authenticator) func(*Client) {
 //   - To use a custom authentication schema use the WithCustomAuthenticator option as an argument to this method.
 func NewClient(opts ...option) *Client {
 	c := Client{
-		baseURL:       productionURI,
-		httpClient:    http.DefaultClient,
-		authenticator: unauthenticated{}, // Default to unauthenticated user.
+		baseURL:          productionURI,
+		webSocketURL:     productionWebSocketURI,
+		userWebSocketURL: productionUserWebSocketURI,
+		httpClient:       http.DefaultClient,
+		authenticator:    unauthenticated{}, // Default to unauthenticated user.
+		clock:            &clock{},
 	}
 
 	// Reuse a single struct instead of allocating one for each service on the heap.
@@ -103,6 +140,7 @@ func NewClient(opts ...option) *Client {
 	c.Public = (*PublicService)(&commonService)
 	c.PaymentMethods = (*PaymentMethodsService)(&commonService)
 	c.Futures = (*FuturesService)(&commonService)
+	c.Stream = (*StreamService)(&commonService)
 
 	for _, opt := range opts {
 		if opt != nil {
@@ -121,6 +159,7 @@ func NewWithLegacy(apiKey string, apiSecret string, opts ...option) *Client {
 	c.authenticator = legacyAuthenticator{
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
+		clock:     c.clock,
 	}
 
 	return c
@@ -131,17 +170,43 @@ func NewWithLegacy(apiKey string, apiSecret string, opts ...option) *Client {
 func NewWithCloud(apiKey string, apiSecret string, opts ...option) (*Client, error) {
 	c := NewClient(opts...)
 
-	key, err := parsePrivateKey(apiSecret)
+	key, err := ParsePrivateKey(apiSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api secret provided: %w", err)
+	}
+
+	c.authenticator, err = newCloudAuthenticator(apiKey, key, c.clock)
 	if err != nil {
 		return nil, fmt.Errorf("invalid api secret provided: %w", err)
 	}
 
-	c.authenticator = cloudAuthenticator{
-		apiKey:     apiKey,
-		signingKey: key,
+	return c, nil
+}
+
+// String describes the client without its credentials, so it can be logged.
+func (c *Client) String() string {
+	return fmt.Sprintf("coinbase.Client{baseURL: %s, authenticator: %v}", c.baseURL, c.authenticator)
+}
+
+func (c *Client) GoString() string {
+	return c.String()
+}
+
+// NewWithSigner creates a new Coinbase Advanced Trade REST API client, using Cloud API Trading Keys
+// for authentication with a private key that never has to be loaded into memory, such as a key kept
+// in a KMS or an HSM. The signer must hold a P-256 EC key or an Ed25519 key, see the pkcs11signer
+// package for keys on PKCS#11 tokens.
+func NewWithSigner(apiKey string, signer crypto.Signer, opts ...option) (*Client, error) {
+	c := NewClient(opts...)
+
+	var err error
+
+	c.authenticator, err = newCloudAuthenticator(apiKey, signer, c.clock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer provided: %w", err)
 	}
 
-	return c, err
+	return c, nil
 }
 
 // Bool is a helper function that allocates a new bool value
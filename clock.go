@@ -0,0 +1,124 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// clock tells the time of Coinbase's servers, estimated from the local clock and the offset measured
+// by Client.SyncTime. Authenticators sign requests with it, a nil clock is the local clock.
+type clock struct {
+	mu     sync.RWMutex
+	offset time.Duration // Difference between Coinbase's clock and the local clock.
+	synced bool          // The offset was measured at least once.
+}
+
+func (c *clock) now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return time.Now().Add(c.offset)
+}
+
+// isSynced reports whether the offset to Coinbase's clock is known.
+func (c *clock) isSynced() bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.synced
+}
+
+func (c *clock) getOffset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.offset
+}
+
+func (c *clock) setOffset(offset time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.offset = offset
+	c.synced = true
+}
+
+// SyncTime measures the offset between the local clock and Coinbase's clock with PublicService.GetServerTime,
+// and corrects the time used to authenticate requests by it. The server time is assumed to have been read
+// half way through the round trip. The offset is returned, it is positive if the local clock is behind.
+func (c *Client) SyncTime(ctx context.Context) (time.Duration, error) {
+	sent := time.Now()
+
+	st, err := c.Public.GetServerTime(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sync time: %w", err)
+	}
+
+	received := time.Now()
+
+	server, err := st.Time()
+	if err != nil {
+		return 0, fmt.Errorf("failed to sync time: %w", err)
+	}
+
+	offset := server.Sub(sent.Add(received.Sub(sent) / 2))
+
+	c.clock.setOffset(offset)
+
+	return offset, nil
+}
+
+// StartTimeSync syncs the time once with SyncTime, then again every interval in the background until the
+// context is done. The offset measured last is kept if a later sync fails, errors of the first sync are
+// returned and the others are passed to onError if it is not nil.
+func (c *Client) StartTimeSync(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("time sync interval must be positive, got %s", interval)
+	}
+
+	_, err := c.SyncTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.SyncTime(ctx); err != nil && onError != nil && ctx.Err() == nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ClockOffset returns the offset between the local clock and Coinbase's clock measured by SyncTime,
+// zero if the time was never synced.
+func (c *Client) ClockOffset() time.Duration {
+	return c.clock.getOffset()
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+func TestSyncTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset time.Duration // Offset of the server's clock from the local clock.
+	}{
+		{name: "local clock behind", offset: time.Hour},
+		{name: "local clock ahead", offset: -3 * time.Minute},
+		{name: "clocks agree"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := coinbasetest.NewServer()
+			defer srv.Close()
+
+			srv.SetTime(time.Now().Add(tt.offset))
+
+			client, err := srv.Client()
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if offset := client.ClockOffset(); offset != 0 {
+				t.Fatalf("offset is %s before syncing, want 0", offset)
+			}
+
+			offset, err := client.SyncTime(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// The server's clock is frozen, so the offset shrinks by the time the test took to get here.
+			if diff := offset - tt.offset; diff < -time.Second || diff > time.Second {
+				t.Fatalf("offset is %s, want %s", offset, tt.offset)
+			}
+
+			if client.ClockOffset() != offset {
+				t.Fatalf("client offset is %s, want %s", client.ClockOffset(), offset)
+			}
+		})
+	}
+}
+
+func TestSyncTimeFailureKeepsOffset(t *testing.T) {
+	srv := newAPIServer(t, http.StatusOK, `{"iso":"2030-01-01T00:00:00Z","epochSeconds":"1893456000","epochMillis":"1893456000000"}`)
+	client := srv.client()
+
+	offset, err := client.SyncTime(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.Status = http.StatusInternalServerError
+	srv.Body = `{"error":"INTERNAL","message":"failed"}`
+
+	_, err = client.SyncTime(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if client.ClockOffset() != offset {
+		t.Fatalf("offset is %s after a failed sync, want %s", client.ClockOffset(), offset)
+	}
+
+	srv.Body = `{"iso":"not a time"}`
+	srv.Status = http.StatusOK
+
+	_, err = client.SyncTime(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for an invalid time")
+	}
+
+	if client.ClockOffset() != offset {
+		t.Fatalf("offset is %s after an invalid time, want %s", client.ClockOffset(), offset)
+	}
+}
+
+func TestStartTimeSync(t *testing.T) {
+	srv := coinbasetest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.StartTimeSync(context.Background(), 0, nil); err == nil {
+		t.Fatal("expected an error for a zero interval")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = client.StartTimeSync(ctx, 5*time.Millisecond, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The server's clock jumps ahead, the background sync picks it up.
+	srv.SetTime(time.Now().Add(time.Hour))
+
+	eventually(t, "offset updated", func() bool {
+		return client.ClockOffset() > 59*time.Minute
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+// Package coinbasemock provides mocks of the go-coinbase services, for unit testing code that depends on
+// the service interfaces of the coinbase package instead of the concrete services of a client.
+//
+// Every mock has a function field per method. A method records its call and delegates to the field, or
+// fails with ErrNotMocked if the field is nil, so tests only set the methods they expect to be called.
+//
+//	orders := &coinbasemock.Orders{
+//		GetFunc: func(ctx context.Context, id string) (*coinbase.Order, error) {
+//			return &coinbase.Order{ID: id}, nil
+//		},
+//	}
+//
+//	strategy := NewStrategy(orders) // Accepts a coinbase.Orders, e.g. client.Orders in production.
+//
+//	// ...
+//
+//	if len(orders.CallsTo("Create")) != 1 {
+//		t.Error("expected one order to be created")
+//	}
+package coinbasemock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// ErrNotMocked is returned by the methods of a mock whose function field is nil.
+var ErrNotMocked = errors.New("coinbasemock: method not mocked")
+
+func notMocked(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotMocked, method)
+}
+
+// Call is a call of a method of a mock.
+type Call struct {
+	Method string // Name of the method, e.g. "Create".
+	Args   []any  // Arguments of the call except the context. Variadic arguments are passed as a slice.
+}
+
+// Recorder records the calls of a mock, it is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (c *Recorder) record(method string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns every call of the mock, oldest first.
+func (c *Recorder) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Call(nil), c.calls...)
+}
+
+// CallsTo returns the calls of the method, oldest first.
+func (c *Recorder) CallsTo(method string) []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var calls []Call
+
+	for _, call := range c.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets the recorded calls.
+func (c *Recorder) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = nil
+}
+
+// Pager returns a pager over the items, in a single page. Use it in the function fields of methods
+// returning a pager such as Orders.AllFunc.
+func Pager[T any](items ...T) *coinbase.Pager[T] {
+	return coinbase.NewPager(context.Background(), func(context.Context, *string) ([]T, *string, error) {
+		return items, nil, nil
+	})
+}
+
+// errPager returns a pager failing with ErrNotMocked.
+func errPager[T any](ctx context.Context, method string) *coinbase.Pager[T] {
+	return coinbase.NewPager(ctx, func(context.Context, *string) ([]T, *string, error) {
+		return nil, nil, notMocked(method)
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasemock_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasemock"
+)
+
+// recorded is implemented by every mock through its embedded Recorder.
+type recorded interface {
+	Calls() []coinbasemock.Call
+}
+
+func TestNotMocked(t *testing.T) {
+	mocks := []recorded{
+		&coinbasemock.Accounts{},
+		&coinbasemock.Converts{},
+		&coinbasemock.Fees{},
+		&coinbasemock.Futures{},
+		&coinbasemock.Orders{},
+		&coinbasemock.PaymentMethods{},
+		&coinbasemock.Portfolios{},
+		&coinbasemock.Products{},
+		&coinbasemock.Public{},
+	}
+
+	ctx := reflect.ValueOf(context.Background())
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	recorder := reflect.TypeOf(&coinbasemock.Recorder{})
+
+	for _, m := range mocks {
+		v := reflect.ValueOf(m)
+
+		for i := 0; i < v.NumMethod(); i++ {
+			method := v.Type().Method(i)
+
+			if _, ok := recorder.MethodByName(method.Name); ok {
+				continue
+			}
+
+			name := v.Elem().Type().Name() + "." + method.Name
+
+			t.Run(name, func(t *testing.T) {
+				fn := v.Method(i)
+				args := []reflect.Value{ctx}
+
+				// Every argument but the context and the variadic options is passed its zero value.
+				for j := 1; j < fn.Type().NumIn(); j++ {
+					if fn.Type().IsVariadic() && j == fn.Type().NumIn()-1 {
+						break
+					}
+
+					args = append(args, reflect.Zero(fn.Type().In(j)))
+				}
+
+				out := fn.Call(args)
+
+				var err error
+
+				last := out[len(out)-1]
+
+				switch {
+				case last.Type().Implements(errType):
+					err, _ = last.Interface().(error)
+				case last.MethodByName("Next").IsValid():
+					// Methods returning a pager fail when it fetches its first page.
+					if last.MethodByName("Next").Call(nil)[0].Bool() {
+						t.Fatal("pager returned an item")
+					}
+
+					err, _ = last.MethodByName("Err").Call(nil)[0].Interface().(error)
+				default:
+					t.Fatalf("unexpected result type %s", last.Type())
+				}
+
+				if !errors.Is(err, coinbasemock.ErrNotMocked) {
+					t.Fatalf("error is %v, want %v", err, coinbasemock.ErrNotMocked)
+				}
+
+				calls := m.Calls()
+				if len(calls) == 0 || calls[len(calls)-1].Method != method.Name {
+					t.Fatalf("call not recorded: %+v", calls)
+				}
+			})
+		}
+	}
+}
+
+func TestMockDelegates(t *testing.T) {
+	orders := &coinbasemock.Orders{
+		GetFunc: func(ctx context.Context, id string) (*coinbase.Order, error) {
+			return &coinbase.Order{ID: id}, nil
+		},
+		CancelFunc: func(ctx context.Context, ids ...string) ([]coinbase.CancelledOrder, error) {
+			cancelled := make([]coinbase.CancelledOrder, len(ids))
+			for i := range ids {
+				cancelled[i] = coinbase.CancelledOrder{ID: ids[i], Success: true}
+			}
+
+			return cancelled, nil
+		},
+		AllFunc: func(ctx context.Context, options *coinbase.ListOrdersOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Order] {
+			return coinbasemock.Pager(coinbase.Order{ID: "1"}, coinbase.Order{ID: "2"})
+		},
+	}
+
+	var service coinbase.Orders = orders
+
+	order, err := service.Get(context.Background(), "abc")
+	if err != nil || order.ID != "abc" {
+		t.Fatalf("Get returned %+v, %v", order, err)
+	}
+
+	cancelled, err := service.Cancel(context.Background(), "a", "b")
+	if err != nil || len(cancelled) != 2 || cancelled[1].ID != "b" {
+		t.Fatalf("Cancel returned %+v, %v", cancelled, err)
+	}
+
+	var ids []string
+
+	all := service.All(context.Background(), nil)
+	for all.Next() {
+		ids = append(ids, all.Value().ID)
+	}
+
+	if all.Err() != nil || !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Fatalf("All returned %v, %v", ids, all.Err())
+	}
+
+	want := []coinbasemock.Call{
+		{Method: "Get", Args: []any{"abc"}},
+		{Method: "Cancel", Args: []any{[]string{"a", "b"}}},
+		{Method: "All", Args: []any{(*coinbase.ListOrdersOptions)(nil), []coinbase.PagerOption(nil)}},
+	}
+
+	if calls := orders.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Fatalf("recorded %+v, want %+v", calls, want)
+	}
+
+	if calls := orders.CallsTo("Cancel"); len(calls) != 1 {
+		t.Fatalf("%d calls to Cancel, want 1", len(calls))
+	}
+
+	orders.Reset()
+
+	if calls := orders.Calls(); len(calls) != 0 {
+		t.Fatalf("%d calls after reset, want 0", len(calls))
+	}
+}
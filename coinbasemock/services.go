@@ -0,0 +1,658 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasemock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// Compile-time checks that the mocks implement the interfaces of the services.
+var (
+	_ coinbase.Accounts       = (*Accounts)(nil)
+	_ coinbase.Converts       = (*Converts)(nil)
+	_ coinbase.Fees           = (*Fees)(nil)
+	_ coinbase.Futures        = (*Futures)(nil)
+	_ coinbase.Orders         = (*Orders)(nil)
+	_ coinbase.PaymentMethods = (*PaymentMethods)(nil)
+	_ coinbase.Portfolios     = (*Portfolios)(nil)
+	_ coinbase.Products       = (*Products)(nil)
+	_ coinbase.Public         = (*Public)(nil)
+)
+
+// Accounts is a mock of coinbase.Accounts.
+type Accounts struct {
+	Recorder
+
+	AllFunc  func(ctx context.Context, options *coinbase.AccountListOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Account]
+	GetFunc  func(ctx context.Context, id string) (*coinbase.Account, error)
+	ListFunc func(ctx context.Context, options *coinbase.AccountListOptions) (*coinbase.ListAccountsResponse, error)
+}
+
+// All calls AllFunc.
+func (m *Accounts) All(ctx context.Context, options *coinbase.AccountListOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Account] {
+	m.record("All", options, opts)
+
+	if m.AllFunc == nil {
+		return errPager[coinbase.Account](ctx, "Accounts.All")
+	}
+
+	return m.AllFunc(ctx, options, opts...)
+}
+
+// Get calls GetFunc.
+func (m *Accounts) Get(ctx context.Context, id string) (*coinbase.Account, error) {
+	m.record("Get", id)
+
+	if m.GetFunc == nil {
+		return nil, notMocked("Accounts.Get")
+	}
+
+	return m.GetFunc(ctx, id)
+}
+
+// List calls ListFunc.
+func (m *Accounts) List(ctx context.Context, options *coinbase.AccountListOptions) (*coinbase.ListAccountsResponse, error) {
+	m.record("List", options)
+
+	if m.ListFunc == nil {
+		return nil, notMocked("Accounts.List")
+	}
+
+	return m.ListFunc(ctx, options)
+}
+
+// Converts is a mock of coinbase.Converts.
+type Converts struct {
+	Recorder
+
+	CommitFunc      func(ctx context.Context, tradeID string, options coinbase.CommitConvertTradeOptions) (*coinbase.ConvertTrade, error)
+	CreateQuoteFunc func(ctx context.Context, options coinbase.CreateConvertQuoteOptions) (*coinbase.ConvertTrade, error)
+	GetTradeFunc    func(ctx context.Context, tradeID string, options coinbase.GetConvertTradeOptions) (*coinbase.ConvertTrade, error)
+}
+
+// Commit calls CommitFunc.
+func (m *Converts) Commit(ctx context.Context, tradeID string, options coinbase.CommitConvertTradeOptions) (*coinbase.ConvertTrade, error) {
+	m.record("Commit", tradeID, options)
+
+	if m.CommitFunc == nil {
+		return nil, notMocked("Converts.Commit")
+	}
+
+	return m.CommitFunc(ctx, tradeID, options)
+}
+
+// CreateQuote calls CreateQuoteFunc.
+func (m *Converts) CreateQuote(ctx context.Context, options coinbase.CreateConvertQuoteOptions) (*coinbase.ConvertTrade, error) {
+	m.record("CreateQuote", options)
+
+	if m.CreateQuoteFunc == nil {
+		return nil, notMocked("Converts.CreateQuote")
+	}
+
+	return m.CreateQuoteFunc(ctx, options)
+}
+
+// GetTrade calls GetTradeFunc.
+func (m *Converts) GetTrade(ctx context.Context, tradeID string, options coinbase.GetConvertTradeOptions) (*coinbase.ConvertTrade, error) {
+	m.record("GetTrade", tradeID, options)
+
+	if m.GetTradeFunc == nil {
+		return nil, notMocked("Converts.GetTrade")
+	}
+
+	return m.GetTradeFunc(ctx, tradeID, options)
+}
+
+// Fees is a mock of coinbase.Fees.
+type Fees struct {
+	Recorder
+
+	GetTransactionsSummaryFunc func(ctx context.Context, options *coinbase.GetTransactionsSummaryOptions) (*coinbase.GetTransactionsSummaryResponse, error)
+}
+
+// GetTransactionsSummary calls GetTransactionsSummaryFunc.
+func (m *Fees) GetTransactionsSummary(ctx context.Context, options *coinbase.GetTransactionsSummaryOptions) (*coinbase.GetTransactionsSummaryResponse, error) {
+	m.record("GetTransactionsSummary", options)
+
+	if m.GetTransactionsSummaryFunc == nil {
+		return nil, notMocked("Fees.GetTransactionsSummary")
+	}
+
+	return m.GetTransactionsSummaryFunc(ctx, options)
+}
+
+// Futures is a mock of coinbase.Futures.
+type Futures struct {
+	Recorder
+
+	CancelPendingSweepFunc func(ctx context.Context) (bool, error)
+	GetBalanceSummaryFunc  func(ctx context.Context) (*coinbase.BalanceSummary, error)
+	GetPositionFunc        func(ctx context.Context, id string) (*coinbase.FuturesPosition, error)
+	ListPositionsFunc      func(ctx context.Context) ([]coinbase.FuturesPosition, error)
+	ListSweepsFunc         func(ctx context.Context) ([]coinbase.FuturesSweep, error)
+	ScheduleSweepFunc      func(ctx context.Context, options coinbase.ScheduleSweepOptions) (*coinbase.ScheduleSweepResponse, error)
+}
+
+// CancelPendingSweep calls CancelPendingSweepFunc.
+func (m *Futures) CancelPendingSweep(ctx context.Context) (bool, error) {
+	m.record("CancelPendingSweep")
+
+	if m.CancelPendingSweepFunc == nil {
+		return false, notMocked("Futures.CancelPendingSweep")
+	}
+
+	return m.CancelPendingSweepFunc(ctx)
+}
+
+// GetBalanceSummary calls GetBalanceSummaryFunc.
+func (m *Futures) GetBalanceSummary(ctx context.Context) (*coinbase.BalanceSummary, error) {
+	m.record("GetBalanceSummary")
+
+	if m.GetBalanceSummaryFunc == nil {
+		return nil, notMocked("Futures.GetBalanceSummary")
+	}
+
+	return m.GetBalanceSummaryFunc(ctx)
+}
+
+// GetPosition calls GetPositionFunc.
+func (m *Futures) GetPosition(ctx context.Context, id string) (*coinbase.FuturesPosition, error) {
+	m.record("GetPosition", id)
+
+	if m.GetPositionFunc == nil {
+		return nil, notMocked("Futures.GetPosition")
+	}
+
+	return m.GetPositionFunc(ctx, id)
+}
+
+// ListPositions calls ListPositionsFunc.
+func (m *Futures) ListPositions(ctx context.Context) ([]coinbase.FuturesPosition, error) {
+	m.record("ListPositions")
+
+	if m.ListPositionsFunc == nil {
+		return nil, notMocked("Futures.ListPositions")
+	}
+
+	return m.ListPositionsFunc(ctx)
+}
+
+// ListSweeps calls ListSweepsFunc.
+func (m *Futures) ListSweeps(ctx context.Context) ([]coinbase.FuturesSweep, error) {
+	m.record("ListSweeps")
+
+	if m.ListSweepsFunc == nil {
+		return nil, notMocked("Futures.ListSweeps")
+	}
+
+	return m.ListSweepsFunc(ctx)
+}
+
+// ScheduleSweep calls ScheduleSweepFunc.
+func (m *Futures) ScheduleSweep(ctx context.Context, options coinbase.ScheduleSweepOptions) (*coinbase.ScheduleSweepResponse, error) {
+	m.record("ScheduleSweep", options)
+
+	if m.ScheduleSweepFunc == nil {
+		return nil, notMocked("Futures.ScheduleSweep")
+	}
+
+	return m.ScheduleSweepFunc(ctx, options)
+}
+
+// Orders is a mock of coinbase.Orders.
+type Orders struct {
+	Recorder
+
+	AllFunc         func(ctx context.Context, options *coinbase.ListOrdersOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Order]
+	AllFillsFunc    func(ctx context.Context, options *coinbase.ListOrderFillsOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Fill]
+	CancelFunc      func(ctx context.Context, ids ...string) ([]coinbase.CancelledOrder, error)
+	CreateFunc      func(ctx context.Context, options coinbase.CreateOrderOptions) (*coinbase.CreateOrderResponse, error)
+	EditFunc        func(ctx context.Context, options coinbase.EditOrderOptions) (*coinbase.EditOrderResponse, error)
+	EditPreviewFunc func(ctx context.Context, options coinbase.EditOrderOptions) (*coinbase.EditOrderResponse, error)
+	GetFunc         func(ctx context.Context, id string) (*coinbase.Order, error)
+	ListFunc        func(ctx context.Context, options *coinbase.ListOrdersOptions) (*coinbase.ListOrdersResponse, error)
+	ListFillsFunc   func(ctx context.Context, options *coinbase.ListOrderFillsOptions) (*coinbase.ListFillsResponse, error)
+	PlaceFunc       func(ctx context.Context, options coinbase.CreateOrderOptions, opts ...coinbase.PlaceOrderOption) (*coinbase.PlaceOrderResult, error)
+	PreviewFunc     func(ctx context.Context, options coinbase.CreateOrderOptions) (*coinbase.PreviewOrderResponse, error)
+}
+
+// All calls AllFunc.
+func (m *Orders) All(ctx context.Context, options *coinbase.ListOrdersOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Order] {
+	m.record("All", options, opts)
+
+	if m.AllFunc == nil {
+		return errPager[coinbase.Order](ctx, "Orders.All")
+	}
+
+	return m.AllFunc(ctx, options, opts...)
+}
+
+// AllFills calls AllFillsFunc.
+func (m *Orders) AllFills(ctx context.Context, options *coinbase.ListOrderFillsOptions, opts ...coinbase.PagerOption) *coinbase.Pager[coinbase.Fill] {
+	m.record("AllFills", options, opts)
+
+	if m.AllFillsFunc == nil {
+		return errPager[coinbase.Fill](ctx, "Orders.AllFills")
+	}
+
+	return m.AllFillsFunc(ctx, options, opts...)
+}
+
+// Cancel calls CancelFunc.
+func (m *Orders) Cancel(ctx context.Context, ids ...string) ([]coinbase.CancelledOrder, error) {
+	m.record("Cancel", ids)
+
+	if m.CancelFunc == nil {
+		return nil, notMocked("Orders.Cancel")
+	}
+
+	return m.CancelFunc(ctx, ids...)
+}
+
+// Create calls CreateFunc.
+func (m *Orders) Create(ctx context.Context, options coinbase.CreateOrderOptions) (*coinbase.CreateOrderResponse, error) {
+	m.record("Create", options)
+
+	if m.CreateFunc == nil {
+		return nil, notMocked("Orders.Create")
+	}
+
+	return m.CreateFunc(ctx, options)
+}
+
+// Edit calls EditFunc.
+func (m *Orders) Edit(ctx context.Context, options coinbase.EditOrderOptions) (*coinbase.EditOrderResponse, error) {
+	m.record("Edit", options)
+
+	if m.EditFunc == nil {
+		return nil, notMocked("Orders.Edit")
+	}
+
+	return m.EditFunc(ctx, options)
+}
+
+// EditPreview calls EditPreviewFunc.
+func (m *Orders) EditPreview(ctx context.Context, options coinbase.EditOrderOptions) (*coinbase.EditOrderResponse, error) {
+	m.record("EditPreview", options)
+
+	if m.EditPreviewFunc == nil {
+		return nil, notMocked("Orders.EditPreview")
+	}
+
+	return m.EditPreviewFunc(ctx, options)
+}
+
+// Get calls GetFunc.
+func (m *Orders) Get(ctx context.Context, id string) (*coinbase.Order, error) {
+	m.record("Get", id)
+
+	if m.GetFunc == nil {
+		return nil, notMocked("Orders.Get")
+	}
+
+	return m.GetFunc(ctx, id)
+}
+
+// List calls ListFunc.
+func (m *Orders) List(ctx context.Context, options *coinbase.ListOrdersOptions) (*coinbase.ListOrdersResponse, error) {
+	m.record("List", options)
+
+	if m.ListFunc == nil {
+		return nil, notMocked("Orders.List")
+	}
+
+	return m.ListFunc(ctx, options)
+}
+
+// ListFills calls ListFillsFunc.
+func (m *Orders) ListFills(ctx context.Context, options *coinbase.ListOrderFillsOptions) (*coinbase.ListFillsResponse, error) {
+	m.record("ListFills", options)
+
+	if m.ListFillsFunc == nil {
+		return nil, notMocked("Orders.ListFills")
+	}
+
+	return m.ListFillsFunc(ctx, options)
+}
+
+// Place calls PlaceFunc.
+func (m *Orders) Place(ctx context.Context, options coinbase.CreateOrderOptions, opts ...coinbase.PlaceOrderOption) (*coinbase.PlaceOrderResult, error) {
+	m.record("Place", options, opts)
+
+	if m.PlaceFunc == nil {
+		return nil, notMocked("Orders.Place")
+	}
+
+	return m.PlaceFunc(ctx, options, opts...)
+}
+
+// Preview calls PreviewFunc.
+func (m *Orders) Preview(ctx context.Context, options coinbase.CreateOrderOptions) (*coinbase.PreviewOrderResponse, error) {
+	m.record("Preview", options)
+
+	if m.PreviewFunc == nil {
+		return nil, notMocked("Orders.Preview")
+	}
+
+	return m.PreviewFunc(ctx, options)
+}
+
+// PaymentMethods is a mock of coinbase.PaymentMethods.
+type PaymentMethods struct {
+	Recorder
+
+	GetFunc  func(ctx context.Context, id string) (*coinbase.PaymentMethod, error)
+	ListFunc func(ctx context.Context) ([]coinbase.PaymentMethod, error)
+}
+
+// Get calls GetFunc.
+func (m *PaymentMethods) Get(ctx context.Context, id string) (*coinbase.PaymentMethod, error) {
+	m.record("Get", id)
+
+	if m.GetFunc == nil {
+		return nil, notMocked("PaymentMethods.Get")
+	}
+
+	return m.GetFunc(ctx, id)
+}
+
+// List calls ListFunc.
+func (m *PaymentMethods) List(ctx context.Context) ([]coinbase.PaymentMethod, error) {
+	m.record("List")
+
+	if m.ListFunc == nil {
+		return nil, notMocked("PaymentMethods.List")
+	}
+
+	return m.ListFunc(ctx)
+}
+
+// Portfolios is a mock of coinbase.Portfolios.
+type Portfolios struct {
+	Recorder
+
+	AllocateFunc              func(ctx context.Context, options coinbase.AllocatePortfolioOptions) error
+	CreateFunc                func(ctx context.Context, name string) (*coinbase.Portfolio, error)
+	DeleteFunc                func(ctx context.Context, id uuid.UUID) error
+	EditFunc                  func(ctx context.Context, id uuid.UUID, options coinbase.EditPortfolioOptions) (*coinbase.Portfolio, error)
+	GetPortfolioBreakdownFunc func(ctx context.Context, id string) (*coinbase.PortfolioBreakdown, error)
+	ListFunc                  func(ctx context.Context, options *coinbase.ListPortfoliosOptions) (*coinbase.ListPortfoliosResponse, error)
+	MoveFundsFunc             func(ctx context.Context, options coinbase.PortfolioMoveFundsOptions) (*coinbase.PorfoliosMoveFundsResponse, error)
+}
+
+// Allocate calls AllocateFunc.
+func (m *Portfolios) Allocate(ctx context.Context, options coinbase.AllocatePortfolioOptions) error {
+	m.record("Allocate", options)
+
+	if m.AllocateFunc == nil {
+		return notMocked("Portfolios.Allocate")
+	}
+
+	return m.AllocateFunc(ctx, options)
+}
+
+// Create calls CreateFunc.
+func (m *Portfolios) Create(ctx context.Context, name string) (*coinbase.Portfolio, error) {
+	m.record("Create", name)
+
+	if m.CreateFunc == nil {
+		return nil, notMocked("Portfolios.Create")
+	}
+
+	return m.CreateFunc(ctx, name)
+}
+
+// Delete calls DeleteFunc.
+func (m *Portfolios) Delete(ctx context.Context, id uuid.UUID) error {
+	m.record("Delete", id)
+
+	if m.DeleteFunc == nil {
+		return notMocked("Portfolios.Delete")
+	}
+
+	return m.DeleteFunc(ctx, id)
+}
+
+// Edit calls EditFunc.
+func (m *Portfolios) Edit(ctx context.Context, id uuid.UUID, options coinbase.EditPortfolioOptions) (*coinbase.Portfolio, error) {
+	m.record("Edit", id, options)
+
+	if m.EditFunc == nil {
+		return nil, notMocked("Portfolios.Edit")
+	}
+
+	return m.EditFunc(ctx, id, options)
+}
+
+// GetPortfolioBreakdown calls GetPortfolioBreakdownFunc.
+func (m *Portfolios) GetPortfolioBreakdown(ctx context.Context, id string) (*coinbase.PortfolioBreakdown, error) {
+	m.record("GetPortfolioBreakdown", id)
+
+	if m.GetPortfolioBreakdownFunc == nil {
+		return nil, notMocked("Portfolios.GetPortfolioBreakdown")
+	}
+
+	return m.GetPortfolioBreakdownFunc(ctx, id)
+}
+
+// List calls ListFunc.
+func (m *Portfolios) List(ctx context.Context, options *coinbase.ListPortfoliosOptions) (*coinbase.ListPortfoliosResponse, error) {
+	m.record("List", options)
+
+	if m.ListFunc == nil {
+		return nil, notMocked("Portfolios.List")
+	}
+
+	return m.ListFunc(ctx, options)
+}
+
+// MoveFunds calls MoveFundsFunc.
+func (m *Portfolios) MoveFunds(ctx context.Context, options coinbase.PortfolioMoveFundsOptions) (*coinbase.PorfoliosMoveFundsResponse, error) {
+	m.record("MoveFunds", options)
+
+	if m.MoveFundsFunc == nil {
+		return nil, notMocked("Portfolios.MoveFunds")
+	}
+
+	return m.MoveFundsFunc(ctx, options)
+}
+
+// Products is a mock of coinbase.Products.
+type Products struct {
+	Recorder
+
+	GetFunc               func(ctx context.Context, id string) (*coinbase.Product, error)
+	GetBestBidAskFunc     func(ctx context.Context, ids ...string) (*coinbase.GetBestBidAskResponse, error)
+	GetCandleHistoryFunc  func(ctx context.Context, options coinbase.GetCandleHistoryOptions) (*coinbase.CandleHistory, error)
+	GetMarketTradesFunc   func(ctx context.Context, options coinbase.GetMarketTradeOptions) (*coinbase.GetMarketTradesResponse, error)
+	GetProductBookFunc    func(ctx context.Context, id string, limit *int) (*coinbase.PriceBook, error)
+	GetProductCandlesFunc func(ctx context.Context, options coinbase.GetProductCandlesOptions) ([]coinbase.Candles, error)
+	ListFunc              func(ctx context.Context, options *coinbase.ListProductsOptions) ([]coinbase.Product, error)
+	MaintainOrderBookFunc func(ctx context.Context, productID string, options *coinbase.OrderBookOptions) (*coinbase.OrderBook, error)
+}
+
+// Get calls GetFunc.
+func (m *Products) Get(ctx context.Context, id string) (*coinbase.Product, error) {
+	m.record("Get", id)
+
+	if m.GetFunc == nil {
+		return nil, notMocked("Products.Get")
+	}
+
+	return m.GetFunc(ctx, id)
+}
+
+// GetBestBidAsk calls GetBestBidAskFunc.
+func (m *Products) GetBestBidAsk(ctx context.Context, ids ...string) (*coinbase.GetBestBidAskResponse, error) {
+	m.record("GetBestBidAsk", ids)
+
+	if m.GetBestBidAskFunc == nil {
+		return nil, notMocked("Products.GetBestBidAsk")
+	}
+
+	return m.GetBestBidAskFunc(ctx, ids...)
+}
+
+// GetCandleHistory calls GetCandleHistoryFunc.
+func (m *Products) GetCandleHistory(ctx context.Context, options coinbase.GetCandleHistoryOptions) (*coinbase.CandleHistory, error) {
+	m.record("GetCandleHistory", options)
+
+	if m.GetCandleHistoryFunc == nil {
+		return nil, notMocked("Products.GetCandleHistory")
+	}
+
+	return m.GetCandleHistoryFunc(ctx, options)
+}
+
+// GetMarketTrades calls GetMarketTradesFunc.
+func (m *Products) GetMarketTrades(ctx context.Context, options coinbase.GetMarketTradeOptions) (*coinbase.GetMarketTradesResponse, error) {
+	m.record("GetMarketTrades", options)
+
+	if m.GetMarketTradesFunc == nil {
+		return nil, notMocked("Products.GetMarketTrades")
+	}
+
+	return m.GetMarketTradesFunc(ctx, options)
+}
+
+// GetProductBook calls GetProductBookFunc.
+func (m *Products) GetProductBook(ctx context.Context, id string, limit *int) (*coinbase.PriceBook, error) {
+	m.record("GetProductBook", id, limit)
+
+	if m.GetProductBookFunc == nil {
+		return nil, notMocked("Products.GetProductBook")
+	}
+
+	return m.GetProductBookFunc(ctx, id, limit)
+}
+
+// GetProductCandles calls GetProductCandlesFunc.
+func (m *Products) GetProductCandles(ctx context.Context, options coinbase.GetProductCandlesOptions) ([]coinbase.Candles, error) {
+	m.record("GetProductCandles", options)
+
+	if m.GetProductCandlesFunc == nil {
+		return nil, notMocked("Products.GetProductCandles")
+	}
+
+	return m.GetProductCandlesFunc(ctx, options)
+}
+
+// List calls ListFunc.
+func (m *Products) List(ctx context.Context, options *coinbase.ListProductsOptions) ([]coinbase.Product, error) {
+	m.record("List", options)
+
+	if m.ListFunc == nil {
+		return nil, notMocked("Products.List")
+	}
+
+	return m.ListFunc(ctx, options)
+}
+
+// MaintainOrderBook calls MaintainOrderBookFunc.
+func (m *Products) MaintainOrderBook(ctx context.Context, productID string, options *coinbase.OrderBookOptions) (*coinbase.OrderBook, error) {
+	m.record("MaintainOrderBook", productID, options)
+
+	if m.MaintainOrderBookFunc == nil {
+		return nil, notMocked("Products.MaintainOrderBook")
+	}
+
+	return m.MaintainOrderBookFunc(ctx, productID, options)
+}
+
+// Public is a mock of coinbase.Public.
+type Public struct {
+	Recorder
+
+	GetCandleHistoryFunc  func(ctx context.Context, options coinbase.GetCandleHistoryOptions) (*coinbase.CandleHistory, error)
+	GetMarketTradesFunc   func(ctx context.Context, options coinbase.GetMarketTradeOptions) (*coinbase.GetMarketTradesResponse, error)
+	GetProductFunc        func(ctx context.Context, id string) (*coinbase.Product, error)
+	GetProductBookFunc    func(ctx context.Context, opts coinbase.GetProductBookOptions) (*coinbase.PriceBook, error)
+	GetProductCandlesFunc func(ctx context.Context, options coinbase.GetProductCandlesOptions) ([]coinbase.Candles, error)
+	GetServerTimeFunc     func(ctx context.Context) (*coinbase.CoinbaseServerTime, error)
+	ListProductsFunc      func(ctx context.Context, options *coinbase.ListProductsOptions) ([]coinbase.Product, error)
+}
+
+// GetCandleHistory calls GetCandleHistoryFunc.
+func (m *Public) GetCandleHistory(ctx context.Context, options coinbase.GetCandleHistoryOptions) (*coinbase.CandleHistory, error) {
+	m.record("GetCandleHistory", options)
+
+	if m.GetCandleHistoryFunc == nil {
+		return nil, notMocked("Public.GetCandleHistory")
+	}
+
+	return m.GetCandleHistoryFunc(ctx, options)
+}
+
+// GetMarketTrades calls GetMarketTradesFunc.
+func (m *Public) GetMarketTrades(ctx context.Context, options coinbase.GetMarketTradeOptions) (*coinbase.GetMarketTradesResponse, error) {
+	m.record("GetMarketTrades", options)
+
+	if m.GetMarketTradesFunc == nil {
+		return nil, notMocked("Public.GetMarketTrades")
+	}
+
+	return m.GetMarketTradesFunc(ctx, options)
+}
+
+// GetProduct calls GetProductFunc.
+func (m *Public) GetProduct(ctx context.Context, id string) (*coinbase.Product, error) {
+	m.record("GetProduct", id)
+
+	if m.GetProductFunc == nil {
+		return nil, notMocked("Public.GetProduct")
+	}
+
+	return m.GetProductFunc(ctx, id)
+}
+
+// GetProductBook calls GetProductBookFunc.
+func (m *Public) GetProductBook(ctx context.Context, opts coinbase.GetProductBookOptions) (*coinbase.PriceBook, error) {
+	m.record("GetProductBook", opts)
+
+	if m.GetProductBookFunc == nil {
+		return nil, notMocked("Public.GetProductBook")
+	}
+
+	return m.GetProductBookFunc(ctx, opts)
+}
+
+// GetProductCandles calls GetProductCandlesFunc.
+func (m *Public) GetProductCandles(ctx context.Context, options coinbase.GetProductCandlesOptions) ([]coinbase.Candles, error) {
+	m.record("GetProductCandles", options)
+
+	if m.GetProductCandlesFunc == nil {
+		return nil, notMocked("Public.GetProductCandles")
+	}
+
+	return m.GetProductCandlesFunc(ctx, options)
+}
+
+// GetServerTime calls GetServerTimeFunc.
+func (m *Public) GetServerTime(ctx context.Context) (*coinbase.CoinbaseServerTime, error) {
+	m.record("GetServerTime")
+
+	if m.GetServerTimeFunc == nil {
+		return nil, notMocked("Public.GetServerTime")
+	}
+
+	return m.GetServerTimeFunc(ctx)
+}
+
+// ListProducts calls ListProductsFunc.
+func (m *Public) ListProducts(ctx context.Context, options *coinbase.ListProductsOptions) ([]coinbase.Product, error) {
+	m.record("ListProducts", options)
+
+	if m.ListProductsFunc == nil {
+		return nil, notMocked("Public.ListProducts")
+	}
+
+	return m.ListProductsFunc(ctx, options)
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+const (
+	defaultAccountsLimit = 49
+	maxAccountsLimit     = 250
+)
+
+func (s *Server) listAccounts(w http.ResponseWriter, c *call) {
+	start, end, next, err := paginate(len(s.state.accounts), c.query("limit"), c.query("cursor"), defaultAccountsLimit, maxAccountsLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	accounts := make([]coinbase.Account, 0, end-start)
+	for _, a := range s.state.accounts[start:end] {
+		accounts = append(accounts, *a)
+	}
+
+	size := int32(len(accounts))
+
+	writeJSON(w, coinbase.ListAccountsResponse{
+		Accounts: accounts,
+		HasNext:  next != "",
+		Cursor:   &next,
+		Size:     &size,
+	})
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, c *call) {
+	for _, a := range s.state.accounts {
+		if a.ID.String() == c.params[0] {
+			writeJSON(w, map[string]any{"account": a})
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "NOT_FOUND", "account not found")
+}
+
+// paginate returns the range of a page of n items, and the cursor of the next page or an empty string if
+// it is the last one. Cursors are offsets.
+func paginate(n int, limit string, cursor string, defaultLimit int, maxLimit int) (start int, end int, next string, err error) {
+	size := defaultLimit
+
+	if limit != "" {
+		size, err = strconv.Atoi(limit)
+		if err != nil || size <= 0 {
+			return 0, 0, "", errInvalid("limit", limit)
+		}
+
+		if size > maxLimit {
+			size = maxLimit
+		}
+	}
+
+	if cursor != "" {
+		start, err = strconv.Atoi(cursor)
+		if err != nil || start < 0 {
+			return 0, 0, "", errInvalid("cursor", cursor)
+		}
+	}
+
+	if start > n {
+		start = n
+	}
+
+	end = start + size
+	if end >= n {
+		return start, n, "", nil
+	}
+
+	return start, end, strconv.Itoa(end), nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+const (
+	jwtIssuer          = "coinbase-cloud"
+	jwtAudience        = "retail_rest_api_proxy"
+	legacyTimestampAge = 30 * time.Second // Coinbase rejects legacy signatures older or newer than this.
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+type cloudKey struct {
+	secret string
+	public crypto.PublicKey
+}
+
+// WithCloudKey configures a Cloud API trading key the server accepts. The secret is the EC or Ed25519
+// private key passed to coinbase.NewWithCloud, use NewCloudKey or NewEd25519CloudKey to generate one.
+func WithCloudKey(name string, secret string) Option {
+	return func(s *Server) {
+		key, err := coinbase.ParsePrivateKey(secret)
+		if err != nil {
+			panic(fmt.Sprintf("coinbasetest: failed to parse cloud key secret: %v", err))
+		}
+
+		s.addKey(name)
+		s.cloud[name] = cloudKey{secret: secret, public: key.Public()}
+	}
+}
+
+// WithLegacyKey configures a legacy API key the server accepts.
+func WithLegacyKey(key string, secret string) Option {
+	return func(s *Server) {
+		s.addKey(key)
+		s.legacy[key] = secret
+	}
+}
+
+// addKey records the name of a configured key, keeping the order keys were first configured in.
+func (s *Server) addKey(name string) {
+	_, cloud := s.cloud[name]
+	_, legacy := s.legacy[name]
+
+	if !cloud && !legacy {
+		s.keys = append(s.keys, name)
+	}
+}
+
+// NewCloudKey generates a Cloud API trading key, returning a name and a PEM encoded secret that can be
+// passed to both WithCloudKey and coinbase.NewWithCloud.
+func NewCloudKey() (name string, secret string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate EC key: %w", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal EC key: %w", err)
+	}
+
+	id := make([]byte, 8)
+
+	_, err = rand.Read(id)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate key name: %w", err)
+	}
+
+	name = "organizations/coinbasetest/apiKeys/" + hex.EncodeToString(id)
+	secret = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+
+	return name, secret, nil
+}
+
+// NewEd25519CloudKey generates an Ed25519 Cloud API key like those of the Coinbase Developer Platform,
+// returning an ID and a base64 encoded secret that can be passed to both WithCloudKey and coinbase.NewWithCloud.
+func NewEd25519CloudKey() (id string, secret string, err error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate Ed25519 key: %w", err)
+	}
+
+	buf := make([]byte, 16)
+
+	_, err = rand.Read(buf)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate key ID: %w", err)
+	}
+
+	return hex.EncodeToString(buf), base64.StdEncoding.EncodeToString(key), nil
+}
+
+// authenticate checks the request is signed with one of the configured keys and returns the key's name.
+// Every request is accepted if no keys are configured. The lock must be held.
+func (s *Server) authenticate(r *http.Request, body []byte) (string, error) {
+	if len(s.cloud) == 0 && len(s.legacy) == 0 {
+		return "", nil
+	}
+
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return s.authenticateCloud(r, strings.TrimPrefix(auth, "Bearer "))
+	}
+
+	if r.Header.Get("CB-ACCESS-KEY") != "" {
+		return s.authenticateLegacy(r, body)
+	}
+
+	return "", errUnauthorized
+}
+
+func (s *Server) authenticateCloud(r *http.Request, token string) (string, error) {
+	var name string
+
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		name, _ = t.Header["kid"].(string)
+
+		key, ok := s.cloud[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown key '%s'", name)
+		}
+
+		return key.public, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(jwtIssuer),
+		jwt.WithAudience(jwtAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return "", errors.Join(errUnauthorized, err)
+	}
+
+	// The token must be issued for the key it is signed with, and for this very request.
+	if sub, _ := claims.GetSubject(); sub != name {
+		return "", errUnauthorized
+	}
+
+	if uri, _ := claims["uri"].(string); uri != r.Method+" "+r.Host+r.URL.Path {
+		return "", errUnauthorized
+	}
+
+	return name, nil
+}
+
+func (s *Server) authenticateLegacy(r *http.Request, body []byte) (string, error) {
+	key := r.Header.Get("CB-ACCESS-KEY")
+
+	secret, ok := s.legacy[key]
+	if !ok {
+		return "", errUnauthorized
+	}
+
+	ts := r.Header.Get("CB-ACCESS-TIMESTAMP")
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", errUnauthorized
+	}
+
+	if age := time.Since(time.Unix(unix, 0)); age > legacyTimestampAge || age < -legacyTimestampAge {
+		return "", errUnauthorized
+	}
+
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts + strings.ToUpper(r.Method) + r.URL.Path + string(body)))
+
+	sig, err := hex.DecodeString(r.Header.Get("CB-ACCESS-SIGN"))
+	if err != nil || !hmac.Equal(sig, h.Sum(nil)) {
+		return "", errUnauthorized
+	}
+
+	return key, nil
+}
@@ -0,0 +1,561 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// decimalPlaces is the precision of divisions, such as average prices.
+const decimalPlaces = 18
+
+// Now returns the time of the server's clock.
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.now()
+}
+
+// SetTime stops the server's clock at t. Orders are created and filled at the time of the clock and good
+// till date orders expire once it passes their end time. The clock follows the real time until SetTime
+// is called.
+func (s *Server) SetTime(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.clock = t.UTC()
+	s.state.expire()
+}
+
+// Advance moves the server's clock forward, starting from the real time if SetTime was not called.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.clock = s.state.now().Add(d)
+	s.state.expire()
+}
+
+// SetFeeTier sets the fee rates of fills: the maker rate applies to fills of resting orders and the taker
+// rate to fills of incoming orders. The default tier charges 0.4% and 0.6%.
+func (s *Server) SetFeeTier(tier coinbase.FeeTier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.transactionSummary.FeeTier = tier
+}
+
+func (st *state) now() time.Time {
+	if st.clock.IsZero() {
+		return time.Now().UTC()
+	}
+
+	return st.clock
+}
+
+// feeRate returns the rate charged on fills with the liquidity indicator.
+func (st *state) feeRate(liquidity coinbase.LiquidityIndicator) coinbase.Decimal {
+	rate := st.transactionSummary.FeeTier.TakerFeeRate
+	if liquidity == coinbase.LiquidityIndicatorMaker {
+		rate = st.transactionSummary.FeeTier.MakerFeeRate
+	}
+
+	if rate == nil {
+		return coinbase.Decimal{}
+	}
+
+	d, _ := coinbase.ParseDecimal(*rate)
+
+	return d
+}
+
+// progress is how much of an order has been filled.
+type progress struct {
+	size  coinbase.Decimal // Filled base size.
+	value coinbase.Decimal // Filled quote value, excluding fees.
+	fees  coinbase.Decimal
+}
+
+func progressOf(o *coinbase.Order) progress {
+	size, _ := o.FilledSizeDecimal()
+	value, _ := o.FilledValueDecimal()
+	fees, _ := o.TotalFeesDecimal()
+
+	return progress{size: size, value: value, fees: fees}
+}
+
+// left returns the base size the order can still fill at the price. Orders sized in quote spend their
+// size on the value and, for buys, on the fees.
+func (p progress) left(spec orderSpec, side coinbase.Side, price coinbase.Decimal, rate coinbase.Decimal, increment coinbase.Decimal) coinbase.Decimal {
+	var size coinbase.Decimal
+
+	switch {
+	case spec.baseSize != nil:
+		size = spec.baseSize.Sub(p.size)
+	case side == coinbase.SideBuy:
+		unit := price.Add(price.Mul(rate))
+		size = spec.quoteSize.Sub(p.value).Sub(p.fees).Div(unit, decimalPlaces)
+	default:
+		size = spec.quoteSize.Sub(p.value).Div(price, decimalPlaces)
+	}
+
+	if increment.Sign() > 0 {
+		size = size.Quantize(increment, coinbase.RoundDown)
+	}
+
+	if size.Sign() < 0 {
+		return coinbase.Decimal{}
+	}
+
+	return size
+}
+
+// add returns the progress after a fill.
+func (p progress) add(price coinbase.Decimal, size coinbase.Decimal, commission coinbase.Decimal) progress {
+	return progress{size: p.size.Add(size), value: p.value.Add(price.Mul(size)), fees: p.fees.Add(commission)}
+}
+
+// crosses reports whether an order of the side at the limit price would take liquidity from the book.
+func (st *state) crosses(productID string, side coinbase.Side, limit coinbase.Decimal) bool {
+	best := st.bestPrice(productID, side)
+
+	return best != nil && acceptable(side, *best, &limit)
+}
+
+// acceptable reports whether an order of the side with the limit price, if any, can trade at the price.
+func acceptable(side coinbase.Side, price coinbase.Decimal, limit *coinbase.Decimal) bool {
+	switch {
+	case limit == nil:
+		return true
+	case side == coinbase.SideBuy:
+		return !price.GreaterThan(*limit)
+	default:
+		return !price.LessThan(*limit)
+	}
+}
+
+// execute matches a new order against the book. Market, immediate or cancel and fill or kill orders are
+// done afterwards, the rest of the other orders rests on the book. Stop orders wait for their trigger.
+func (st *state) execute(o *coinbase.Order, spec orderSpec) {
+	if *o.TriggerStatus == coinbase.TriggerStatusStopPending {
+		return
+	}
+
+	if spec.timeInForce == coinbase.TimeInForceFillOrKill && !st.take(o, spec, true) {
+		st.finish(o, coinbase.OrderStatusCancelled)
+		return
+	}
+
+	switch {
+	case st.take(o, spec, false):
+		st.finish(o, coinbase.OrderStatusFilled)
+	case spec.orderType == coinbase.OrderTypeMarket || spec.timeInForce == coinbase.TimeInForceImmediateOrCancel ||
+		spec.timeInForce == coinbase.TimeInForceFillOrKill:
+		st.finish(o, coinbase.OrderStatusCancelled)
+	}
+}
+
+// take fills the order against the levels of the book it crosses, best price first, and reports whether
+// the order is completely filled. The book is left untouched on a dry run.
+func (st *state) take(o *coinbase.Order, spec orderSpec, dryRun bool) bool {
+	product := st.product(o.ProductID)
+	increment, _ := coinbase.ParseDecimal(product.BaseIncrement)
+	rate := st.feeRate(coinbase.LiquidityIndicatorTaker)
+	side := *o.Side
+
+	book, ok := st.books[o.ProductID]
+	if !ok {
+		book = &coinbase.PriceBook{ProductID: o.ProductID}
+		st.books[o.ProductID] = book
+	}
+
+	levels := &book.Asks
+	if side == coinbase.SideSell {
+		levels = &book.Bids
+	}
+
+	p := progressOf(o)
+
+	for i := 0; i < len(*levels); i++ {
+		level := (*levels)[i]
+
+		price, err := coinbase.ParseDecimal(deref(level.Price))
+		if err != nil || !acceptable(side, price, spec.limitPrice) {
+			break
+		}
+
+		available, err := coinbase.ParseDecimal(deref(level.Size))
+		if err != nil || available.Sign() <= 0 {
+			continue
+		}
+
+		want := p.left(spec, side, price, rate, increment)
+		if want.Sign() <= 0 {
+			break
+		}
+
+		size := want
+		if available.LessThan(size) {
+			size = available
+		}
+
+		commission := price.Mul(size).Mul(rate)
+		p = p.add(price, size, commission)
+
+		if dryRun {
+			continue
+		}
+
+		st.fill(o, spec, price, size, coinbase.LiquidityIndicatorTaker)
+
+		if rest := available.Sub(size); rest.Sign() > 0 {
+			(*levels)[i].Size = coinbase.String(formatDecimal(rest))
+		} else {
+			*levels = append((*levels)[:i], (*levels)[i+1:]...)
+			i--
+		}
+	}
+
+	return filled(p, spec, side, spec.limitPrice, rate, increment, st.bestPrice(o.ProductID, side))
+}
+
+// filled reports whether nothing is left of the order. Orders sized in quote are filled once the rest of
+// the size buys less than the base increment, at the limit price or else the best price.
+func filled(p progress, spec orderSpec, side coinbase.Side, limit *coinbase.Decimal, rate coinbase.Decimal, increment coinbase.Decimal, best *coinbase.Decimal) bool {
+	if spec.baseSize != nil {
+		return !p.size.LessThan(*spec.baseSize)
+	}
+
+	price := limit
+	if price == nil {
+		price = best
+	}
+
+	if price == nil {
+		return false
+	}
+
+	return p.left(spec, side, *price, rate, increment).Sign() <= 0
+}
+
+// trade matches a market trade against the open orders of the product: a SELL trade fills buy orders at
+// or above its price and a BUY trade fills sell orders at or below it, up to the size of the trade in
+// price-time priority. A trade without a side fills both. Orders fill at their limit price as makers.
+// Stop orders are triggered afterwards if the price of the trade reached their stop price.
+func (st *state) trade(productID string, t coinbase.Trade) {
+	price, err := coinbase.ParseDecimal(deref(t.Price))
+	if err != nil {
+		return
+	}
+
+	size, err := coinbase.ParseDecimal(deref(t.Size))
+	if err != nil {
+		return
+	}
+
+	if t.Side == nil || *t.Side == coinbase.SideSell {
+		st.make(productID, coinbase.SideBuy, price, size)
+	}
+
+	if t.Side == nil || *t.Side == coinbase.SideBuy {
+		st.make(productID, coinbase.SideSell, price, size)
+	}
+
+	st.trigger(productID, price)
+}
+
+// make fills resting orders of the side that accept the price, up to the size.
+func (st *state) make(productID string, side coinbase.Side, price coinbase.Decimal, size coinbase.Decimal) {
+	product := st.product(productID)
+	if product == nil {
+		return
+	}
+
+	increment, _ := coinbase.ParseDecimal(product.BaseIncrement)
+	rate := st.feeRate(coinbase.LiquidityIndicatorMaker)
+
+	type resting struct {
+		order *coinbase.Order
+		spec  orderSpec
+	}
+
+	var book []resting
+
+	for _, o := range st.orders {
+		if o.ProductID != productID || *o.Side != side || !st.resting(o) {
+			continue
+		}
+
+		spec, r := parseSpec(*o.Configuration)
+		if r != nil || spec.limitPrice == nil || !acceptable(side, price, spec.limitPrice) {
+			continue
+		}
+
+		book = append(book, resting{order: o, spec: spec})
+	}
+
+	// Best price first, then the oldest order.
+	sort.SliceStable(book, func(i, j int) bool {
+		a, b := *book[i].spec.limitPrice, *book[j].spec.limitPrice
+		if !a.Equal(b) {
+			return (side == coinbase.SideBuy) == a.GreaterThan(b)
+		}
+
+		return book[i].order.CreatedTime.Before(book[j].order.CreatedTime)
+	})
+
+	for _, r := range book {
+		if size.Sign() <= 0 {
+			return
+		}
+
+		limit := *r.spec.limitPrice
+
+		fill := progressOf(r.order).left(r.spec, side, limit, rate, increment)
+		if size.LessThan(fill) {
+			fill = size
+		}
+
+		if fill.Sign() <= 0 {
+			continue
+		}
+
+		st.fill(r.order, r.spec, limit, fill, coinbase.LiquidityIndicatorMaker)
+		size = size.Sub(fill)
+
+		if filled(progressOf(r.order), r.spec, side, &limit, rate, increment, nil) {
+			st.finish(r.order, coinbase.OrderStatusFilled)
+		}
+	}
+}
+
+// resting reports whether the order is open on the book, excluding stop orders waiting for their trigger.
+func (st *state) resting(o *coinbase.Order) bool {
+	return *o.Status == coinbase.OrderStatusOpen && (o.TriggerStatus == nil || *o.TriggerStatus != coinbase.TriggerStatusStopPending)
+}
+
+// trigger triggers the stop orders of the product whose stop price the price reached. Stop limit orders
+// then trade as limit orders, the stop leg of a bracket order as a market order.
+func (st *state) trigger(productID string, price coinbase.Decimal) {
+	for _, o := range st.orders {
+		if o.ProductID != productID || *o.Status != coinbase.OrderStatusOpen || o.TriggerStatus == nil ||
+			*o.TriggerStatus != coinbase.TriggerStatusStopPending {
+			continue
+		}
+
+		spec, r := parseSpec(*o.Configuration)
+		if r != nil || !stopReached(spec, *o.Side, price) {
+			continue
+		}
+
+		triggered := coinbase.TriggerStatusStopTriggered
+		o.TriggerStatus = &triggered
+
+		if spec.orderType == coinbase.OrderTypeBracket {
+			spec.limitPrice = nil
+			spec.timeInForce = coinbase.TimeInForceImmediateOrCancel
+		}
+
+		st.execute(o, spec)
+	}
+}
+
+// stopReached reports whether the price reached the stop price of the order. Without a stop direction,
+// buys stop on the way up and sells on the way down.
+func stopReached(spec orderSpec, side coinbase.Side, price coinbase.Decimal) bool {
+	up := side == coinbase.SideBuy
+	if spec.direction != nil {
+		up = *spec.direction == coinbase.StopDirectionUp
+	}
+
+	if up {
+		return !price.LessThan(*spec.stopPrice)
+	}
+
+	return !price.GreaterThan(*spec.stopPrice)
+}
+
+// expire expires the open good till date orders whose end time the clock passed.
+func (st *state) expire() {
+	now := st.now()
+
+	for _, o := range st.orders {
+		if *o.Status != coinbase.OrderStatusOpen || o.TimeInForce == nil || *o.TimeInForce != coinbase.TimeInForceGoodUntilDate {
+			continue
+		}
+
+		spec, r := parseSpec(*o.Configuration)
+		if r == nil && spec.endTime != nil && !spec.endTime.After(now) {
+			st.finish(o, coinbase.OrderStatusExpired)
+		}
+	}
+}
+
+// fill records a fill of the order, settles it against the accounts and updates the order.
+func (st *state) fill(o *coinbase.Order, spec orderSpec, price coinbase.Decimal, size coinbase.Decimal, liquidity coinbase.LiquidityIndicator) {
+	product := st.product(o.ProductID)
+	base, quote := currencies(product)
+	now := st.now()
+
+	value := price.Mul(size)
+	commission := value.Mul(st.feeRate(liquidity))
+
+	// Buys pay the value and the fee from the hold of the order, sells their size. Whatever the hold does
+	// not cover is taken from the available balance.
+	if *o.Side == coinbase.SideBuy {
+		st.debit(o, quote, value.Add(commission))
+		st.credit(base, size)
+
+		// The hold of a limit buy covers its size at the limit price and the taker fee, what a fill at a
+		// better price or as a maker did not use is released.
+		if spec.baseSize != nil && spec.limitPrice != nil {
+			reserved := spec.limitPrice.Mul(size)
+			reserved = reserved.Add(reserved.Mul(st.feeRate(coinbase.LiquidityIndicatorTaker)))
+
+			st.release(o, reserved.Sub(value.Add(commission)))
+		}
+	} else {
+		st.debit(o, base, size)
+		st.credit(quote, value.Sub(commission))
+	}
+
+	tradeType := coinbase.TradeTypeFill
+
+	st.fills = append(st.fills, coinbase.Fill{
+		EntryID:            coinbase.String(uuid.NewString()),
+		TradeID:            coinbase.String(uuid.NewString()),
+		OrderID:            coinbase.String(o.ID),
+		TradeTime:          &now,
+		TradeType:          &tradeType,
+		Price:              coinbase.String(formatDecimal(price)),
+		Size:               coinbase.String(formatDecimal(size)),
+		Commission:         coinbase.String(formatDecimal(commission)),
+		ProductID:          coinbase.String(o.ProductID),
+		SequenceTimestamp:  &now,
+		LiquidityIndicator: &liquidity,
+		SizeInQuote:        coinbase.Bool(o.SizeInQuote),
+		UserID:             coinbase.String(o.UserID),
+		Side:               o.Side,
+	})
+
+	p := progressOf(o).add(price, size, commission)
+	fills, _ := coinbase.ParseDecimal(o.NumberOfFills)
+
+	total := p.value.Add(p.fees)
+	if *o.Side == coinbase.SideSell {
+		total = p.value.Sub(p.fees)
+	}
+
+	completion := coinbase.NewDecimalFromInt(100)
+
+	switch {
+	case spec.baseSize != nil:
+		completion = p.size.Mul(completion).Div(*spec.baseSize, 2)
+	case *o.Side == coinbase.SideBuy:
+		completion = p.value.Add(p.fees).Mul(completion).Div(*spec.quoteSize, 2)
+	default:
+		completion = p.value.Mul(completion).Div(*spec.quoteSize, 2)
+	}
+
+	o.FilledSize = coinbase.String(formatDecimal(p.size))
+	o.FilledValue = coinbase.String(formatDecimal(p.value))
+	o.TotalFees = formatDecimal(p.fees)
+	o.TotalValueAfterFees = formatDecimal(total)
+	o.AverageFilledPrice = formatDecimal(p.value.Div(p.size, decimalPlaces))
+	o.NumberOfFills = formatDecimal(fills.Add(coinbase.NewDecimalFromInt(1)))
+	o.CompletionPercentage = formatDecimal(completion)
+	o.LastFillTime = &now
+
+	if h := st.holds[o.ID]; h != nil {
+		o.OutstandingHoldAmount = coinbase.String(formatDecimal(h.amount))
+	}
+}
+
+// debit takes the amount from the hold of the order, and what the hold does not cover from the available balance.
+func (st *state) debit(o *coinbase.Order, currency string, amount coinbase.Decimal) {
+	a := st.account(currency)
+	available, _ := coinbase.ParseDecimal(a.AvailableBalance.Value)
+	onHold, _ := coinbase.ParseDecimal(a.Hold.Value)
+
+	fromHold := coinbase.Decimal{}
+
+	if h := st.holds[o.ID]; h != nil && h.currency == currency {
+		fromHold = amount
+		if h.amount.LessThan(fromHold) {
+			fromHold = h.amount
+		}
+
+		h.amount = h.amount.Sub(fromHold)
+	}
+
+	a.Hold.Value = formatDecimal(onHold.Sub(fromHold))
+	a.AvailableBalance.Value = formatDecimal(available.Sub(amount.Sub(fromHold)))
+}
+
+// release returns up to amount of the hold of the order to the available balance.
+func (st *state) release(o *coinbase.Order, amount coinbase.Decimal) {
+	h := st.holds[o.ID]
+	if h == nil || amount.Sign() <= 0 {
+		return
+	}
+
+	if h.amount.LessThan(amount) {
+		amount = h.amount
+	}
+
+	h.amount = h.amount.Sub(amount)
+
+	st.releaseHold(&hold{currency: h.currency, amount: amount})
+}
+
+// credit adds the amount to the available balance.
+func (st *state) credit(currency string, amount coinbase.Decimal) {
+	a := st.account(currency)
+	available, _ := coinbase.ParseDecimal(a.AvailableBalance.Value)
+
+	a.AvailableBalance.Value = formatDecimal(available.Add(amount))
+}
+
+// finish moves the order to a final status and releases what is left of its hold.
+func (st *state) finish(o *coinbase.Order, status coinbase.OrderStatus) {
+	o.Status = &status
+	o.Settled = coinbase.Bool(status == coinbase.OrderStatusFilled)
+	o.OutstandingHoldAmount = coinbase.String("0")
+
+	st.releaseHold(st.holds[o.ID])
+	delete(st.holds, o.ID)
+}
+
+// formatDecimal formats the decimal without trailing zeros.
+func formatDecimal(d coinbase.Decimal) string {
+	s := d.String()
+
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+func level(price, size string) coinbase.BidAsk {
+	return coinbase.BidAsk{Price: coinbase.String(price), Size: coinbase.String(size)}
+}
+
+func trade(price, size string) coinbase.Trade {
+	return coinbase.Trade{Price: coinbase.String(price), Size: coinbase.String(size)}
+}
+
+func TestEngine(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		asks    []coinbase.BidAsk
+		config  coinbase.OrderConfiguration
+		trades  []coinbase.Trade
+		advance time.Duration
+		taker   string // Taker fee rate, 0 if empty.
+		status  coinbase.OrderStatus
+		filled  string
+		trigger coinbase.TriggerStatus // Expected trigger status if not empty.
+		usd     string                 // Available USD balance afterwards, starting from 1000.
+		hold    string                 // USD on hold afterwards.
+		fills   int
+	}{
+		{
+			name:   "market order walks the book",
+			asks:   []coinbase.BidAsk{level("100", "1"), level("200", "1")},
+			config: coinbase.NewMarketIOC(coinbase.QuoteSize("150")),
+			status: coinbase.OrderStatusFilled,
+			filled: "1.25",
+			usd:    "850",
+			hold:   "0",
+			fills:  2,
+		},
+		{
+			name:   "market order cancelled once the book is empty",
+			asks:   []coinbase.BidAsk{level("100", "1")},
+			config: coinbase.NewMarketIOC(coinbase.BaseSize("2")),
+			status: coinbase.OrderStatusCancelled,
+			filled: "1",
+			usd:    "900",
+			hold:   "0",
+			fills:  1,
+		},
+		{
+			name:   "fill or kill not filled in full",
+			asks:   []coinbase.BidAsk{level("100", "1"), level("200", "1")},
+			config: coinbase.NewLimitFOK(coinbase.BaseSize("2"), "150"),
+			status: coinbase.OrderStatusCancelled,
+			filled: "0",
+			usd:    "1000",
+			hold:   "0",
+		},
+		{
+			name:   "limit order only takes levels at its price",
+			asks:   []coinbase.BidAsk{level("100", "1"), level("200", "1")},
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("2"), "150", false),
+			status: coinbase.OrderStatusOpen,
+			filled: "1",
+			usd:    "750",
+			hold:   "150",
+			fills:  1,
+		},
+		{
+			name:   "resting order filled by a trade",
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("1"), "90", false),
+			trades: []coinbase.Trade{trade("90", "1")},
+			status: coinbase.OrderStatusFilled,
+			filled: "1",
+			usd:    "910",
+			hold:   "0",
+			fills:  1,
+		},
+		{
+			name:   "resting order partially filled by trades",
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("1"), "90", false),
+			trades: []coinbase.Trade{trade("95", "1"), trade("90", "0.4")},
+			status: coinbase.OrderStatusOpen,
+			filled: "0.4",
+			usd:    "910",
+			hold:   "54",
+			fills:  1,
+		},
+		{
+			name:   "maker fill releases the taker fee held",
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("1"), "90", false),
+			taker:  "0.01",
+			trades: []coinbase.Trade{trade("90", "0.4")},
+			status: coinbase.OrderStatusOpen,
+			filled: "0.4",
+			usd:    "909.46",
+			hold:   "54.54",
+			fills:  1,
+		},
+		{
+			name:    "good till date order expires",
+			config:  coinbase.NewLimitGTD(coinbase.BaseSize("1"), "90", now.Add(time.Hour), false),
+			advance: 2 * time.Hour,
+			status:  coinbase.OrderStatusExpired,
+			filled:  "0",
+			usd:     "1000",
+			hold:    "0",
+		},
+		{
+			name:    "stop order waits for its price",
+			config:  coinbase.NewStopLimitGTC("1", "120", "110", coinbase.StopDirectionUp),
+			trades:  []coinbase.Trade{trade("105", "1")},
+			status:  coinbase.OrderStatusOpen,
+			filled:  "0",
+			trigger: coinbase.TriggerStatusStopPending,
+			usd:     "880",
+			hold:    "120",
+		},
+		{
+			name:    "stop order triggered by a trade",
+			config:  coinbase.NewStopLimitGTC("1", "120", "110", coinbase.StopDirectionUp),
+			trades:  []coinbase.Trade{trade("110", "1"), trade("115", "1")},
+			status:  coinbase.OrderStatusFilled,
+			filled:  "1",
+			trigger: coinbase.TriggerStatusStopTriggered,
+			usd:     "880",
+			hold:    "0",
+			fills:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := coinbasetest.NewServer()
+			defer srv.Close()
+
+			srv.SetTime(now)
+			srv.AddProduct(coinbase.Product{ID: "BTC-USD", BaseIncrement: "0.00000001", QuoteIncrement: "0.01"})
+
+			taker := tt.taker
+			if taker == "" {
+				taker = "0"
+			}
+
+			srv.SetFeeTier(coinbase.FeeTier{TakerFeeRate: coinbase.String(taker), MakerFeeRate: coinbase.String("0")})
+			srv.SetBalance("USD", "1000")
+			srv.SetProductBook(coinbase.PriceBook{ProductID: "BTC-USD", Asks: tt.asks})
+
+			client, err := srv.Client()
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			side := coinbase.SideBuy
+
+			resp, err := client.Orders.Create(context.Background(), coinbase.CreateOrderOptions{
+				ProductID:          "BTC-USD",
+				Side:               &side,
+				OrderConfiguration: tt.config,
+			})
+			if err != nil || !resp.Success {
+				t.Fatalf("failed to create order: %v %+v", err, resp)
+			}
+
+			srv.AddTrades("BTC-USD", tt.trades...)
+			srv.Advance(tt.advance)
+
+			order, ok := srv.Order(resp.SuccessResponse.OrderID)
+			if !ok {
+				t.Fatal("order not found")
+			}
+
+			if *order.Status != tt.status {
+				t.Fatalf("status is %s, want %s", *order.Status, tt.status)
+			}
+
+			if !coinbase.MustParseDecimal(*order.FilledSize).Equal(coinbase.MustParseDecimal(tt.filled)) {
+				t.Fatalf("filled %s, want %s", *order.FilledSize, tt.filled)
+			}
+
+			if tt.trigger != "" && *order.TriggerStatus != tt.trigger {
+				t.Fatalf("trigger status is %s, want %s", *order.TriggerStatus, tt.trigger)
+			}
+
+			usd, hold := srv.Balance("USD")
+			if !coinbase.MustParseDecimal(usd).Equal(coinbase.MustParseDecimal(tt.usd)) || !coinbase.MustParseDecimal(hold).Equal(coinbase.MustParseDecimal(tt.hold)) {
+				t.Fatalf("USD balance is %s with %s on hold, want %s with %s on hold", usd, hold, tt.usd, tt.hold)
+			}
+
+			if fills := len(srv.Fills()); fills != tt.fills {
+				t.Fatalf("%d fills, want %d", fills, tt.fills)
+			}
+		})
+	}
+}
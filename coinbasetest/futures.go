@@ -0,0 +1,107 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func (s *Server) getFuturesBalanceSummary(w http.ResponseWriter, c *call) {
+	writeJSON(w, map[string]any{"balance_summary": s.state.balanceSummary})
+}
+
+func (s *Server) listFuturesPositions(w http.ResponseWriter, c *call) {
+	positions := append([]coinbase.FuturesPosition{}, s.state.futuresPositions...)
+
+	writeJSON(w, map[string]any{"positions": positions})
+}
+
+func (s *Server) getFuturesPosition(w http.ResponseWriter, c *call) {
+	for _, p := range s.state.futuresPositions {
+		if p.ProductID != nil && *p.ProductID == c.params[0] {
+			writeJSON(w, map[string]any{"position": p})
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "NOT_FOUND", "position not found")
+}
+
+func (s *Server) listFuturesSweeps(w http.ResponseWriter, c *call) {
+	sweeps := append([]coinbase.FuturesSweep{}, s.state.futuresSweeps...)
+
+	writeJSON(w, map[string]any{"sweeps": sweeps})
+}
+
+func (s *Server) scheduleFuturesSweep(w http.ResponseWriter, c *call) {
+	var req coinbase.ScheduleSweepOptions
+
+	if err := c.decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	// Only one sweep can be pending at a time.
+	if s.state.pendingSweep() >= 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "a sweep is already pending")
+		return
+	}
+
+	status := coinbase.SweepStatusPending
+	now := s.state.now()
+
+	sweep := coinbase.FuturesSweep{
+		ID:             coinbase.String(uuid.NewString()),
+		ShouldSweepAll: coinbase.Bool(req.USDAmmount == nil || *req.USDAmmount == ""),
+		Status:         &status,
+		ScheduledTime:  &now,
+	}
+
+	sweep.RequestedAmount.Currency = coinbase.String("USD")
+	sweep.RequestedAmount.Value = coinbase.String("0")
+
+	if !*sweep.ShouldSweepAll {
+		amount, err := coinbase.ParseDecimal(*req.USDAmmount)
+		if err != nil || amount.Sign() <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("usd_amount", *req.USDAmmount).Error())
+			return
+		}
+
+		sweep.RequestedAmount.Value = coinbase.String(amount.String())
+	}
+
+	s.state.futuresSweeps = append(s.state.futuresSweeps, sweep)
+
+	writeJSON(w, coinbase.ScheduleSweepResponse{Success: coinbase.Bool(true)})
+}
+
+func (s *Server) cancelFuturesSweep(w http.ResponseWriter, c *call) {
+	i := s.state.pendingSweep()
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "no pending sweep")
+		return
+	}
+
+	s.state.futuresSweeps = append(s.state.futuresSweeps[:i], s.state.futuresSweeps[i+1:]...)
+
+	writeJSON(w, map[string]any{"success": true})
+}
+
+// pendingSweep returns the index of the pending sweep, or -1 if there is none.
+func (st *state) pendingSweep() int {
+	for i, sweep := range st.futuresSweeps {
+		if sweep.Status != nil && *sweep.Status == coinbase.SweepStatusPending {
+			return i
+		}
+	}
+
+	return -1
+}
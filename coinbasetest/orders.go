@@ -0,0 +1,840 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+const (
+	userID               = "coinbasetest"
+	defaultOrdersLimit   = 1000
+	maxOrdersLimit       = 1000
+	defaultFillsLimit    = 100
+	maxFillsLimit        = 1000
+	maxEditHistory       = 5
+	maxCancelOrderIDs    = 100
+	insufficientFundsMsg = "Insufficient balance in source account"
+)
+
+// orderSpec is the configuration of an order, whatever its type.
+type orderSpec struct {
+	orderType   coinbase.OrderType
+	timeInForce coinbase.TimeInForce
+	baseSize    *coinbase.Decimal
+	quoteSize   *coinbase.Decimal
+	limitPrice  *coinbase.Decimal
+	stopPrice   *coinbase.Decimal
+	direction   *coinbase.StopDirection
+	postOnly    bool
+	endTime     *time.Time
+}
+
+// hold is the amount of an account reserved by an open order.
+type hold struct {
+	currency string
+	amount   coinbase.Decimal
+}
+
+// rejection is the reason an order was not accepted, in both the create and preview vocabulary.
+type rejection struct {
+	reason  coinbase.OrderFailureReason
+	preview coinbase.PreviewFailureReason
+	message string
+}
+
+func (r *rejection) response() coinbase.CreateOrderResponse {
+	unknown := coinbase.OrderFailureReasonUnknown
+	reason := r.reason
+	preview := r.preview
+
+	return coinbase.CreateOrderResponse{
+		Success:            false,
+		OrderFailureReason: &unknown,
+		ErrorResponse: coinbase.CreateOrderErrorMetadata{
+			Error:                 &reason,
+			Message:               coinbase.String(r.message),
+			ErrorDetails:          coinbase.String(r.message),
+			PreviewFailureReason:  &preview,
+			NewOrderFailureReason: &reason,
+		},
+	}
+}
+
+func reject(reason coinbase.OrderFailureReason, preview coinbase.PreviewFailureReason, message string) *rejection {
+	return &rejection{reason: reason, preview: preview, message: message}
+}
+
+// parseSpec parses the single order configuration that must be set.
+func parseSpec(cfg coinbase.OrderConfiguration) (orderSpec, *rejection) {
+	var (
+		specs []orderSpec
+		bad   bool
+	)
+
+	parse := func(s *string) *coinbase.Decimal {
+		if s == nil {
+			return nil
+		}
+
+		d, err := coinbase.ParseDecimal(*s)
+		if err != nil || d.Sign() <= 0 {
+			bad = true
+			return nil
+		}
+
+		return &d
+	}
+
+	add := func(t coinbase.OrderType, tif coinbase.TimeInForce, base, quote, limit, stop *string, postOnly *bool, end *time.Time) {
+		spec := orderSpec{
+			orderType:   t,
+			timeInForce: tif,
+			baseSize:    parse(base),
+			quoteSize:   parse(quote),
+			limitPrice:  parse(limit),
+			stopPrice:   parse(stop),
+			postOnly:    postOnly != nil && *postOnly,
+			endTime:     end,
+		}
+
+		specs = append(specs, spec)
+	}
+
+	if c := cfg.MarketIOC; c != nil {
+		add(coinbase.OrderTypeMarket, coinbase.TimeInForceImmediateOrCancel, c.BaseSize, c.QuoteSize, nil, nil, nil, nil)
+	}
+
+	if c := cfg.MarketFOK; c != nil {
+		add(coinbase.OrderTypeMarket, coinbase.TimeInForceFillOrKill, c.BaseSize, c.QuoteSize, nil, nil, nil, nil)
+	}
+
+	if c := cfg.SORLimitIOC; c != nil {
+		add(coinbase.OrderTypeLimt, coinbase.TimeInForceImmediateOrCancel, c.BaseSize, c.QuoteSize, c.LimitPrice, nil, nil, nil)
+	}
+
+	if c := cfg.LimitGTC; c != nil {
+		add(coinbase.OrderTypeLimt, coinbase.TimeInForceGoodUntilCancelled, c.BaseSize, c.QuoteSize, c.LimitPrice, nil, c.PostOnly, nil)
+	}
+
+	if c := cfg.LimitGTD; c != nil {
+		add(coinbase.OrderTypeLimt, coinbase.TimeInForceGoodUntilDate, c.BaseSize, c.QuoteSize, c.LimitPrice, nil, c.PostOnly, c.EndTime)
+	}
+
+	if c := cfg.LimitFOK; c != nil {
+		add(coinbase.OrderTypeLimt, coinbase.TimeInForceFillOrKill, c.BaseSize, c.QuoteSize, c.LimitPrice, nil, nil, nil)
+	}
+
+	if c := cfg.StopLimitGTC; c != nil {
+		add(coinbase.OrderTypeStopLimit, coinbase.TimeInForceGoodUntilCancelled, c.BaseSize, nil, c.LimitPrice, c.StopPrice, nil, nil)
+		specs[len(specs)-1].direction = c.StopDirection
+	}
+
+	if c := cfg.StopLimitGTD; c != nil {
+		add(coinbase.OrderTypeStopLimit, coinbase.TimeInForceGoodUntilDate, c.BaseSize, nil, c.LimitPrice, c.StopPrice, nil, c.EndTime)
+		specs[len(specs)-1].direction = c.StopDirection
+	}
+
+	if c := cfg.TriggerBracketGTC; c != nil {
+		add(coinbase.OrderTypeBracket, coinbase.TimeInForceGoodUntilCancelled, c.BaseSize, nil, c.LimitPrice, c.StopTriggerPrice, nil, nil)
+	}
+
+	if c := cfg.TriggerBracketGTD; c != nil {
+		add(coinbase.OrderTypeBracket, coinbase.TimeInForceGoodUntilDate, c.BaseSize, nil, c.LimitPrice, c.StopTriggerPrice, nil, c.EndTime)
+	}
+
+	if len(specs) != 1 || bad {
+		return orderSpec{}, reject(
+			coinbase.OrderFailureReasonUnsupportedOrderConfiguration,
+			coinbase.PreviewFailureReasonInvalidOrderConfig,
+			"exactly one valid order configuration is required",
+		)
+	}
+
+	spec := specs[0]
+
+	if (spec.baseSize == nil) == (spec.quoteSize == nil) {
+		return orderSpec{}, reject(
+			coinbase.OrderFailureReasonInvalidRequest,
+			coinbase.PreviewFailureReasonInvalidOrderConfig,
+			"exactly one of base_size and quote_size is required",
+		)
+	}
+
+	if spec.orderType != coinbase.OrderTypeMarket && spec.limitPrice == nil {
+		return orderSpec{}, reject(coinbase.OrderFailureReasonInvalidLimitPrice, coinbase.PreviewFailureReasonInvalidLimitPrice, "limit_price is required")
+	}
+
+	if (spec.orderType == coinbase.OrderTypeStopLimit || spec.orderType == coinbase.OrderTypeBracket) && spec.stopPrice == nil {
+		return orderSpec{}, reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidStopPrice, "stop price is required")
+	}
+
+	if spec.timeInForce == coinbase.TimeInForceGoodUntilDate && spec.endTime == nil {
+		return orderSpec{}, reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidOrderConfig, "end_time is required")
+	}
+
+	return spec, nil
+}
+
+// validate checks the order against the product and the balances, returning the hold the order needs.
+func (st *state) validate(options coinbase.CreateOrderOptions, spec orderSpec) (*hold, *rejection) {
+	product := st.product(options.ProductID)
+	if product == nil {
+		return nil, reject(coinbase.OrderFailureReasonInvalidProductID, coinbase.PreviewFailureReasonInvalidProductID, "product not found")
+	}
+
+	if options.Side == nil || (*options.Side != coinbase.SideBuy && *options.Side != coinbase.SideSell) {
+		return nil, reject(coinbase.OrderFailureReasonInvalidSide, coinbase.PreviewFailureReasonInvalidOrderConfig, "side must be BUY or SELL")
+	}
+
+	if product.IsDisabled || product.TradingDisabled || product.CancelOnly {
+		return nil, reject(coinbase.OrderFailureReasonOrderEntryDisabled, coinbase.PreviewFailureReasonInvalidOrderConfig, "order entry is disabled for the product")
+	}
+
+	if product.LimitOnly && spec.orderType == coinbase.OrderTypeMarket {
+		return nil, reject(coinbase.OrderFailureReasonOrderEntryDisabled, coinbase.PreviewFailureReasonInvalidOrderConfig, "product only accepts limit orders")
+	}
+
+	if product.PostOnly && spec.orderType != coinbase.OrderTypeMarket && !spec.postOnly {
+		return nil, reject(coinbase.OrderFailureReasonInvalidLimitPricePostOnly, coinbase.PreviewFailureReasonInvalidLimitPricePostOnly, "product only accepts post only orders")
+	}
+
+	// A post only order must rest on the book, it is rejected if it would take liquidity.
+	if spec.postOnly && st.crosses(options.ProductID, *options.Side, *spec.limitPrice) {
+		return nil, reject(coinbase.OrderFailureReasonInvalidLimitPricePostOnly, coinbase.PreviewFailureReasonInvalidLimitPricePostOnly, "post only order would take liquidity")
+	}
+
+	if spec.endTime != nil && !spec.endTime.After(st.now()) {
+		return nil, reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidOrderConfig, "end_time must be in the future")
+	}
+
+	if spec.baseSize != nil {
+		if r := checkSize(*spec.baseSize, product.BaseIncrement, product.BaseMinimimSize, product.BaseMaximumSize, "base"); r != nil {
+			return nil, r
+		}
+	}
+
+	if spec.quoteSize != nil {
+		if r := checkSize(*spec.quoteSize, product.QuoteIncrement, product.QuoteMinimumSize, product.QuoteMaximumSize, "quote"); r != nil {
+			return nil, r
+		}
+	}
+
+	increment, _ := product.PriceIncrementDecimal()
+
+	for _, price := range []*coinbase.Decimal{spec.limitPrice, spec.stopPrice} {
+		if price != nil && increment.Sign() > 0 && !price.Quantize(increment, coinbase.RoundDown).Equal(*price) {
+			return nil, reject(coinbase.OrderFailureReasonInvalidPricePrecision, coinbase.PreviewFailureReasonInvalidPricePrecision, "price does not match the price increment")
+		}
+	}
+
+	h := st.holdFor(product, *options.Side, spec)
+	if h != nil {
+		available, _ := coinbase.ParseDecimal(st.account(h.currency).AvailableBalance.Value)
+		if available.LessThan(h.amount) {
+			return nil, reject(coinbase.OrderFailureReasonInsufficientFund, coinbase.PreviewFailureReasonInsufficientFund, insufficientFundsMsg)
+		}
+	}
+
+	return h, nil
+}
+
+func checkSize(size coinbase.Decimal, increment, minimum, maximum string, name string) *rejection {
+	inc, _ := coinbase.ParseDecimal(increment)
+	if inc.Sign() > 0 && !size.Quantize(inc, coinbase.RoundDown).Equal(size) {
+		if name == "base" {
+			return reject(coinbase.OrderFailureReasonInvalidSizePrecision, coinbase.PreviewFailureReasonInvalidSizePrecision, "base size does not match the base increment")
+		}
+
+		return reject(coinbase.OrderFailureReasonInvalidSizePrecision, coinbase.PreviewFailureReasonInvalidQuoteSizePrecision, "quote size does not match the quote increment")
+	}
+
+	if lower, err := coinbase.ParseDecimal(minimum); err == nil && size.LessThan(lower) {
+		if name == "base" {
+			return reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidBaseSizeTooSmall, "base size is below the minimum")
+		}
+
+		return reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidQuoteSizeTooSmall, "quote size is below the minimum")
+	}
+
+	if upper, err := coinbase.ParseDecimal(maximum); err == nil && upper.Sign() > 0 && size.GreaterThan(upper) {
+		if name == "base" {
+			return reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidBaseSizeTooLarge, "base size is above the maximum")
+		}
+
+		return reject(coinbase.OrderFailureReasonInvalidRequest, coinbase.PreviewFailureReasonInvalidQuoteSizeTooLarge, "quote size is above the maximum")
+	}
+
+	return nil
+}
+
+// holdFor returns the funds an order reserves: the quote amount including the taker fee for buys and the
+// base size for sells. Nil is returned if the amount depends on a market price that is not known.
+func (st *state) holdFor(product *coinbase.Product, side coinbase.Side, spec orderSpec) *hold {
+	base, quote := currencies(product)
+
+	if side == coinbase.SideSell {
+		if spec.baseSize != nil {
+			return &hold{currency: base, amount: *spec.baseSize}
+		}
+
+		price := spec.limitPrice
+		if price == nil {
+			price = st.bestPrice(product.ID, coinbase.SideSell)
+		}
+
+		if price == nil {
+			return nil
+		}
+
+		return &hold{currency: base, amount: spec.quoteSize.Div(*price, 18)}
+	}
+
+	if spec.quoteSize != nil {
+		return &hold{currency: quote, amount: *spec.quoteSize}
+	}
+
+	price := spec.limitPrice
+	if price == nil {
+		price = st.bestPrice(product.ID, coinbase.SideBuy)
+	}
+
+	if price == nil {
+		return nil
+	}
+
+	value := spec.baseSize.Mul(*price)
+
+	return &hold{currency: quote, amount: value.Add(value.Mul(st.feeRate(coinbase.LiquidityIndicatorTaker)))}
+}
+
+// bestPrice returns the best price an order of the side would trade at: the best ask for buys and the best bid for sells.
+func (st *state) bestPrice(productID string, side coinbase.Side) *coinbase.Decimal {
+	book := st.book(productID)
+
+	levels := book.Asks
+	if side == coinbase.SideSell {
+		levels = book.Bids
+	}
+
+	if len(levels) == 0 || levels[0].Price == nil {
+		return nil
+	}
+
+	price, err := coinbase.ParseDecimal(*levels[0].Price)
+	if err != nil {
+		return nil
+	}
+
+	return &price
+}
+
+// currencies returns the base and quote currency of the product.
+func currencies(product *coinbase.Product) (string, string) {
+	base, quote, _ := strings.Cut(product.ID, "-")
+
+	if product.BaseCurrencyID != nil && *product.BaseCurrencyID != "" {
+		base = *product.BaseCurrencyID
+	}
+
+	if product.QuoteCurrencyID != nil && *product.QuoteCurrencyID != "" {
+		quote = *product.QuoteCurrencyID
+	}
+
+	return base, quote
+}
+
+// placeHold moves the amount of the hold from the available balance to the balance on hold.
+func (st *state) placeHold(h *hold) {
+	if h == nil {
+		return
+	}
+
+	a := st.account(h.currency)
+
+	available, _ := coinbase.ParseDecimal(a.AvailableBalance.Value)
+	onHold, _ := coinbase.ParseDecimal(a.Hold.Value)
+
+	a.AvailableBalance.Value = formatDecimal(available.Sub(h.amount))
+	a.Hold.Value = formatDecimal(onHold.Add(h.amount))
+}
+
+// releaseHold returns the amount of the hold to the available balance.
+func (st *state) releaseHold(h *hold) {
+	if h == nil {
+		return
+	}
+
+	st.placeHold(&hold{currency: h.currency, amount: h.amount.Neg()})
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, c *call) {
+	var options coinbase.CreateOrderOptions
+
+	if err := c.decode(&options); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	// Coinbase does not create a second order for a client order ID, it returns the existing one.
+	if options.ClientOrderID != "" {
+		for _, o := range s.state.orders {
+			if o.ClientOrderID == options.ClientOrderID {
+				writeJSON(w, createdResponse(o))
+				return
+			}
+		}
+	}
+
+	spec, r := parseSpec(options.OrderConfiguration)
+	if r == nil {
+		var h *hold
+
+		h, r = s.state.validate(options, spec)
+		if r == nil {
+			o := s.state.newOrder(options, spec, h)
+			s.state.execute(o, spec)
+
+			writeJSON(w, createdResponse(o))
+
+			return
+		}
+	}
+
+	writeJSON(w, r.response())
+}
+
+func createdResponse(o *coinbase.Order) coinbase.CreateOrderResponse {
+	return coinbase.CreateOrderResponse{
+		Success: true,
+		OrderID: coinbase.String(o.ID),
+		SuccessResponse: coinbase.CreateOrderSuccessMetadata{
+			OrderID:       o.ID,
+			ProductID:     coinbase.String(o.ProductID),
+			Side:          o.Side,
+			ClientOrderID: coinbase.String(o.ClientOrderID),
+		},
+		OrderConfiguration: o.Configuration,
+	}
+}
+
+// newOrder records an accepted order and reserves its funds.
+func (st *state) newOrder(options coinbase.CreateOrderOptions, spec orderSpec, h *hold) *coinbase.Order {
+	product := st.product(options.ProductID)
+
+	clientOrderID := options.ClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = uuid.NewString()
+	}
+
+	status := coinbase.OrderStatusOpen
+	orderType := spec.orderType
+	timeInForce := spec.timeInForce
+	source := coinbase.OrderPlacementSourceRetailAdvanced
+	cfg := options.OrderConfiguration
+
+	trigger := coinbase.TriggerStatusInvalidOrderType
+	if spec.stopPrice != nil {
+		trigger = coinbase.TriggerStatusStopPending
+	}
+
+	o := coinbase.Order{
+		ID:                   uuid.NewString(),
+		ProductID:            options.ProductID,
+		UserID:               userID,
+		Configuration:        &cfg,
+		Side:                 options.Side,
+		ClientOrderID:        clientOrderID,
+		Status:               &status,
+		TimeInForce:          &timeInForce,
+		CreatedTime:          st.now(),
+		CompletionPercentage: "0",
+		FilledSize:           coinbase.String("0"),
+		AverageFilledPrice:   "0",
+		NumberOfFills:        "0",
+		FilledValue:          coinbase.String("0"),
+		TotalFees:            "0",
+		TotalValueAfterFees:  "0",
+		SizeInQuote:          spec.quoteSize != nil,
+		TriggerStatus:        &trigger,
+		Type:                 &orderType,
+		Settled:              coinbase.Bool(false),
+		ProductType:          product.Type,
+		OrderPlacementSource: &source,
+		IsLiquidation:        coinbase.Bool(false),
+	}
+
+	if h != nil {
+		o.OutstandingHoldAmount = coinbase.String(formatDecimal(h.amount))
+
+		st.holds[o.ID] = h
+		st.placeHold(h)
+	}
+
+	st.orders = append(st.orders, &o)
+
+	return &o
+}
+
+func (s *Server) previewOrder(w http.ResponseWriter, c *call) {
+	var options coinbase.CreateOrderOptions
+
+	if err := c.decode(&options); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	resp := coinbase.PreviewOrderResponse{
+		Errs:      []coinbase.PreviewFailureReason{},
+		Warning:   []coinbase.PreviewWarning{},
+		PreviewID: coinbase.String(uuid.NewString()),
+	}
+
+	if side := options.Side; side != nil {
+		if bid := s.state.bestPrice(options.ProductID, coinbase.SideSell); bid != nil {
+			resp.BestBid = coinbase.String(bid.String())
+		}
+
+		if ask := s.state.bestPrice(options.ProductID, coinbase.SideBuy); ask != nil {
+			resp.BestAsk = coinbase.String(ask.String())
+		}
+	}
+
+	spec, r := parseSpec(options.OrderConfiguration)
+	if r == nil {
+		var h *hold
+
+		h, r = s.state.validate(options, spec)
+		if r == nil && h != nil {
+			if *options.Side == coinbase.SideBuy {
+				// The hold of a buy is the value of the order plus the taker fee.
+				rate := s.state.feeRate(coinbase.LiquidityIndicatorTaker)
+				value := h.amount.Div(coinbase.NewDecimalFromInt(1).Add(rate), decimalPlaces)
+
+				resp.QuoteSize = coinbase.String(formatDecimal(value))
+				resp.CommissionTotal = coinbase.String(formatDecimal(h.amount.Sub(value)))
+				resp.OrderTotal = coinbase.String(formatDecimal(h.amount))
+			} else {
+				resp.BaseSize = coinbase.String(formatDecimal(h.amount))
+			}
+		}
+	}
+
+	if r != nil {
+		resp.Errs = append(resp.Errs, r.preview)
+	}
+
+	if spec.baseSize != nil {
+		resp.BaseSize = coinbase.String(spec.baseSize.String())
+	}
+
+	if spec.quoteSize != nil {
+		resp.QuoteSize = coinbase.String(spec.quoteSize.String())
+	}
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) editOrder(w http.ResponseWriter, c *call) {
+	s.edit(w, c, true)
+}
+
+func (s *Server) editOrderPreview(w http.ResponseWriter, c *call) {
+	s.edit(w, c, false)
+}
+
+func (s *Server) edit(w http.ResponseWriter, c *call, apply bool) {
+	var options coinbase.EditOrderOptions
+
+	if err := c.decode(&options); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	fail := func(reason coinbase.EditFailureReason, preview *coinbase.PreviewFailureReason) {
+		e := coinbase.EditOrderError{PreviewFailureReason: preview}
+		if reason != "" {
+			e.EditFailureReason = &reason
+		}
+
+		writeJSON(w, coinbase.EditOrderResponse{Success: false, Errors: []coinbase.EditOrderError{e}})
+	}
+
+	o := s.state.order(options.OrderID)
+	if o == nil {
+		fail(coinbase.EditFailureReasonNotFound, nil)
+		return
+	}
+
+	if *o.Status != coinbase.OrderStatusOpen {
+		fail(coinbase.EditFailureReasonOnlyOpenOrdersCanBeEdited, nil)
+		return
+	}
+
+	cfg := o.Configuration.LimitGTC
+	if cfg == nil || cfg.BaseSize == nil {
+		fail(coinbase.EditFailureReasonOnlyLimitOrderEditsSupported, nil)
+		return
+	}
+
+	price, _ := coinbase.ParseDecimal(*cfg.LimitPrice)
+	size, _ := coinbase.ParseDecimal(*cfg.BaseSize)
+	newPrice, newSize := price, size
+
+	var err error
+
+	if options.Price != nil {
+		newPrice, err = coinbase.ParseDecimal(*options.Price)
+		if err != nil || newPrice.Sign() <= 0 {
+			fail(coinbase.EditFailureReasonInvalidEditedPrice, nil)
+			return
+		}
+	}
+
+	if options.Size != nil {
+		newSize, err = coinbase.ParseDecimal(*options.Size)
+		if err != nil || newSize.Sign() <= 0 {
+			fail(coinbase.EditFailureReasonInvalidEditedSize, nil)
+			return
+		}
+	}
+
+	if newPrice.Equal(price) && newSize.Equal(size) {
+		fail(coinbase.EditFailureReasonEditEqualToOriginal, nil)
+		return
+	}
+
+	filled, _ := o.FilledSizeDecimal()
+	if newSize.LessThan(filled) || newSize.Equal(filled) {
+		fail(coinbase.EditFailureReasonBelowFilledSize, nil)
+		return
+	}
+
+	remaining := newSize.Sub(filled)
+	spec := orderSpec{orderType: coinbase.OrderTypeLimt, baseSize: &remaining, limitPrice: &newPrice, postOnly: cfg.PostOnly != nil && *cfg.PostOnly}
+
+	// The order's own hold is available to the edited order.
+	old := s.state.holds[o.ID]
+	s.state.releaseHold(old)
+
+	h, r := s.state.validate(coinbase.CreateOrderOptions{ProductID: o.ProductID, Side: o.Side}, spec)
+	if r != nil || !apply {
+		s.state.placeHold(old)
+
+		if r != nil {
+			fail("", &r.preview)
+			return
+		}
+
+		writeJSON(w, coinbase.EditOrderResponse{Success: true, Errors: []coinbase.EditOrderError{}})
+
+		return
+	}
+
+	s.state.placeHold(h)
+
+	if h != nil {
+		s.state.holds[o.ID] = h
+		o.OutstandingHoldAmount = coinbase.String(formatDecimal(h.amount))
+	}
+
+	now := s.state.now()
+
+	o.EditHistory = append(o.EditHistory, struct {
+		Price                  *string    `json:"price"`
+		Size                   *string    `json:"size"`
+		ReplaceAcceptTimestamp *time.Time `json:"replace_accept_timestamp"`
+	}{Price: coinbase.String(newPrice.String()), Size: coinbase.String(newSize.String()), ReplaceAcceptTimestamp: &now})
+
+	if len(o.EditHistory) > maxEditHistory {
+		o.EditHistory = o.EditHistory[len(o.EditHistory)-maxEditHistory:]
+	}
+
+	edited := *cfg
+	edited.LimitPrice = coinbase.String(newPrice.String())
+	edited.BaseSize = coinbase.String(newSize.String())
+
+	o.Configuration = &coinbase.OrderConfiguration{LimitGTC: &edited}
+
+	// An edit that crosses the book trades like a new order.
+	spec.baseSize = &newSize
+
+	if s.state.take(o, spec, false) {
+		s.state.finish(o, coinbase.OrderStatusFilled)
+	}
+
+	writeJSON(w, coinbase.EditOrderResponse{Success: true, Errors: []coinbase.EditOrderError{}})
+}
+
+func (s *Server) cancelOrders(w http.ResponseWriter, c *call) {
+	var req struct {
+		OrderIDs []string `json:"order_ids"`
+	}
+
+	if err := c.decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	if len(req.OrderIDs) > maxCancelOrderIDs {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Too many orderIDs entered, limit is 100")
+		return
+	}
+
+	results := make([]coinbase.CancelledOrder, 0, len(req.OrderIDs))
+
+	for _, id := range req.OrderIDs {
+		result := coinbase.CancelledOrder{ID: id}
+
+		var reason coinbase.CancelOrderFailureReason
+
+		switch o := s.state.order(id); {
+		case o == nil:
+			reason = coinbase.CancelOrderFailureReasonUnknownOrder
+		case *o.Status == coinbase.OrderStatusCancelled:
+			reason = coinbase.CancelOrderFailureReasonDuplicateRequest
+		case *o.Status != coinbase.OrderStatusOpen && *o.Status != coinbase.OrderStatusPending:
+			reason = coinbase.CancelOrderFailureReasonInvalidRequest
+		default:
+			s.state.finish(o, coinbase.OrderStatusCancelled)
+			result.Success = true
+		}
+
+		if reason != "" {
+			result.FailureReason = &reason
+		}
+
+		results = append(results, result)
+	}
+
+	writeJSON(w, map[string]any{"results": results})
+}
+
+func (s *Server) listOrders(w http.ResponseWriter, c *call) {
+	statuses := make(map[string]bool)
+	for _, status := range c.queryList("order_status") {
+		statuses[status] = true
+	}
+
+	if statuses[string(coinbase.OrderStatusOpen)] && len(statuses) > 1 {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "OPEN orders cannot be queried together with other statuses")
+		return
+	}
+
+	types := make(map[string]bool)
+	for _, t := range append(c.queryList("order_types"), c.queryList("order_type")...) {
+		types[t] = true
+	}
+
+	var start, end time.Time
+
+	if v := c.query("start_date"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("start_date", v).Error())
+			return
+		}
+
+		start = t
+	}
+
+	if v := c.query("end_date"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("end_date", v).Error())
+			return
+		}
+
+		end = t
+	}
+
+	productID := c.query("product_id")
+	side := c.query("order_side")
+
+	orders := []coinbase.Order{}
+
+	for _, o := range s.state.orders {
+		open := *o.Status == coinbase.OrderStatusOpen
+
+		switch {
+		case productID != "" && o.ProductID != productID,
+			len(statuses) > 0 && !statuses[string(*o.Status)],
+			len(types) > 0 && (o.Type == nil || !types[string(*o.Type)]),
+			side != "" && (o.Side == nil || string(*o.Side) != side),
+			// Date filters do not apply to open orders.
+			!open && !start.IsZero() && o.CreatedTime.Before(start),
+			!open && !end.IsZero() && !o.CreatedTime.Before(end):
+			continue
+		}
+
+		orders = append(orders, *o)
+	}
+
+	// Most recent first.
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedTime.After(orders[j].CreatedTime)
+	})
+
+	from, to, next, err := paginate(len(orders), c.query("limit"), c.query("cursor"), defaultOrdersLimit, maxOrdersLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	writeJSON(w, coinbase.ListOrdersResponse{
+		Orders:  orders[from:to],
+		HasNext: next != "",
+		Cursor:  &next,
+	})
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, c *call) {
+	o := s.state.order(c.params[0])
+	if o == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "order not found")
+		return
+	}
+
+	writeJSON(w, map[string]any{"order": o})
+}
+
+func (s *Server) listFills(w http.ResponseWriter, c *call) {
+	orderID := c.query("order_id")
+	productID := c.query("product_id")
+
+	fills := []coinbase.Fill{}
+
+	// Most recent first.
+	for i := len(s.state.fills) - 1; i >= 0; i-- {
+		f := s.state.fills[i]
+
+		if orderID != "" && (f.OrderID == nil || *f.OrderID != orderID) {
+			continue
+		}
+
+		if productID != "" && (f.ProductID == nil || *f.ProductID != productID) {
+			continue
+		}
+
+		fills = append(fills, f)
+	}
+
+	from, to, next, err := paginate(len(fills), c.query("limit"), c.query("cursor"), defaultFillsLimit, maxFillsLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	writeJSON(w, coinbase.ListFillsResponse{Fills: fills[from:to], Cursor: &next})
+}
@@ -0,0 +1,31 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"net/http"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func (s *Server) listPaymentMethods(w http.ResponseWriter, c *call) {
+	methods := append([]coinbase.PaymentMethod{}, s.state.paymentMethods...)
+
+	writeJSON(w, map[string]any{"payment_methods": methods})
+}
+
+func (s *Server) getPaymentMethod(w http.ResponseWriter, c *call) {
+	for _, m := range s.state.paymentMethods {
+		if m.ID == c.params[0] {
+			writeJSON(w, map[string]any{"payment_method": m})
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "NOT_FOUND", "payment method not found")
+}
@@ -0,0 +1,220 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func (s *Server) listPortfolios(w http.ResponseWriter, c *call) {
+	t := c.query("portfolio_type")
+
+	portfolios := []coinbase.Portfolio{}
+
+	for _, p := range s.state.portfolios {
+		if *p.Deleted || (t != "" && string(*p.Type) != t) {
+			continue
+		}
+
+		portfolios = append(portfolios, *p)
+	}
+
+	writeJSON(w, coinbase.ListPortfoliosResponse{Portfolios: portfolios})
+}
+
+func (s *Server) createPortfolio(w http.ResponseWriter, c *call) {
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.decode(&req); err != nil || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "name is required")
+		return
+	}
+
+	if s.state.portfolioNamed(req.Name) != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "a portfolio with this name already exists")
+		return
+	}
+
+	id := uuid.New()
+
+	p := &coinbase.Portfolio{
+		Name:    coinbase.String(req.Name),
+		UUID:    &id,
+		Type:    portfolioType(coinbase.PortfolioTypeConsumer),
+		Deleted: coinbase.Bool(false),
+	}
+
+	s.state.portfolios = append(s.state.portfolios, p)
+
+	writeJSON(w, map[string]any{"portfolio": p})
+}
+
+func (s *Server) editPortfolio(w http.ResponseWriter, c *call) {
+	var req coinbase.EditPortfolioOptions
+
+	if err := c.decode(&req); err != nil || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "name is required")
+		return
+	}
+
+	p := s.state.portfolio(c.params[0])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "portfolio not found")
+		return
+	}
+
+	if other := s.state.portfolioNamed(req.Name); other != nil && other != p {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "a portfolio with this name already exists")
+		return
+	}
+
+	p.Name = coinbase.String(req.Name)
+
+	writeJSON(w, map[string]any{"portfolio": p})
+}
+
+func (s *Server) deletePortfolio(w http.ResponseWriter, c *call) {
+	p := s.state.portfolio(c.params[0])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "portfolio not found")
+		return
+	}
+
+	if *p.Type == coinbase.PortfolioTypeDefault {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "the default portfolio cannot be deleted")
+		return
+	}
+
+	p.Deleted = coinbase.Bool(true)
+
+	writeJSON(w, struct{}{})
+}
+
+func (s *Server) getPortfolioBreakdown(w http.ResponseWriter, c *call) {
+	p := s.state.portfolio(c.params[0])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "portfolio not found")
+		return
+	}
+
+	var (
+		cash      coinbase.Decimal
+		positions = []coinbase.SpotPosition{}
+	)
+
+	// Balances are not split between portfolios, every account belongs to every portfolio.
+	for _, a := range s.state.accounts {
+		available, _ := coinbase.ParseDecimal(a.AvailableBalance.Value)
+		onHold, _ := coinbase.ParseDecimal(a.Hold.Value)
+		total := available.Add(onHold)
+		totalCrypto := json.Number(total.String())
+		currency := *a.Currency
+		isCash := fiatCurrencies[currency]
+
+		if isCash {
+			cash = cash.Add(total)
+		}
+
+		positions = append(positions, coinbase.SpotPosition{
+			Asset:              coinbase.String(currency),
+			AccountUUID:        a.ID,
+			TotalBalanceCrypto: &totalCrypto,
+			CostBasis:          coinbase.Funds{Value: "0", Currency: currency},
+			IsCash:             coinbase.Bool(isCash),
+		})
+	}
+
+	usd := func(d coinbase.Decimal) coinbase.Funds {
+		return coinbase.Funds{Value: d.String(), Currency: "USD"}
+	}
+
+	var zero coinbase.Decimal
+
+	writeJSON(w, map[string]any{"breakdown": coinbase.PortfolioBreakdown{
+		Portfolio: p,
+		Balances: &coinbase.PortfolioBalances{
+			TotalBalance:               usd(cash),
+			TotalFuturesBalance:        usd(zero),
+			TotalCashEquivalentBalance: usd(cash),
+			TotalCryptoBalance:         usd(zero),
+			FuturesUnrealizedPNL:       usd(zero),
+			PerpUnrealizedPNL:          usd(zero),
+		},
+		SpotPositions: positions,
+		PerpPositions: []coinbase.PerpPosition{},
+	}})
+}
+
+func (s *Server) movePortfolioFunds(w http.ResponseWriter, c *call) {
+	var req coinbase.PortfolioMoveFundsOptions
+
+	if err := c.decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	if amount, err := req.Funds.Decimal(); err != nil || amount.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("funds.value", req.Funds.Value).Error())
+		return
+	}
+
+	for _, id := range []uuid.UUID{req.SourcePortfolioUUID, req.TargetPortfolioUUID} {
+		if s.state.portfolio(id.String()) == nil {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "portfolio not found")
+			return
+		}
+	}
+
+	if req.SourcePortfolioUUID == req.TargetPortfolioUUID {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "source and target portfolio must differ")
+		return
+	}
+
+	writeJSON(w, coinbase.PorfoliosMoveFundsResponse{
+		SourcePortfolioUUID: &req.SourcePortfolioUUID,
+		TargetPortfolioUUID: &req.TargetPortfolioUUID,
+	})
+}
+
+func (s *Server) allocatePortfolio(w http.ResponseWriter, c *call) {
+	var req coinbase.AllocatePortfolioOptions
+
+	if err := c.decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid request body")
+		return
+	}
+
+	p := s.state.portfolio(req.PortfolioUUID.String())
+	if p == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "portfolio not found")
+		return
+	}
+
+	if *p.Type != coinbase.PortfolioTypeINTX {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "funds can only be allocated to an INTX portfolio")
+		return
+	}
+
+	writeJSON(w, struct{}{})
+}
+
+func (st *state) portfolioNamed(name string) *coinbase.Portfolio {
+	for _, p := range st.portfolios {
+		if !*p.Deleted && p.Name != nil && *p.Name == name {
+			return p
+		}
+	}
+
+	return nil
+}
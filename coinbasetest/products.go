@@ -0,0 +1,232 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+const (
+	defaultMarketTradesLimit = 100
+	maxCandles               = 350
+)
+
+func (s *Server) listProducts(w http.ResponseWriter, c *call) {
+	ids := make(map[string]bool)
+	for _, id := range c.queryList("product_ids") {
+		ids[id] = true
+	}
+
+	productType := c.query("product_type")
+
+	products := []coinbase.Product{}
+
+	for _, p := range s.state.products {
+		if len(ids) > 0 && !ids[p.ID] {
+			continue
+		}
+
+		if productType != "" && (p.Type == nil || string(*p.Type) != productType) {
+			continue
+		}
+
+		products = append(products, *p)
+	}
+
+	if limit, err := strconv.Atoi(c.query("limit")); err == nil && limit > 0 && limit < len(products) {
+		products = products[:limit]
+	}
+
+	writeJSON(w, map[string]any{
+		"products":     products,
+		"num_products": len(products),
+	})
+}
+
+func (s *Server) getProduct(w http.ResponseWriter, c *call) {
+	p := s.state.product(c.params[0])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "product not found")
+		return
+	}
+
+	writeJSON(w, p)
+}
+
+func (s *Server) getProductBook(w http.ResponseWriter, c *call) {
+	id := c.query("product_id")
+
+	if s.state.product(id) == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "product not found")
+		return
+	}
+
+	book := s.state.book(id)
+
+	if limit, err := strconv.Atoi(c.query("limit")); err == nil && limit > 0 {
+		book.Bids = book.Bids[:min(limit, len(book.Bids))]
+		book.Asks = book.Asks[:min(limit, len(book.Asks))]
+	}
+
+	writeJSON(w, map[string]any{"pricebook": book})
+}
+
+func (s *Server) getBestBidAsk(w http.ResponseWriter, c *call) {
+	ids := c.queryList("product_ids")
+
+	if len(ids) == 0 {
+		for _, p := range s.state.products {
+			ids = append(ids, p.ID)
+		}
+	}
+
+	books := []coinbase.PriceBook{}
+
+	for _, id := range ids {
+		if s.state.product(id) == nil {
+			continue
+		}
+
+		book := s.state.book(id)
+		book.Bids = book.Bids[:min(1, len(book.Bids))]
+		book.Asks = book.Asks[:min(1, len(book.Asks))]
+
+		books = append(books, book)
+	}
+
+	writeJSON(w, coinbase.GetBestBidAskResponse{PriceBooks: books})
+}
+
+// book returns a copy of the order book of the product, which is empty if none was set.
+func (st *state) book(id string) coinbase.PriceBook {
+	book, ok := st.books[id]
+	if !ok {
+		now := st.now()
+
+		return coinbase.PriceBook{ProductID: id, Bids: []coinbase.BidAsk{}, Asks: []coinbase.BidAsk{}, Time: &now}
+	}
+
+	copied := *book
+	copied.Bids = append([]coinbase.BidAsk{}, book.Bids...)
+	copied.Asks = append([]coinbase.BidAsk{}, book.Asks...)
+
+	return copied
+}
+
+func (s *Server) getCandles(w http.ResponseWriter, c *call) {
+	id := c.params[0]
+
+	if s.state.product(id) == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "product not found")
+		return
+	}
+
+	granularity := coinbase.TimeGranularity(c.query("granularity"))
+	if granularity.Duration() == 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("granularity", string(granularity)).Error())
+		return
+	}
+
+	start, err := strconv.ParseInt(c.query("start"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("start", c.query("start")).Error())
+		return
+	}
+
+	end, err := strconv.ParseInt(c.query("end"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", errInvalid("end", c.query("end")).Error())
+		return
+	}
+
+	// Coinbase rejects ranges spanning more candles than it returns in one response.
+	if (end-start)/int64(granularity.Duration().Seconds()) >= maxCandles {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "number of candles requested should be less than 350")
+		return
+	}
+
+	candles := []coinbase.Candles{}
+
+	for _, candle := range s.state.candles[id] {
+		if t := candleStart(candle); t >= start && t <= end {
+			candles = append(candles, candle)
+		}
+	}
+
+	writeJSON(w, map[string]any{"candles": candles})
+}
+
+func candleStart(c coinbase.Candles) int64 {
+	if c.Start == nil {
+		return 0
+	}
+
+	t, _ := strconv.ParseInt(*c.Start, 10, 64)
+
+	return t
+}
+
+func (s *Server) getMarketTrades(w http.ResponseWriter, c *call) {
+	id := c.params[0]
+
+	if s.state.product(id) == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "product not found")
+		return
+	}
+
+	trades := append([]coinbase.Trade{}, s.state.trades[id]...)
+
+	// Most recent first.
+	sort.SliceStable(trades, func(i, j int) bool {
+		return tradeTime(trades[i]).After(tradeTime(trades[j]))
+	})
+
+	limit := defaultMarketTradesLimit
+	if l, err := strconv.Atoi(c.query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	trades = trades[:min(limit, len(trades))]
+
+	resp := coinbase.GetMarketTradesResponse{Trades: trades}
+
+	book := s.state.book(id)
+
+	if len(book.Bids) > 0 {
+		resp.BestBid = book.Bids[0].Price
+	}
+
+	if len(book.Asks) > 0 {
+		resp.BestAsk = book.Asks[0].Price
+	}
+
+	writeJSON(w, resp)
+}
+
+func tradeTime(t coinbase.Trade) time.Time {
+	if t.Time == nil {
+		return time.Time{}
+	}
+
+	return *t.Time
+}
+
+func (s *Server) getServerTime(w http.ResponseWriter, c *call) {
+	now := s.state.now()
+
+	writeJSON(w, coinbase.CoinbaseServerTime{
+		ISO:          coinbase.String(now.Format(time.RFC3339Nano)),
+		EpochSeconds: coinbase.String(strconv.FormatInt(now.Unix(), 10)),
+		EpochMillis:  coinbase.String(strconv.FormatInt(now.UnixMilli(), 10)),
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"net/http"
+	"strings"
+)
+
+type handler func(s *Server, w http.ResponseWriter, c *call)
+
+type route struct {
+	method  string
+	pattern []string // Segments of the path, "*" matches any single segment.
+	handler handler
+}
+
+// routes of the Advanced Trade REST API implemented by the server. More specific patterns come first.
+var routes = []route{
+	{http.MethodGet, path("/api/v3/brokerage/accounts"), (*Server).listAccounts},
+	{http.MethodGet, path("/api/v3/brokerage/accounts/*"), (*Server).getAccount},
+
+	{http.MethodGet, path("/api/v3/brokerage/products"), (*Server).listProducts},
+	{http.MethodGet, path("/api/v3/brokerage/products/*"), (*Server).getProduct},
+	{http.MethodGet, path("/api/v3/brokerage/products/*/candles"), (*Server).getCandles},
+	{http.MethodGet, path("/api/v3/brokerage/products/*/ticker"), (*Server).getMarketTrades},
+	{http.MethodGet, path("/api/v3/brokerage/product_book"), (*Server).getProductBook},
+	{http.MethodGet, path("/api/v3/brokerage/best_bid_ask"), (*Server).getBestBidAsk},
+
+	{http.MethodGet, path("/api/v3/brokerage/market/products"), (*Server).listProducts},
+	{http.MethodGet, path("/api/v3/brokerage/market/products/*"), (*Server).getProduct},
+	{http.MethodGet, path("/api/v3/brokerage/market/products/*/candles"), (*Server).getCandles},
+	{http.MethodGet, path("/api/v3/brokerage/market/products/*/ticker"), (*Server).getMarketTrades},
+	{http.MethodGet, path("/api/v3/brokerage/market/product_book"), (*Server).getProductBook},
+	{http.MethodGet, path("/api/v3/brokerage/time"), (*Server).getServerTime},
+
+	{http.MethodPost, path("/api/v3/brokerage/orders"), (*Server).createOrder},
+	{http.MethodPost, path("/api/v3/brokerage/orders/preview"), (*Server).previewOrder},
+	{http.MethodPost, path("/api/v3/brokerage/orders/edit"), (*Server).editOrder},
+	{http.MethodPost, path("/api/v3/brokerage/orders/edit_preview"), (*Server).editOrderPreview},
+	{http.MethodPost, path("/api/v3/brokerage/orders/batch_cancel"), (*Server).cancelOrders},
+	{http.MethodGet, path("/api/v3/brokerage/orders/historical/batch"), (*Server).listOrders},
+	{http.MethodGet, path("/api/v3/brokerage/orders/historical/fills"), (*Server).listFills},
+	{http.MethodGet, path("/api/v3/brokerage/orders/historical/*"), (*Server).getOrder},
+
+	{http.MethodGet, path("/api/v3/brokerage/portfolios"), (*Server).listPortfolios},
+	{http.MethodPost, path("/api/v3/brokerage/portfolios"), (*Server).createPortfolio},
+	{http.MethodPost, path("/api/v3/brokerage/portfolios/move_funds"), (*Server).movePortfolioFunds},
+	{http.MethodGet, path("/api/v3/brokerage/portfolios/*"), (*Server).getPortfolioBreakdown},
+	{http.MethodPut, path("/api/v3/brokerage/portfolios/*"), (*Server).editPortfolio},
+	{http.MethodDelete, path("/api/v3/brokerage/portfolios/*"), (*Server).deletePortfolio},
+	{http.MethodPost, path("/api/v3/brokerage/intx/allocate"), (*Server).allocatePortfolio},
+
+	{http.MethodGet, path("/api/v3/brokerage/cfm/balance_summary"), (*Server).getFuturesBalanceSummary},
+	{http.MethodGet, path("/api/v3/brokerage/cfm/positions"), (*Server).listFuturesPositions},
+	{http.MethodGet, path("/api/v3/brokerage/cfm/positions/*"), (*Server).getFuturesPosition},
+	{http.MethodGet, path("/api/v3/brokerage/cfm/sweeps"), (*Server).listFuturesSweeps},
+	{http.MethodPost, path("/api/v3/brokerage/cfm/sweeps/schedule"), (*Server).scheduleFuturesSweep},
+	{http.MethodDelete, path("/api/v3/brokerage/cfm/sweeps"), (*Server).cancelFuturesSweep},
+
+	{http.MethodGet, path("/api/v3/brokerage/transaction_summary"), (*Server).getTransactionSummary},
+
+	{http.MethodGet, path("/api/v3/brokerage/payment_methods"), (*Server).listPaymentMethods},
+	{http.MethodGet, path("/api/v3/brokerage/payment_methods/*"), (*Server).getPaymentMethod},
+}
+
+func path(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+// match returns the handler of the request and the path segments matched by wildcards.
+func match(method string, p string) (handler, []string) {
+	segments := path(p)
+
+	for _, rt := range routes {
+		if rt.method != method || len(rt.pattern) != len(segments) {
+			continue
+		}
+
+		var params []string
+
+		matched := true
+
+		for i, s := range rt.pattern {
+			if s == "*" {
+				params = append(params, segments[i])
+			} else if s != segments[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return rt.handler, params
+		}
+	}
+
+	return nil, nil
+}
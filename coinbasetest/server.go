@@ -0,0 +1,319 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+// Package coinbasetest provides an in-memory fake of the Coinbase Advanced Trade REST API for testing code
+// built on go-coinbase without touching production.
+//
+// The server keeps accounts, products, orders, portfolios and the other resources in memory, checks the
+// JWT or legacy signature of every private request against the configured keys and records every request
+// it receives so tests can assert on them.
+//
+//	srv := coinbasetest.NewServer(coinbasetest.WithLegacyKey("key", "secret"))
+//	defer srv.Close()
+//
+//	srv.AddProduct(coinbase.Product{ID: "BTC-USD", BaseIncrement: "0.00000001", QuoteIncrement: "0.01"})
+//	srv.SetBalance("USD", "1000")
+//
+//	client := coinbase.NewWithLegacy("key", "secret", coinbase.WithBaseURL(srv.URL()))
+//
+// # Matching
+//
+// Orders are matched deterministically against market data supplied by the test. A new order takes
+// liquidity from the levels of the book set with SetProductBook that it crosses, best price first, and
+// consumes them. What is left of a limit order rests on the book, market, immediate or cancel and fill or
+// kill orders are cancelled instead. Resting orders are filled as makers by the trades added with
+// AddTrades, in price-time priority and at their limit price. Trades also trigger stop orders once their
+// price reaches the stop price, and good till date orders expire when the clock moved by SetTime or
+// Advance passes their end time.
+//
+// Every fill is charged the maker or taker rate of the fee tier, see SetFeeTier, and settled against the
+// accounts. Open orders hold the funds they need, which are released once they are done.
+package coinbasetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// Request is a request received by the server.
+type Request struct {
+	Method string      // HTTP method of the request.
+	Path   string      // URL path of the request.
+	Query  url.Values  // Query parameters of the request.
+	Header http.Header // Headers of the request.
+	Body   []byte      // Body of the request.
+	APIKey string      // API key the request was authenticated with, empty for public endpoints or if no keys are configured.
+	Time   time.Time   // Time the request was received.
+}
+
+// Decode unmarshals the JSON body of the request into v.
+func (r Request) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a fake Coinbase Advanced Trade REST API. Create one with NewServer and point a client at it
+// with coinbase.WithBaseURL(srv.URL()).
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	cloud    map[string]cloudKey // Cloud API keys by name.
+	legacy   map[string]string   // Legacy API secrets by key.
+	keys     []string            // Names of the cloud and legacy keys in the order they were configured.
+	requests []Request
+	state    state
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// NewServer starts a fake Coinbase server. Private endpoints accept any request unless an API key is
+// configured with WithCloudKey or WithLegacyKey, in which case requests must be signed with one of them.
+// A default portfolio exists from the start.
+func NewServer(opts ...Option) *Server {
+	s := Server{
+		cloud:  make(map[string]cloudKey),
+		legacy: make(map[string]string),
+		state:  newState(),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&s)
+		}
+	}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return &s
+}
+
+// URL returns the base URL of the server, pass it to coinbase.WithBaseURL.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a client for the server, authenticated with the first API key configured, or an
+// unauthenticated client if there is none. Use ClientWithKey to pick another key.
+func (s *Server) Client(opts ...func(*coinbase.Client)) (*coinbase.Client, error) {
+	s.mu.Lock()
+	var name string
+	if len(s.keys) > 0 {
+		name = s.keys[0]
+	}
+	s.mu.Unlock()
+
+	if name == "" {
+		return coinbase.NewClient(apply(s.clientOptions(opts))), nil
+	}
+
+	return s.ClientWithKey(name, opts...)
+}
+
+// ClientWithKey returns a client for the server, authenticated with the configured cloud or legacy API key
+// of the name.
+func (s *Server) ClientWithKey(name string, opts ...func(*coinbase.Client)) (*coinbase.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.cloud[name]; ok {
+		return coinbase.NewWithCloud(name, key.secret, apply(s.clientOptions(opts)))
+	}
+
+	if secret, ok := s.legacy[name]; ok {
+		return coinbase.NewWithLegacy(name, secret, apply(s.clientOptions(opts))), nil
+	}
+
+	return nil, fmt.Errorf("coinbasetest: no API key '%s' configured", name)
+}
+
+// clientOptions points a client at the server before applying opts.
+func (s *Server) clientOptions(opts []func(*coinbase.Client)) []func(*coinbase.Client) {
+	return append([]func(*coinbase.Client){coinbase.WithBaseURL(s.URL())}, opts...)
+}
+
+// apply combines the client options into one.
+func apply(opts []func(*coinbase.Client)) func(*coinbase.Client) {
+	return func(c *coinbase.Client) {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(c)
+			}
+		}
+	}
+}
+
+// Requests returns every request received by the server, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// LastRequest returns the most recent request received by the server.
+func (s *Server) LastRequest() (Request, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) == 0 {
+		return Request{}, false
+	}
+
+	return s.requests[len(s.requests)-1], true
+}
+
+// ResetRequests forgets the requests received so far.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "failed to read request body")
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	}
+
+	if !isPublic(r.URL.Path) {
+		apiKey, err := s.authenticate(r, body)
+		if err != nil {
+			s.requests = append(s.requests, req)
+
+			// Coinbase answers unauthenticated requests with a plain text body.
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		req.APIKey = apiKey
+	}
+
+	s.requests = append(s.requests, req)
+
+	// Good till date orders expire as the real clock passes their end time too.
+	s.state.expire()
+
+	h, params := match(r.Method, r.URL.Path)
+	if h == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path))
+		return
+	}
+
+	h(s, w, &call{r: r, body: body, params: params})
+}
+
+// isPublic reports whether the path is one of the public endpoints, which require no authentication.
+func isPublic(path string) bool {
+	return strings.HasPrefix(path, "/api/v3/brokerage/market/") || path == "/api/v3/brokerage/time"
+}
+
+// call is a request being handled, along with the parameters captured from its path.
+type call struct {
+	r      *http.Request
+	body   []byte
+	params []string
+}
+
+func (c *call) decode(v any) error {
+	return json.Unmarshal(c.body, v)
+}
+
+func (c *call) query(name string) string {
+	return c.r.URL.Query().Get(name)
+}
+
+// queryList returns every value of the query parameter, both repeated and comma separated.
+func (c *call) queryList(name string) []string {
+	var values []string
+
+	for _, v := range c.r.URL.Query()[name] {
+		for _, s := range strings.Split(v, ",") {
+			if s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+
+	return values
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format used by Coinbase.
+func writeError(w http.ResponseWriter, status int, reason string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error":         reason,
+		"code":          grpcCode(status),
+		"message":       message,
+		"error_details": message,
+		"details":       []any{},
+	})
+}
+
+// grpcCode maps the HTTP status to the gRPC code Coinbase reports alongside it.
+func grpcCode(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return 3
+	case http.StatusNotFound:
+		return 5
+	case http.StatusForbidden:
+		return 7
+	case http.StatusTooManyRequests:
+		return 8
+	case http.StatusUnauthorized:
+		return 16
+	default:
+		return 2
+	}
+}
+
+// errInvalid reports an invalid request parameter.
+func errInvalid(name string, value string) error {
+	return fmt.Errorf("invalid %s '%s'", name, value)
+}
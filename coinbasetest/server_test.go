@@ -0,0 +1,264 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+func newCloudKey(t *testing.T) (string, string) {
+	t.Helper()
+
+	name, secret, err := coinbasetest.NewCloudKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return name, secret
+}
+
+// keyOfLastRequest returns the API key the last request received by the server was authenticated with.
+func keyOfLastRequest(t *testing.T, srv *coinbasetest.Server) string {
+	t.Helper()
+
+	r, ok := srv.LastRequest()
+	if !ok {
+		t.Fatal("no request received")
+	}
+
+	return r.APIKey
+}
+
+func TestServerClient(t *testing.T) {
+	cloud1, secret1 := newCloudKey(t)
+	cloud2, secret2 := newCloudKey(t)
+
+	tests := []struct {
+		name string
+		opts []coinbasetest.Option
+		key  string // Name of the key ClientWithKey picks, Client is used if empty.
+		want string // Key the requests are authenticated with.
+		err  bool
+	}{
+		{name: "no keys"},
+		{
+			name: "first cloud key",
+			opts: []coinbasetest.Option{coinbasetest.WithCloudKey(cloud1, secret1), coinbasetest.WithCloudKey(cloud2, secret2)},
+			want: cloud1,
+		},
+		{
+			name: "first key of any kind",
+			opts: []coinbasetest.Option{coinbasetest.WithLegacyKey("legacy", "secret"), coinbasetest.WithCloudKey(cloud1, secret1)},
+			want: "legacy",
+		},
+		{
+			name: "key configured again keeps its place",
+			opts: []coinbasetest.Option{coinbasetest.WithCloudKey(cloud1, secret1), coinbasetest.WithCloudKey(cloud2, secret2), coinbasetest.WithCloudKey(cloud1, secret1)},
+			want: cloud1,
+		},
+		{
+			name: "cloud key by name",
+			opts: []coinbasetest.Option{coinbasetest.WithCloudKey(cloud1, secret1), coinbasetest.WithCloudKey(cloud2, secret2)},
+			key:  cloud2,
+			want: cloud2,
+		},
+		{
+			name: "legacy key by name",
+			opts: []coinbasetest.Option{coinbasetest.WithCloudKey(cloud1, secret1), coinbasetest.WithLegacyKey("legacy", "secret")},
+			key:  "legacy",
+			want: "legacy",
+		},
+		{
+			name: "unknown key",
+			opts: []coinbasetest.Option{coinbasetest.WithCloudKey(cloud1, secret1)},
+			key:  "unknown",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := coinbasetest.NewServer(tt.opts...)
+			defer srv.Close()
+
+			// Map iteration order is random, picking a key has to give the same answer every time.
+			for i := 0; i < 20; i++ {
+				var (
+					client *coinbase.Client
+					err    error
+				)
+
+				if tt.key == "" {
+					client, err = srv.Client()
+				} else {
+					client, err = srv.ClientWithKey(tt.key)
+				}
+
+				if tt.err {
+					if err == nil {
+						t.Fatal("expected an error")
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("failed to create client: %v", err)
+				}
+
+				_, err = client.Accounts.List(context.Background(), nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if key := keyOfLastRequest(t, srv); key != tt.want {
+					t.Fatalf("authenticated with '%s', want '%s'", key, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	cloud, secret := newCloudKey(t)
+	other, otherSecret := newCloudKey(t)
+
+	tests := []struct {
+		name   string
+		client func(srv *coinbasetest.Server) (*coinbase.Client, error)
+		err    bool
+	}{
+		{
+			name: "configured cloud key",
+			client: func(srv *coinbasetest.Server) (*coinbase.Client, error) {
+				return coinbase.NewWithCloud(cloud, secret, coinbase.WithBaseURL(srv.URL()))
+			},
+		},
+		{
+			name: "configured legacy key",
+			client: func(srv *coinbasetest.Server) (*coinbase.Client, error) {
+				return coinbase.NewWithLegacy("legacy", "secret", coinbase.WithBaseURL(srv.URL())), nil
+			},
+		},
+		{
+			name: "unknown cloud key",
+			client: func(srv *coinbasetest.Server) (*coinbase.Client, error) {
+				return coinbase.NewWithCloud(other, otherSecret, coinbase.WithBaseURL(srv.URL()))
+			},
+			err: true,
+		},
+		{
+			name: "cloud key signed with another secret",
+			client: func(srv *coinbasetest.Server) (*coinbase.Client, error) {
+				return coinbase.NewWithCloud(cloud, otherSecret, coinbase.WithBaseURL(srv.URL()))
+			},
+			err: true,
+		},
+		{
+			name: "wrong legacy secret",
+			client: func(srv *coinbasetest.Server) (*coinbase.Client, error) {
+				return coinbase.NewWithLegacy("legacy", "wrong", coinbase.WithBaseURL(srv.URL())), nil
+			},
+			err: true,
+		},
+		{
+			name: "unauthenticated",
+			client: func(srv *coinbasetest.Server) (*coinbase.Client, error) {
+				return coinbase.NewClient(coinbase.WithBaseURL(srv.URL())), nil
+			},
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := coinbasetest.NewServer(coinbasetest.WithCloudKey(cloud, secret), coinbasetest.WithLegacyKey("legacy", "secret"))
+			defer srv.Close()
+
+			client, err := tt.client(srv)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			_, err = client.Accounts.List(context.Background(), nil)
+
+			if !tt.err {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, coinbase.ErrUnauthorized) {
+				t.Fatalf("error is %v, want %v", err, coinbase.ErrUnauthorized)
+			}
+		})
+	}
+}
+
+func TestServerMatching(t *testing.T) {
+	srv := coinbasetest.NewServer()
+	defer srv.Close()
+
+	srv.AddProduct(coinbase.Product{ID: "BTC-USD", BaseIncrement: "0.00000001", QuoteIncrement: "0.01"})
+	srv.SetFeeTier(coinbase.FeeTier{TakerFeeRate: coinbase.String("0.01"), MakerFeeRate: coinbase.String("0")})
+	srv.SetBalance("USD", "1000")
+	srv.SetProductBook(coinbase.PriceBook{
+		ProductID: "BTC-USD",
+		Asks: []coinbase.BidAsk{
+			{Price: coinbase.String("100"), Size: coinbase.String("1")},
+			{Price: coinbase.String("200"), Size: coinbase.String("1")},
+		},
+	})
+
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	side := coinbase.SideBuy
+
+	// Takes both levels of the book, the cheapest first.
+	resp, err := client.Orders.Create(context.Background(), coinbase.CreateOrderOptions{
+		ClientOrderID:      "buy",
+		ProductID:          "BTC-USD",
+		Side:               &side,
+		OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("2"), "200", false),
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("failed to create order: %v", err)
+	}
+
+	order, ok := srv.Order(resp.SuccessResponse.OrderID)
+	if !ok {
+		t.Fatal("order not found")
+	}
+
+	if *order.Status != coinbase.OrderStatusFilled || *order.FilledSize != "2" || order.AverageFilledPrice != "150" {
+		t.Fatalf("order is %s with %s filled at %s, want FILLED with 2 filled at 150", *order.Status, *order.FilledSize, order.AverageFilledPrice)
+	}
+
+	if len(srv.Fills()) != 2 {
+		t.Fatalf("%d fills, want 2", len(srv.Fills()))
+	}
+
+	// 300 for the bitcoin and 3 of taker fees.
+	if usd, hold := srv.Balance("USD"); usd != "697" || hold != "0" {
+		t.Fatalf("USD balance is %s with %s on hold, want 697 with 0 on hold", usd, hold)
+	}
+
+	if btc, _ := srv.Balance("BTC"); btc != "2" {
+		t.Fatalf("BTC balance is %s, want 2", btc)
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbasetest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// fiatCurrencies are the currencies whose accounts are fiat accounts, every other account holds crypto.
+var fiatCurrencies = map[string]bool{"USD": true, "EUR": true, "GBP": true, "CAD": true, "SGD": true}
+
+// state holds the resources of the server, guarded by the server's lock.
+type state struct {
+	clock              time.Time // Fake time set by SetTime, the real time is used while it is zero.
+	accounts           []*coinbase.Account
+	products           []*coinbase.Product
+	books              map[string]*coinbase.PriceBook
+	candles            map[string][]coinbase.Candles
+	trades             map[string][]coinbase.Trade
+	orders             []*coinbase.Order
+	holds              map[string]*hold
+	fills              []coinbase.Fill
+	portfolios         []*coinbase.Portfolio
+	paymentMethods     []coinbase.PaymentMethod
+	futuresPositions   []coinbase.FuturesPosition
+	futuresSweeps      []coinbase.FuturesSweep
+	balanceSummary     coinbase.BalanceSummary
+	transactionSummary coinbase.GetTransactionsSummaryResponse
+}
+
+func newState() state {
+	id := uuid.New()
+
+	return state{
+		books:   make(map[string]*coinbase.PriceBook),
+		holds:   make(map[string]*hold),
+		candles: make(map[string][]coinbase.Candles),
+		trades:  make(map[string][]coinbase.Trade),
+		portfolios: []*coinbase.Portfolio{{
+			Name:    coinbase.String("Default"),
+			UUID:    &id,
+			Type:    portfolioType(coinbase.PortfolioTypeDefault),
+			Deleted: coinbase.Bool(false),
+		}},
+		transactionSummary: coinbase.GetTransactionsSummaryResponse{
+			FeeTier: coinbase.FeeTier{
+				PricingTier:  coinbase.String("Advanced 1"),
+				USDFrom:      coinbase.String("0"),
+				USDTo:        coinbase.String("10000"),
+				TakerFeeRate: coinbase.String("0.006"),
+				MakerFeeRate: coinbase.String("0.004"),
+			},
+		},
+	}
+}
+
+func portfolioType(t coinbase.PortfolioType) *coinbase.PortfolioType {
+	return &t
+}
+
+// AddProduct adds a product, or replaces the product with the same ID. Empty increments and limits are
+// given permissive defaults so orders can be placed without configuring every field.
+func (s *Server) AddProduct(product coinbase.Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defaults := map[*string]string{
+		&product.BaseIncrement:    "0.00000001",
+		&product.QuoteIncrement:   "0.01",
+		&product.BaseMinimimSize:  "0.00000001",
+		&product.BaseMaximumSize:  "1000000",
+		&product.QuoteMinimumSize: "0.01",
+		&product.QuoteMaximumSize: "100000000",
+		&product.Status:           "online",
+	}
+
+	for field, value := range defaults {
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	if product.Type == nil {
+		t := coinbase.ProductTypeSpot
+		product.Type = &t
+	}
+
+	for i, p := range s.state.products {
+		if p.ID == product.ID {
+			s.state.products[i] = &product
+			return
+		}
+	}
+
+	s.state.products = append(s.state.products, &product)
+}
+
+// Product returns the product with the ID.
+func (s *Server) Product(id string) (coinbase.Product, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.state.product(id)
+	if p == nil {
+		return coinbase.Product{}, false
+	}
+
+	return *p, true
+}
+
+func (st *state) product(id string) *coinbase.Product {
+	for _, p := range st.products {
+		if p.ID == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// SetBalance sets the available balance of the account holding the currency, creating the account if needed.
+func (s *Server) SetBalance(currency string, available string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.account(currency).AvailableBalance.Value = available
+}
+
+// Balance returns the available balance and the amount on hold of the account holding the currency.
+func (s *Server) Balance(currency string) (available string, hold string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.state.account(currency)
+
+	return a.AvailableBalance.Value, a.Hold.Value
+}
+
+// Accounts returns every account, in the order they were created.
+func (s *Server) Accounts() []coinbase.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]coinbase.Account, len(s.state.accounts))
+	for i, a := range s.state.accounts {
+		accounts[i] = *a
+	}
+
+	return accounts
+}
+
+// account returns the account holding the currency, creating an empty one if there is none.
+func (st *state) account(currency string) *coinbase.Account {
+	for _, a := range st.accounts {
+		if *a.Currency == currency {
+			return a
+		}
+	}
+
+	id := uuid.New()
+	now := st.now()
+
+	t := coinbase.AccountTypeCrypto
+	if fiatCurrencies[currency] {
+		t = coinbase.AccountTypeFiat
+	}
+
+	a := coinbase.Account{
+		ID:               &id,
+		Name:             coinbase.String(currency + " Wallet"),
+		Currency:         coinbase.String(currency),
+		AvailableBalance: coinbase.AvailableBalance{Value: "0", Currency: currency},
+		Default:          coinbase.Bool(true),
+		Active:           coinbase.Bool(true),
+		CreatedAt:        &now,
+		UpdatedAt:        &now,
+		Type:             &t,
+		Ready:            coinbase.Bool(true),
+		Hold:             coinbase.Hold{Value: "0", Currency: currency},
+	}
+
+	st.accounts = append(st.accounts, &a)
+
+	return &a
+}
+
+// SetProductBook sets the order book returned for the book's product.
+func (s *Server) SetProductBook(book coinbase.PriceBook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if book.Time == nil {
+		now := s.state.now()
+		book.Time = &now
+	}
+
+	s.state.books[book.ProductID] = &book
+}
+
+// AddCandles adds candles of the product. Candles with the same start replace the existing ones.
+func (s *Server) AddCandles(productID string, candles ...coinbase.Candles) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byStart := make(map[string]coinbase.Candles)
+
+	for _, c := range append(s.state.candles[productID], candles...) {
+		if c.Start != nil {
+			byStart[*c.Start] = c
+		}
+	}
+
+	merged := make([]coinbase.Candles, 0, len(byStart))
+	for _, c := range byStart {
+		merged = append(merged, c)
+	}
+
+	// Coinbase returns the most recent candle first.
+	sort.Slice(merged, func(i, j int) bool {
+		return candleStart(merged[i]) > candleStart(merged[j])
+	})
+
+	s.state.candles[productID] = merged
+}
+
+// AddTrades adds market trades of the product, returned by the ticker endpoints most recent first.
+//
+// Every trade is also matched against the open orders of the product, see the package documentation.
+func (s *Server) AddTrades(productID string, trades ...coinbase.Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range trades {
+		if t.ProductID == nil {
+			t.ProductID = coinbase.String(productID)
+		}
+
+		if t.Time == nil {
+			now := s.state.now()
+			t.Time = &now
+		}
+
+		s.state.trades[productID] = append(s.state.trades[productID], t)
+		s.state.trade(productID, t)
+	}
+}
+
+// AddOrder adds an existing order, e.g. one placed before the test started.
+func (s *Server) AddOrder(order coinbase.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if order.ID == "" {
+		order.ID = uuid.NewString()
+	}
+
+	if order.CreatedTime.IsZero() {
+		order.CreatedTime = s.state.now()
+	}
+
+	s.state.orders = append(s.state.orders, &order)
+}
+
+// Orders returns every order, in the order they were placed.
+func (s *Server) Orders() []coinbase.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]coinbase.Order, len(s.state.orders))
+	for i, o := range s.state.orders {
+		orders[i] = *o
+	}
+
+	return orders
+}
+
+// Order returns the order with the ID.
+func (s *Server) Order(id string) (coinbase.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.state.order(id)
+	if o == nil {
+		return coinbase.Order{}, false
+	}
+
+	return *o, true
+}
+
+func (st *state) order(id string) *coinbase.Order {
+	for _, o := range st.orders {
+		if o.ID == id {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// AddFill adds a fill, returned by the fills endpoint most recent first.
+func (s *Server) AddFill(fill coinbase.Fill) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.fills = append(s.state.fills, fill)
+}
+
+// Fills returns every fill, oldest first.
+func (s *Server) Fills() []coinbase.Fill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]coinbase.Fill(nil), s.state.fills...)
+}
+
+// AddPortfolio adds a portfolio, a UUID is generated if it has none.
+func (s *Server) AddPortfolio(portfolio coinbase.Portfolio) coinbase.Portfolio {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if portfolio.UUID == nil {
+		id := uuid.New()
+		portfolio.UUID = &id
+	}
+
+	if portfolio.Type == nil {
+		portfolio.Type = portfolioType(coinbase.PortfolioTypeConsumer)
+	}
+
+	if portfolio.Deleted == nil {
+		portfolio.Deleted = coinbase.Bool(false)
+	}
+
+	s.state.portfolios = append(s.state.portfolios, &portfolio)
+
+	return portfolio
+}
+
+// Portfolios returns every portfolio that was not deleted, the default portfolio first.
+func (s *Server) Portfolios() []coinbase.Portfolio {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var portfolios []coinbase.Portfolio
+
+	for _, p := range s.state.portfolios {
+		if !*p.Deleted {
+			portfolios = append(portfolios, *p)
+		}
+	}
+
+	return portfolios
+}
+
+func (st *state) portfolio(id string) *coinbase.Portfolio {
+	for _, p := range st.portfolios {
+		if p.UUID.String() == id && !*p.Deleted {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// AddPaymentMethod adds a payment method, an ID is generated if it has none.
+func (s *Server) AddPaymentMethod(method coinbase.PaymentMethod) coinbase.PaymentMethod {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if method.ID == "" {
+		method.ID = uuid.NewString()
+	}
+
+	s.state.paymentMethods = append(s.state.paymentMethods, method)
+
+	return method
+}
+
+// AddFuturesPosition adds a futures position, or replaces the position in the same product.
+func (s *Server) AddFuturesPosition(position coinbase.FuturesPosition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, p := range s.state.futuresPositions {
+		if p.ProductID != nil && position.ProductID != nil && *p.ProductID == *position.ProductID {
+			s.state.futuresPositions[i] = position
+			return
+		}
+	}
+
+	s.state.futuresPositions = append(s.state.futuresPositions, position)
+}
+
+// FuturesSweeps returns the futures sweeps that were scheduled and not cancelled.
+func (s *Server) FuturesSweeps() []coinbase.FuturesSweep {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]coinbase.FuturesSweep(nil), s.state.futuresSweeps...)
+}
+
+// SetFuturesBalanceSummary sets the balance summary of the futures account.
+func (s *Server) SetFuturesBalanceSummary(summary coinbase.BalanceSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.balanceSummary = summary
+}
+
+// SetTransactionSummary sets the fee tier and volumes returned by the transaction summary endpoint.
+func (s *Server) SetTransactionSummary(summary coinbase.GetTransactionsSummaryResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.transactionSummary = summary
+}
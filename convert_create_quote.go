@@ -0,0 +1,46 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type TradeIncentiveMetadata struct {
+	UserIncentiveID *string `json:"user_incentive_id,omitempty"` // The user incentive ID.
+	CodeValue       *string `json:"code_val,omitempty"`          // A promo code for waiving fees.
+}
+
+type CreateConvertQuoteOptions struct {
+	FromAccount            string                  `json:"from_account"`                       // The currency of the account to convert from (e.g. USD).
+	ToAccount              string                  `json:"to_account"`                         // The currency of the account to convert to (e.g. USDC).
+	Amount                 string                  `json:"amount"`                             // The amount to be converted (in the currency specified in FromAccount).
+	TradeIncentiveMetadata *TradeIncentiveMetadata `json:"trade_incentive_metadata,omitempty"` // Optional incentive information for the trade.
+}
+
+// CreateQuote creates a convert quote with a specified source currency, target currency, and amount.
+// The returned trade must be committed with Commit before it expires in order for the conversion to take place.
+// https://docs.cdp.coinbase.com/advanced-trade/reference/retailbrokerageapi_createconvertquote/
+func (s *ConvertsService) CreateQuote(ctx context.Context, options CreateConvertQuoteOptions) (*ConvertTrade, error) {
+	b, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CreateConvertQuoteOptions to JSON: %w", err)
+	}
+
+	var resp convertTradeResponse
+
+	err = s.client.post(ctx, s.client.baseURL+"/api/v3/brokerage/convert/quote", bytes.NewReader(b), &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create convert quote from '%s' to '%s': %w", options.FromAccount, options.ToAccount, err)
+	}
+
+	return &resp.Trade, nil
+}
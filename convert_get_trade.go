@@ -0,0 +1,32 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"fmt"
+)
+
+type GetConvertTradeOptions struct {
+	FromAccount string `url:"from_account"` // The currency of the account to convert from (e.g. USD).
+	ToAccount   string `url:"to_account"`   // The currency of the account to convert to (e.g. USDC).
+}
+
+// GetTrade gets information about a convert trade with a specified trade ID, source currency, and target currency.
+// https://docs.cdp.coinbase.com/advanced-trade/reference/retailbrokerageapi_getconverttrade/
+func (s *ConvertsService) GetTrade(ctx context.Context, tradeID string, options GetConvertTradeOptions) (*ConvertTrade, error) {
+	u := fmt.Sprintf("%s/api/v3/brokerage/convert/trade/%s", s.client.baseURL, tradeID)
+
+	var resp convertTradeResponse
+	err := s.client.get(ctx, u, &options, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch convert trade '%s': %w", tradeID, err)
+	}
+
+	return &resp.Trade, nil
+}
@@ -7,4 +7,101 @@
 
 package coinbase
 
+// Interface for interacting with the Converts APIs.
+// Converts allow you to exchange one currency for another, for example USD to USDC, at a quoted rate.
 type ConvertsService service
+
+type ConvertTradeStatus string
+
+const (
+	ConvertTradeStatusUnspecified ConvertTradeStatus = "TRADE_STATUS_UNSPECIFIED"
+	ConvertTradeStatusCreated     ConvertTradeStatus = "TRADE_STATUS_CREATED"
+	ConvertTradeStatusStarted     ConvertTradeStatus = "TRADE_STATUS_STARTED"
+	ConvertTradeStatusCompleted   ConvertTradeStatus = "TRADE_STATUS_COMPLETED"
+	ConvertTradeStatusCanceled    ConvertTradeStatus = "TRADE_STATUS_CANCELED"
+)
+
+type ConvertFee struct {
+	Title       *string `json:"title"`       // Title of the fee.
+	Description *string `json:"description"` // Description of the fee.
+	Amount      Funds   `json:"amount"`      // Amount of the fee.
+	Label       *string `json:"label"`       // Label of the fee.
+}
+
+type ConvertUnitPrice struct {
+	TargetToFiat   *ScaledAmount `json:"target_to_fiat"`   // Price of the target currency, denominated in fiat.
+	TargetToSource *ScaledAmount `json:"target_to_source"` // Price of the target currency, denominated in the source currency.
+	SourceToFiat   *ScaledAmount `json:"source_to_fiat"`   // Price of the source currency, denominated in fiat.
+}
+
+// Monetary amount along with the number of decimal places it is presented with.
+type ScaledAmount struct {
+	Amount Funds  `json:"amount"` // Represents a monetary amount.
+	Scale  *int32 `json:"scale"`  // Number of decimal places the amount is presented with.
+}
+
+type ConvertUserWarning struct {
+	ID   *string `json:"id"`
+	Link *struct {
+		Text *string `json:"text"`
+		URL  *string `json:"url"`
+	} `json:"link"`
+	Context *struct {
+		Details  []string `json:"details"`
+		Title    *string  `json:"title"`
+		LinkText *string  `json:"link_text"`
+	} `json:"context"`
+	Code    *string `json:"code"`
+	Message *string `json:"message"`
+}
+
+type ConvertCancellationReason struct {
+	Message   *string `json:"message"`
+	Code      *string `json:"code"`
+	ErrorCode *string `json:"error_code"`
+	ErrorCTA  *string `json:"error_cta"`
+}
+
+// Source or target of a convert trade.
+type ConvertAccount struct {
+	Type          *string `json:"type"`    // Type of the account, i.e. 'LEDGER_ACCOUNT'.
+	Network       *string `json:"network"` // Network the funds reside on.
+	LedgerAccount *struct {
+		AccountID *string `json:"account_id"`
+		Currency  *string `json:"currency"`
+		Owner     *struct {
+			ID       *string `json:"id"`
+			UUID     *string `json:"uuid"`
+			UserUUID *string `json:"user_uuid"`
+			Type     *string `json:"type"`
+		} `json:"owner"`
+	} `json:"ledger_account"`
+}
+
+type ConvertTrade struct {
+	ID                 *string                    `json:"id"`                    // The trade ID of the convert trade.
+	Status             *ConvertTradeStatus        `json:"status"`                // Status of the convert trade.
+	UserEnteredAmount  *Funds                     `json:"user_entered_amount"`   // Amount entered by the user.
+	Amount             *Funds                     `json:"amount"`                // Amount of the source currency that will be converted.
+	Subtotal           *Funds                     `json:"subtotal"`              // Amount of the source currency before fees.
+	Total              *Funds                     `json:"total"`                 // Total amount of the source currency, including fees.
+	Fees               []ConvertFee               `json:"fees"`                  // Breakdown of the fees charged for the trade.
+	TotalFee           *ConvertFee                `json:"total_fee"`             // Sum of all fees charged for the trade.
+	TotalFeeWithoutTax *ConvertFee                `json:"total_fee_without_tax"` // Sum of all fees, excluding taxes.
+	Source             *ConvertAccount            `json:"source"`                // Account the funds are converted from.
+	Target             *ConvertAccount            `json:"target"`                // Account the funds are converted to.
+	UnitPrice          *ConvertUnitPrice          `json:"unit_price"`            // Prices used to calculate the trade.
+	UserWarnings       []ConvertUserWarning       `json:"user_warnings"`         // Warnings the user should be made aware of before committing the trade.
+	UserReference      *string                    `json:"user_reference"`        // Reference the user can use to identify the trade.
+	SourceCurrency     *string                    `json:"source_currency"`       // Currency symbol of the source account.
+	TargetCurrency     *string                    `json:"target_currency"`       // Currency symbol of the target account.
+	SourceID           *string                    `json:"source_id"`             // Account UUID of the source account.
+	TargetID           *string                    `json:"target_id"`             // Account UUID of the target account.
+	CancellationReason *ConvertCancellationReason `json:"cancellation_reason"`   // Reason the trade was canceled, if it was.
+	ExchangeRate       *Funds                     `json:"exchange_rate"`         // Rate the source currency is exchanged at.
+	FiatDenotedTotal   *Funds                     `json:"fiat_denoted_total"`    // Total amount of the trade, denominated in fiat.
+}
+
+type convertTradeResponse struct {
+	Trade ConvertTrade `json:"trade"`
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+const convertTradeJSON = `{"trade":{"id":"trade-1","status":"TRADE_STATUS_CREATED","amount":{"value":"100","currency":"USD"},"total_fee":{"title":"Fee","amount":{"value":"0.5","currency":"USD"}},"source_currency":"USD","target_currency":"USDC"}}`
+
+func TestConverts(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(*coinbase.Client) (*coinbase.ConvertTrade, error)
+		want recordedRequest
+	}{
+		{
+			name: "create quote",
+			call: func(c *coinbase.Client) (*coinbase.ConvertTrade, error) {
+				return c.Converts.CreateQuote(context.Background(), coinbase.CreateConvertQuoteOptions{FromAccount: "USD", ToAccount: "USDC", Amount: "100"})
+			},
+			want: recordedRequest{Method: http.MethodPost, Path: "/api/v3/brokerage/convert/quote", Body: `{"from_account":"USD","to_account":"USDC","amount":"100"}`},
+		},
+		{
+			name: "create quote with incentive",
+			call: func(c *coinbase.Client) (*coinbase.ConvertTrade, error) {
+				return c.Converts.CreateQuote(context.Background(), coinbase.CreateConvertQuoteOptions{
+					FromAccount:            "USD",
+					ToAccount:              "USDC",
+					Amount:                 "100",
+					TradeIncentiveMetadata: &coinbase.TradeIncentiveMetadata{CodeValue: coinbase.String("PROMO")},
+				})
+			},
+			want: recordedRequest{Method: http.MethodPost, Path: "/api/v3/brokerage/convert/quote", Body: `{"from_account":"USD","to_account":"USDC","amount":"100","trade_incentive_metadata":{"code_val":"PROMO"}}`},
+		},
+		{
+			name: "commit",
+			call: func(c *coinbase.Client) (*coinbase.ConvertTrade, error) {
+				return c.Converts.Commit(context.Background(), "trade-1", coinbase.CommitConvertTradeOptions{FromAccount: "USD", ToAccount: "USDC"})
+			},
+			want: recordedRequest{Method: http.MethodPost, Path: "/api/v3/brokerage/convert/trade/trade-1", Body: `{"from_account":"USD","to_account":"USDC"}`},
+		},
+		{
+			name: "get trade",
+			call: func(c *coinbase.Client) (*coinbase.ConvertTrade, error) {
+				return c.Converts.GetTrade(context.Background(), "trade-1", coinbase.GetConvertTradeOptions{FromAccount: "USD", ToAccount: "USDC"})
+			},
+			want: recordedRequest{Method: http.MethodGet, Path: "/api/v3/brokerage/convert/trade/trade-1", Query: "from_account=USD&to_account=USDC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newAPIServer(t, http.StatusOK, convertTradeJSON)
+
+			trade, err := tt.call(srv.client())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := srv.last(t); got != tt.want {
+				t.Fatalf("request %+v, want %+v", got, tt.want)
+			}
+
+			if *trade.ID != "trade-1" || *trade.Status != coinbase.ConvertTradeStatusCreated || trade.TotalFee.Amount.Value != "0.5" {
+				t.Fatalf("unexpected trade %+v", trade)
+			}
+		})
+	}
+}
+
+func TestConvertsError(t *testing.T) {
+	srv := newAPIServer(t, http.StatusBadRequest, `{"error":"INVALID_ARGUMENT","message":"amount is too small"}`)
+
+	_, err := srv.client().Converts.CreateQuote(context.Background(), coinbase.CreateConvertQuoteOptions{FromAccount: "USD", ToAccount: "USDC", Amount: "0"})
+	if !errors.Is(err, coinbase.ErrInvalidArgument) {
+		t.Fatalf("error %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Largest exponent accepted when parsing, protects against inputs such as '1e1000000000'.
+const maxDecimalExponent = 1000
+
+// RoundingMode determines how a Decimal is rounded when digits are dropped.
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // Round to nearest, ties away from zero.
+	RoundHalfEven                     // Round to nearest, ties to the even neighbour (banker's rounding).
+	RoundDown                         // Round towards zero, i.e. truncate.
+	RoundUp                           // Round away from zero.
+	RoundFloor                        // Round towards negative infinity.
+	RoundCeiling                      // Round towards positive infinity.
+)
+
+// Decimal is an arbitrary-precision decimal number, used for the prices, sizes and balances that
+// Coinbase represents as strings. Arithmetic is exact apart from Div, which rounds to the requested
+// number of places. The zero value is 0.
+//
+// A Decimal remembers the number of digits after the decimal point it was parsed with, so values
+// received from Coinbase are marshalled back in the same format, i.e. '0.00100000' remains '0.00100000'.
+type Decimal struct {
+	value *big.Int // Unscaled value, nil is zero.
+	scale int32    // Number of digits after the decimal point.
+}
+
+// ParseDecimal parses a number in decimal or scientific notation, i.e. '-12.345' or '1.5e-8'.
+func ParseDecimal(s string) (Decimal, error) {
+	original := s
+
+	mantissa, exponent := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+
+		exp, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("invalid decimal '%s'", original)
+		}
+
+		exponent = exp
+	}
+
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+
+	digits := whole + fraction
+	if strings.TrimLeft(digits, "+-") == "" || len(fraction) > maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", original)
+	}
+
+	// big.Int accepts underscores and prefixes such as '0x' when the base is zero, the base is fixed to avoid both.
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok || strings.ContainsAny(fraction, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", original)
+	}
+
+	scale := int64(len(fraction)) - exponent
+	if scale < 0 {
+		value.Mul(value, pow10(int32(-scale)))
+		scale = 0
+	}
+
+	return Decimal{value: value, scale: int32(scale)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if the string is not a valid decimal.
+// It is intended for constants, i.e. coinbase.MustParseDecimal("0.01").
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// NewDecimal returns the decimal value * 10^-scale, i.e. NewDecimal(12345, 2) is 123.45.
+func NewDecimal(value int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{value: new(big.Int).Mul(big.NewInt(value), pow10(-scale))}
+	}
+
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+// NewDecimalFromInt returns the integer as a decimal.
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{value: big.NewInt(i)}
+}
+
+// NewDecimalFromRat converts the rational to a decimal, rounded to the number of places.
+func NewDecimalFromRat(r *big.Rat, places int32, mode RoundingMode) Decimal {
+	return roundRat(r, places, mode)
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) unscaled() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+
+	return d.value
+}
+
+// rescale returns the unscaled value at a larger scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.unscaled()
+	}
+
+	return new(big.Int).Mul(d.unscaled(), pow10(scale-d.scale))
+}
+
+// align returns the unscaled values of both decimals at a common scale.
+func align(a Decimal, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{value: new(big.Int).Add(a, b), scale: scale}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{value: new(big.Int).Sub(a, b), scale: scale}
+}
+
+// Mul returns d * o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.unscaled(), o.unscaled()), scale: d.scale + o.scale}
+}
+
+// Div returns d / o rounded half up to the number of places. Like math/big it panics on division by zero.
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	return d.DivWithMode(o, places, RoundHalfUp)
+}
+
+// DivWithMode returns d / o rounded to the number of places using the rounding mode.
+// Like math/big it panics on division by zero.
+func (d Decimal) DivWithMode(o Decimal, places int32, mode RoundingMode) Decimal {
+	if o.IsZero() {
+		panic("coinbase: division of decimal by zero")
+	}
+
+	return roundRat(new(big.Rat).Quo(d.Rat(), o.Rat()), places, mode)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.unscaled()), scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.unscaled()), scale: d.scale}
+}
+
+// Sign returns -1 if d < 0, 0 if d == 0 and +1 if d > 0.
+func (d Decimal) Sign() int {
+	return d.unscaled().Sign()
+}
+
+// IsZero reports whether d is zero.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp returns -1 if d < o, 0 if d == o and +1 if d > o. The scale is ignored, 1.0 and 1.00 are equal.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o represent the same number.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// LessThan reports whether d < o.
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+// GreaterThan reports whether d > o.
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Round rounds half away from zero to the number of places. Decimals that already have fewer
+// digits after the decimal point are returned unchanged.
+func (d Decimal) Round(places int32) Decimal {
+	return d.RoundWithMode(places, RoundHalfUp)
+}
+
+// Truncate drops the digits beyond the number of places, rounding towards zero.
+func (d Decimal) Truncate(places int32) Decimal {
+	return d.RoundWithMode(places, RoundDown)
+}
+
+// RoundWithMode rounds to the number of places using the rounding mode. A negative number of
+// places rounds to the left of the decimal point, i.e. -2 rounds to the nearest hundred.
+func (d Decimal) RoundWithMode(places int32, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		return d
+	}
+
+	return roundRat(d.Rat(), places, mode)
+}
+
+// Quantize rounds to a multiple of the increment using the rounding mode, the result has the scale
+// of the increment. This is how prices and sizes are fitted to a product's quote and base increments.
+// A zero increment returns d unchanged.
+func (d Decimal) Quantize(increment Decimal, mode RoundingMode) Decimal {
+	if increment.IsZero() {
+		return d
+	}
+
+	steps := roundRat(new(big.Rat).Quo(d.Rat(), increment.Rat()), 0, mode)
+
+	return steps.Mul(increment)
+}
+
+// roundRat rounds the rational to the number of places using the rounding mode.
+func roundRat(r *big.Rat, places int32, mode RoundingMode) Decimal {
+	num := new(big.Int).Set(r.Num())
+	den := new(big.Int).Set(r.Denom())
+
+	if places >= 0 {
+		num.Mul(num, pow10(places))
+	} else {
+		den.Mul(den, pow10(-places))
+	}
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() != 0 {
+		sign := int64(r.Sign())
+
+		// Compare the remainder to half of the divisor, 2|rem| against den.
+		half := new(big.Int).Abs(rem)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(den)
+
+		var away bool
+
+		switch mode {
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		case RoundUp:
+			away = true
+		case RoundFloor:
+			away = sign < 0
+		case RoundCeiling:
+			away = sign > 0
+		}
+
+		if away {
+			q.Add(q, big.NewInt(sign))
+		}
+	}
+
+	if places < 0 {
+		return Decimal{value: q.Mul(q, pow10(-places))}
+	}
+
+	return Decimal{value: q, scale: places}
+}
+
+// Rat returns d as a rational number.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.unscaled(), pow10(d.scale))
+}
+
+// Float64 returns the nearest float64 to d and whether the conversion is exact.
+func (d Decimal) Float64() (float64, bool) {
+	return d.Rat().Float64()
+}
+
+// String formats d in decimal notation with its scale, i.e. '0.00100000'.
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled()).String()
+
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+
+		i := len(digits) - int(d.scale)
+		digits = digits[:i] + "." + digits[i:]
+	}
+
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// StringFixed formats d rounded half away from zero to exactly the number of places.
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+
+	if places > d.scale {
+		return Decimal{value: d.rescale(places), scale: places}.String()
+	}
+
+	return roundRat(d.Rat(), places, RoundHalfUp).String()
+}
+
+// MarshalJSON encodes d as a JSON string, the format used by Coinbase.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a JSON string or number, an empty string is decoded as zero.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	s := string(data)
+
+	if len(data) > 0 && data[0] == '"' {
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal decimal: %w", err)
+		}
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, empty text is decoded as zero.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Decimal{}
+		return nil
+	}
+
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+// EncodeValues encodes d as a query parameter, implementing the go-querystring Encoder interface.
+func (d Decimal) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, d.String())
+	return nil
+}
+
+// parseNumberField parses an optional numeric field kept as the raw JSON number, missing values are zero.
+func parseNumberField(name string, n *json.Number) (Decimal, error) {
+	return parseDecimalField(name, (*string)(n))
+}
+
+// parseDecimalField parses an optional monetary field, missing and empty values are zero.
+func parseDecimalField(name string, s *string) (Decimal, error) {
+	if s == nil || *s == "" {
+		return Decimal{}, nil
+	}
+
+	d, err := ParseDecimal(*s)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return d, nil
+}
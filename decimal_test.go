@@ -0,0 +1,286 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // Formatted decimal, empty if the input is invalid.
+	}{
+		{"0", "0"},
+		{"-12.345", "-12.345"},
+		{"+7", "7"},
+		{"0.00100000", "0.00100000"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"1.5e-8", "0.000000015"},
+		{"1.5E3", "1500"},
+		{"-2.50e1", "-25.0"},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+		{"", ""},
+		{"-", ""},
+		{".", ""},
+		{"abc", ""},
+		{"1.2.3", ""},
+		{"1.-2", ""},
+		{"0x10", ""},
+		{"1_000", ""},
+		{"1e", ""},
+		{"1e1000000000", ""},
+		{" 1", ""},
+	}
+
+	for _, tt := range tests {
+		d, err := coinbase.ParseDecimal(tt.in)
+
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("ParseDecimal(%q) = %s, want an error", tt.in, d)
+		case tt.want != "" && err != nil:
+			t.Errorf("ParseDecimal(%q) failed: %v", tt.in, err)
+		case tt.want != "" && d.String() != tt.want:
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, d, tt.want)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := coinbase.MustParseDecimal
+
+	tests := []struct {
+		name string
+		got  coinbase.Decimal
+		want string
+	}{
+		{"add aligns scales", d("0.1").Add(d("0.02")), "0.12"},
+		{"add is exact", d("0.1").Add(d("0.2")), "0.3"},
+		{"sub", d("1").Sub(d("0.00000001")), "0.99999999"},
+		{"sub below zero", d("1.5").Sub(d("2.25")), "-0.75"},
+		{"mul adds scales", d("1.5").Mul(d("0.25")), "0.375"},
+		{"mul negative", d("-3").Mul(d("0.1")), "-0.3"},
+		{"div rounds half up", d("2").Div(d("3"), 4), "0.6667"},
+		{"div exact", d("1").Div(d("8"), 3), "0.125"},
+		{"div with mode", d("2").DivWithMode(d("3"), 4, coinbase.RoundDown), "0.6666"},
+		{"neg", d("1.50").Neg(), "-1.50"},
+		{"abs", d("-0.01").Abs(), "0.01"},
+		{"zero value", coinbase.Decimal{}.Add(d("1")), "1"},
+		{"new decimal", coinbase.NewDecimal(12345, 2), "123.45"},
+		{"new decimal negative scale", coinbase.NewDecimal(12, -2), "1200"},
+	}
+
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDecimalRoundWithMode(t *testing.T) {
+	modes := []struct {
+		name string
+		mode coinbase.RoundingMode
+	}{
+		{"half up", coinbase.RoundHalfUp},
+		{"half even", coinbase.RoundHalfEven},
+		{"down", coinbase.RoundDown},
+		{"up", coinbase.RoundUp},
+		{"floor", coinbase.RoundFloor},
+		{"ceiling", coinbase.RoundCeiling},
+	}
+
+	tests := []struct {
+		in     string
+		places int32
+		want   [6]string // Expected result of each mode, in the order above.
+	}{
+		{"2.5", 0, [6]string{"3", "2", "2", "3", "2", "3"}},
+		{"3.5", 0, [6]string{"4", "4", "3", "4", "3", "4"}},
+		{"-2.5", 0, [6]string{"-3", "-2", "-2", "-3", "-3", "-2"}},
+		{"1.2345", 2, [6]string{"1.23", "1.23", "1.23", "1.24", "1.23", "1.24"}},
+		{"-1.235", 2, [6]string{"-1.24", "-1.24", "-1.23", "-1.24", "-1.24", "-1.23"}},
+		{"1250", -2, [6]string{"1300", "1200", "1200", "1300", "1200", "1300"}},
+		{"0.5", 3, [6]string{"0.5", "0.5", "0.5", "0.5", "0.5", "0.5"}},
+	}
+
+	for _, tt := range tests {
+		for i, m := range modes {
+			got := coinbase.MustParseDecimal(tt.in).RoundWithMode(tt.places, m.mode)
+			if got.String() != tt.want[i] {
+				t.Errorf("%s rounded %s to %d places = %s, want %s", tt.in, m.name, tt.places, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDecimalQuantize(t *testing.T) {
+	tests := []struct {
+		in        string
+		increment string
+		mode      coinbase.RoundingMode
+		want      string
+	}{
+		{"42000.005", "0.01", coinbase.RoundDown, "42000.00"},
+		{"42000.005", "0.01", coinbase.RoundHalfUp, "42000.01"},
+		{"0.0123456789", "0.00000001", coinbase.RoundDown, "0.01234567"},
+		{"17", "5", coinbase.RoundFloor, "15"},
+		{"17", "0.5", coinbase.RoundCeiling, "17.0"},
+		{"1.23", "0", coinbase.RoundDown, "1.23"},
+	}
+
+	for _, tt := range tests {
+		got := coinbase.MustParseDecimal(tt.in).Quantize(coinbase.MustParseDecimal(tt.increment), tt.mode)
+		if got.String() != tt.want {
+			t.Errorf("Quantize(%s, %s) = %s, want %s", tt.in, tt.increment, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1.000", 0},
+		{"0.1", "0.09", 1},
+		{"-1", "0", -1},
+		{"0", "-0.0", 0},
+	}
+
+	for _, tt := range tests {
+		a, b := coinbase.MustParseDecimal(tt.a), coinbase.MustParseDecimal(tt.b)
+
+		if got := a.Cmp(b); got != tt.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+
+		if a.Equal(b) != (tt.want == 0) || a.LessThan(b) != (tt.want < 0) || a.GreaterThan(b) != (tt.want > 0) {
+			t.Errorf("comparisons of %s and %s disagree with Cmp", tt.a, tt.b)
+		}
+	}
+}
+
+func TestDecimalStringFixed(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"1.5", 3, "1.500"},
+		{"1.005", 2, "1.01"},
+		{"-1.005", 2, "-1.01"},
+		{"0.001", 0, "0"},
+		{"12", -1, "12"},
+	}
+
+	for _, tt := range tests {
+		if got := coinbase.MustParseDecimal(tt.in).StringFixed(tt.places); got != tt.want {
+			t.Errorf("StringFixed(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // Re-encoded JSON, empty if decoding must fail.
+	}{
+		{`"0.00100000"`, `"0.00100000"`},
+		{`0.25`, `"0.25"`},
+		{`""`, `"0"`},
+		{`null`, `"0"`},
+		{`"1e-3"`, `"0.001"`},
+		{`"nope"`, ``},
+		{`true`, ``},
+	}
+
+	for _, tt := range tests {
+		var v struct {
+			D coinbase.Decimal `json:"d"`
+		}
+
+		err := json.Unmarshal([]byte(`{"d":`+tt.in+`}`), &v)
+
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("decoding %s succeeded, want an error", tt.in)
+		case tt.want != "" && err != nil:
+			t.Errorf("failed to decode %s: %v", tt.in, err)
+		case tt.want != "":
+			out, _ := json.Marshal(v)
+
+			if string(out) != `{"d":`+tt.want+`}` {
+				t.Errorf("%s re-encoded as %s, want %s", tt.in, out, tt.want)
+			}
+		}
+	}
+}
+
+func TestFundsDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "0", false},
+		{"10.50", "10.50", false},
+		{"ten", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := coinbase.Funds{Value: tt.value, Currency: "USD"}.Decimal()
+
+		if (err != nil) != tt.wantErr || (err == nil && got.String() != tt.want) {
+			t.Errorf("Funds{%q}.Decimal() = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestNumberFieldDecimals(t *testing.T) {
+	var summary coinbase.GetTransactionsSummaryResponse
+
+	err := json.Unmarshal([]byte(`{"total_volume":12345678.123456789,"total_fees":"0.000000010000000001","advanced_trade_only_fees":1e-3}`), &summary)
+	if err != nil {
+		t.Fatalf("failed to decode transaction summary: %v", err)
+	}
+
+	var position coinbase.SpotPosition
+
+	err = json.Unmarshal([]byte(`{"total_balance_fiat":16777217.01,"allocation":0.1}`), &position)
+	if err != nil {
+		t.Fatalf("failed to decode spot position: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		parse func() (coinbase.Decimal, error)
+		want  string
+	}{
+		{"total volume", summary.TotalVolumeDecimal, "12345678.123456789"},
+		{"total fees", summary.TotalFeesDecimal, "0.000000010000000001"},
+		{"advanced trade only fees", summary.AdvancedTradeOnlyFeesDecimal, "0.001"},
+		{"missing coinbase pro fees", summary.CoinbaseProFeesDecimal, "0"},
+		{"total balance fiat", position.TotalBalanceFiatDecimal, "16777217.01"},
+		{"allocation", position.AllocationDecimal, "0.1"},
+		{"missing one day change", position.OneDayChangeDecimal, "0"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.parse()
+
+		if err != nil || got.String() != tt.want {
+			t.Errorf("%s is %s, %v, want %s", tt.name, got, err, tt.want)
+		}
+	}
+}
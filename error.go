@@ -8,10 +8,34 @@
 package coinbase
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
+// Sentinel errors matched by errors.Is against a *CoinbaseError, based on its HTTP status and gRPC code.
+var (
+	ErrUnauthorized       = errors.New("coinbase: unauthorized")        // The credentials are missing, invalid or expired.
+	ErrPermissionDenied   = errors.New("coinbase: permission denied")   // The credentials lack the permission needed for the request.
+	ErrNotFound           = errors.New("coinbase: not found")           // The requested resource does not exist.
+	ErrRateLimited        = errors.New("coinbase: rate limited")        // Too many requests were made, see WithRateLimit.
+	ErrInvalidArgument    = errors.New("coinbase: invalid argument")    // The request is malformed or has invalid parameters.
+	ErrInsufficientFunds  = errors.New("coinbase: insufficient funds")  // The account does not hold enough funds for the request.
+	ErrServiceUnavailable = errors.New("coinbase: service unavailable") // Coinbase is down or overloaded, the request may be retried.
+)
+
+// gRPC status codes used by Coinbase in the code field of errors.
+const (
+	grpcCodeInvalidArgument   = 3
+	grpcCodeNotFound          = 5
+	grpcCodePermissionDenied  = 7
+	grpcCodeResourceExhausted = 8
+	grpcCodeUnavailable       = 14
+	grpcCodeUnauthenticated   = 16
+)
+
 type ErrorDetails struct {
 	// A URL/resource name that uniquely identifies the type of the serialized protocol buffer message.
 	// This string must contain at least one "/" character.
@@ -26,15 +50,21 @@ type ErrorDetails struct {
 	// the official protobuf release, and it is not used for type URLs beginning with type.googleapis.com. Schemes other than `http`, `https`
 	// (or the empty scheme) might be used with implementation specific semantics.
 	TypeUrl string `json:"type_url"`
-	Value   byte   `json:"value"` // Must be a valid serialized protocol buffer of the above specified type.
+	Value   []byte `json:"value"` // Must be a valid serialized protocol buffer of the above specified type, base64 encoded in JSON.
 }
 
 // Detault error returned by the coinbase API.
 type CoinbaseError struct {
-	Err     *string        `json:"error"`
-	Code    *int32         `json:"code"`
-	Message *string        `json:"message"`
-	Details []ErrorDetails `json:"details"`
+	Err         *string        `json:"error"`
+	Code        *int32         `json:"code"`
+	Message     *string        `json:"message"`
+	Description *string        `json:"error_details"` // Human readable explanation of the error, not always present.
+	Details     []ErrorDetails `json:"details"`
+
+	StatusCode int    `json:"-"` // HTTP status code of the response.
+	RequestID  string `json:"-"` // Value of the X-Request-Id response header, useful when contacting Coinbase support.
+	Method     string `json:"-"` // HTTP method of the failed request.
+	Endpoint   string `json:"-"` // URL path of the failed request.
 }
 
 func (e CoinbaseError) GetCode() int {
@@ -68,7 +98,7 @@ func (e CoinbaseError) Error() string {
 
 	n := len(e.Details)
 	for i, detail := range e.Details {
-		details.WriteString(fmt.Sprintf(`{"type_url": "%s", "value": %v}`, detail.TypeUrl, detail.Value))
+		details.WriteString(fmt.Sprintf(`{"type_url": "%s", "value": "%s"}`, detail.TypeUrl, base64.StdEncoding.EncodeToString(detail.Value)))
 
 		if i < n-1 {
 			details.WriteString(", ")
@@ -85,3 +115,101 @@ func (e CoinbaseError) Error() string {
 		details.String(),
 	)
 }
+
+func (e CoinbaseError) getDescription() string {
+	if e.Description == nil {
+		return ""
+	}
+
+	return *e.Description
+}
+
+// Is reports whether the error matches one of the sentinel errors of the package, so callers can
+// use errors.Is(err, coinbase.ErrNotFound) instead of inspecting the status and code.
+func (e CoinbaseError) Is(target error) bool {
+	code := e.GetCode()
+	reason := strings.ToUpper(e.getError())
+
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || code == grpcCodeUnauthenticated || reason == "UNAUTHENTICATED"
+	case ErrPermissionDenied:
+		return e.StatusCode == http.StatusForbidden || code == grpcCodePermissionDenied || reason == "PERMISSION_DENIED"
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || code == grpcCodeNotFound || reason == "NOT_FOUND"
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || code == grpcCodeResourceExhausted || reason == "RESOURCE_EXHAUSTED"
+	case ErrInvalidArgument:
+		return e.StatusCode == http.StatusBadRequest || code == grpcCodeInvalidArgument || reason == "INVALID_ARGUMENT"
+	case ErrServiceUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable || code == grpcCodeUnavailable || reason == "UNAVAILABLE"
+	case ErrInsufficientFunds:
+		// Coinbase has no dedicated code for this, only the reason of the error or of its ErrorInfo detail tells.
+		if isInsufficientFundsReason(e.getError()) {
+			return true
+		}
+
+		for _, detail := range e.Details {
+			if info, err := detail.Decode(); err == nil {
+				if info, ok := info.(*ErrorInfo); ok && isInsufficientFundsReason(info.Reason) {
+					return true
+				}
+			}
+		}
+
+		return false
+	case ErrUnexpectedAPIResponse:
+		return e.getError() == ErrUnexpectedAPIResponse.Error()
+	default:
+		return false
+	}
+}
+
+// insufficientFundsReasons are the failure reasons Coinbase gives when an account lacks the funds for a request.
+var insufficientFundsReasons = map[string]bool{
+	string(OrderFailureReasonInsufficientFund):              true,
+	string(OrderFailureReasonInsufficientFunds):             true,
+	string(PreviewFailureReasonInsufficientFund):            true,
+	string(PreviewFailureReasonInsufficientLedgerBalance):   true,
+	string(PreviewFailureReasonInsufficientFundsForFutures): true,
+}
+
+func isInsufficientFundsReason(reason string) bool {
+	return insufficientFundsReasons[strings.ToUpper(reason)]
+}
+
+// AuthenticationError is returned when the client could not authenticate a request, before it was
+// sent. Errors of the Authenticator are wrapped and can be matched with errors.Is and errors.As.
+type AuthenticationError struct {
+	Err error // Error returned by the Authenticator.
+}
+
+func (e *AuthenticationError) Error() string {
+	return "failed to authenticate HTTP request: " + e.Err.Error()
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.Err
+}
+
+// notSentError marks an error that happened before the request was sent to Coinbase, such as failing
+// to build the request or to wait for the rate limiter. Its message is the one of the wrapped error.
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+// wasNotSent reports whether the request failed with err before it was sent to Coinbase.
+func wasNotSent(err error) bool {
+	var notSent *notSentError
+	var authErr *AuthenticationError
+
+	return errors.As(err, &notSent) || errors.As(err, &authErr)
+}
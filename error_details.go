@@ -0,0 +1,535 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Type URLs of the google.rpc error details Coinbase attaches to errors.
+const (
+	typeURLErrorInfo           = "type.googleapis.com/google.rpc.ErrorInfo"
+	typeURLRetryInfo           = "type.googleapis.com/google.rpc.RetryInfo"
+	typeURLDebugInfo           = "type.googleapis.com/google.rpc.DebugInfo"
+	typeURLQuotaFailure        = "type.googleapis.com/google.rpc.QuotaFailure"
+	typeURLPreconditionFailure = "type.googleapis.com/google.rpc.PreconditionFailure"
+	typeURLBadRequest          = "type.googleapis.com/google.rpc.BadRequest"
+	typeURLRequestInfo         = "type.googleapis.com/google.rpc.RequestInfo"
+	typeURLResourceInfo        = "type.googleapis.com/google.rpc.ResourceInfo"
+	typeURLHelp                = "type.googleapis.com/google.rpc.Help"
+	typeURLLocalizedMessage    = "type.googleapis.com/google.rpc.LocalizedMessage"
+)
+
+// Longest delay in whole seconds that a time.Duration can hold, with room for the nanoseconds.
+const maxDurationSeconds = math.MaxInt64/int64(time.Second) - 1
+
+// ErrUnsupportedErrorDetail - the error detail is not one of the google.rpc types known to the package.
+var ErrUnsupportedErrorDetail = errors.New("unsupported error detail type")
+
+// ErrorInfo describes the cause of the error with structured details.
+type ErrorInfo struct {
+	Reason   string            // The reason of the error, a constant value in UPPER_SNAKE_CASE.
+	Domain   string            // The logical grouping to which the reason belongs.
+	Metadata map[string]string // Additional structured details about the error.
+}
+
+// RetryInfo describes when the client may retry a failed request.
+type RetryInfo struct {
+	RetryDelay time.Duration // Clients should wait at least this long before retrying.
+}
+
+// DebugInfo describes additional debugging information.
+type DebugInfo struct {
+	StackEntries []string // The stack trace entries indicating where the error occurred.
+	Detail       string   // Additional debugging information provided by the server.
+}
+
+type QuotaViolation struct {
+	Subject     string // The subject on which the quota check failed.
+	Description string // A description of how the quota check failed.
+}
+
+// QuotaFailure describes how a quota check failed.
+type QuotaFailure struct {
+	Violations []QuotaViolation
+}
+
+type PreconditionViolation struct {
+	Type        string // The type of precondition failure.
+	Subject     string // The subject, relative to the type, that failed.
+	Description string // A description of how the precondition failed.
+}
+
+// PreconditionFailure describes what preconditions have failed.
+type PreconditionFailure struct {
+	Violations []PreconditionViolation
+}
+
+type FieldViolation struct {
+	Field       string // A path leading to a field in the request body.
+	Description string // A description of why the request element is bad.
+}
+
+// BadRequest describes violations in a client request.
+type BadRequest struct {
+	FieldViolations []FieldViolation
+}
+
+// RequestInfo contains metadata about the request that clients can attach when filing a bug or providing other forms of feedback.
+type RequestInfo struct {
+	RequestID   string // An opaque string that should only be interpreted by the service generating it.
+	ServingData string // Any data that was used to serve this request.
+}
+
+// ResourceInfo describes the resource that is being accessed.
+type ResourceInfo struct {
+	ResourceType string // A name for the type of resource being accessed.
+	ResourceName string // The name of the resource being accessed.
+	Owner        string // The owner of the resource.
+	Description  string // Describes what error is encountered when accessing this resource.
+}
+
+type HelpLink struct {
+	Description string // Describes what the link offers.
+	URL         string // The URL of the link.
+}
+
+// Help provides links to documentation or for performing an out of band action.
+type Help struct {
+	Links []HelpLink
+}
+
+// LocalizedMessage provides a localized error message that is safe to return to the user.
+type LocalizedMessage struct {
+	Locale  string // The locale used following the specification defined at https://www.rfc-editor.org/rfc/bcp/bcp47.txt.
+	Message string // The localized error message in the above locale.
+}
+
+// Decode decodes the value of the detail into one of the google.rpc error detail types: *ErrorInfo,
+// *RetryInfo, *DebugInfo, *QuotaFailure, *PreconditionFailure, *BadRequest, *RequestInfo, *ResourceInfo,
+// *Help or *LocalizedMessage. ErrUnsupportedErrorDetail is returned for any other type.
+func (d ErrorDetails) Decode() (any, error) {
+	// The type URL may have any host, only the fully qualified name at the end identifies the type.
+	name := d.TypeUrl[strings.LastIndex(d.TypeUrl, "/")+1:]
+
+	var (
+		v   any
+		err error
+	)
+
+	switch "type.googleapis.com/" + name {
+	case typeURLErrorInfo:
+		v, err = decodeErrorInfo(d.Value)
+	case typeURLRetryInfo:
+		v, err = decodeRetryInfo(d.Value)
+	case typeURLDebugInfo:
+		v, err = decodeDebugInfo(d.Value)
+	case typeURLQuotaFailure:
+		v, err = decodeQuotaFailure(d.Value)
+	case typeURLPreconditionFailure:
+		v, err = decodePreconditionFailure(d.Value)
+	case typeURLBadRequest:
+		v, err = decodeBadRequest(d.Value)
+	case typeURLRequestInfo:
+		v, err = decodeRequestInfo(d.Value)
+	case typeURLResourceInfo:
+		v, err = decodeResourceInfo(d.Value)
+	case typeURLHelp:
+		v, err = decodeHelp(d.Value)
+	case typeURLLocalizedMessage:
+		v, err = decodeLocalizedMessage(d.Value)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedErrorDetail, d.TypeUrl)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode error detail '%s': %w", d.TypeUrl, err)
+	}
+
+	return v, nil
+}
+
+// RetryDelay returns the delay from the first RetryInfo detail of the error, if it has one.
+func (e CoinbaseError) RetryDelay() (time.Duration, bool) {
+	for _, detail := range e.Details {
+		if info, err := detail.Decode(); err == nil {
+			if retry, ok := info.(*RetryInfo); ok {
+				return retry.RetryDelay, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func decodeErrorInfo(b []byte) (*ErrorInfo, error) {
+	var info ErrorInfo
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		switch field {
+		case 1:
+			info.Reason = p.string()
+		case 2:
+			info.Domain = p.string()
+		case 3:
+			var key, value string
+
+			err := decodeProtobuf(p.bytes, func(field int, p protobufField) error {
+				switch field {
+				case 1:
+					key = p.string()
+				case 2:
+					value = p.string()
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+
+			info.Metadata[key] = value
+		}
+
+		return nil
+	})
+
+	return &info, err
+}
+
+func decodeRetryInfo(b []byte) (*RetryInfo, error) {
+	var info RetryInfo
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		if field != 1 {
+			return nil
+		}
+
+		var seconds, nanos int64
+
+		// google.protobuf.Duration
+		err := decodeProtobuf(p.bytes, func(field int, p protobufField) error {
+			switch field {
+			case 1:
+				seconds = int64(p.varint)
+			case 2:
+				nanos = int64(int32(p.varint))
+			}
+
+			return nil
+		})
+
+		// A Duration can be longer than a time.Duration, such delays are capped rather than overflowing.
+		switch {
+		case seconds > maxDurationSeconds:
+			info.RetryDelay = time.Duration(math.MaxInt64)
+		case seconds < -maxDurationSeconds:
+			info.RetryDelay = time.Duration(math.MinInt64)
+		default:
+			info.RetryDelay = time.Duration(seconds)*time.Second + time.Duration(nanos)
+		}
+
+		return err
+	})
+
+	return &info, err
+}
+
+func decodeDebugInfo(b []byte) (*DebugInfo, error) {
+	var info DebugInfo
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		switch field {
+		case 1:
+			info.StackEntries = append(info.StackEntries, p.string())
+		case 2:
+			info.Detail = p.string()
+		}
+
+		return nil
+	})
+
+	return &info, err
+}
+
+func decodeQuotaFailure(b []byte) (*QuotaFailure, error) {
+	var failure QuotaFailure
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		if field != 1 {
+			return nil
+		}
+
+		var v QuotaViolation
+
+		err := decodeProtobuf(p.bytes, func(field int, p protobufField) error {
+			switch field {
+			case 1:
+				v.Subject = p.string()
+			case 2:
+				v.Description = p.string()
+			}
+
+			return nil
+		})
+
+		failure.Violations = append(failure.Violations, v)
+
+		return err
+	})
+
+	return &failure, err
+}
+
+func decodePreconditionFailure(b []byte) (*PreconditionFailure, error) {
+	var failure PreconditionFailure
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		if field != 1 {
+			return nil
+		}
+
+		var v PreconditionViolation
+
+		err := decodeProtobuf(p.bytes, func(field int, p protobufField) error {
+			switch field {
+			case 1:
+				v.Type = p.string()
+			case 2:
+				v.Subject = p.string()
+			case 3:
+				v.Description = p.string()
+			}
+
+			return nil
+		})
+
+		failure.Violations = append(failure.Violations, v)
+
+		return err
+	})
+
+	return &failure, err
+}
+
+func decodeBadRequest(b []byte) (*BadRequest, error) {
+	var req BadRequest
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		if field != 1 {
+			return nil
+		}
+
+		var v FieldViolation
+
+		err := decodeProtobuf(p.bytes, func(field int, p protobufField) error {
+			switch field {
+			case 1:
+				v.Field = p.string()
+			case 2:
+				v.Description = p.string()
+			}
+
+			return nil
+		})
+
+		req.FieldViolations = append(req.FieldViolations, v)
+
+		return err
+	})
+
+	return &req, err
+}
+
+func decodeRequestInfo(b []byte) (*RequestInfo, error) {
+	var info RequestInfo
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		switch field {
+		case 1:
+			info.RequestID = p.string()
+		case 2:
+			info.ServingData = p.string()
+		}
+
+		return nil
+	})
+
+	return &info, err
+}
+
+func decodeResourceInfo(b []byte) (*ResourceInfo, error) {
+	var info ResourceInfo
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		switch field {
+		case 1:
+			info.ResourceType = p.string()
+		case 2:
+			info.ResourceName = p.string()
+		case 3:
+			info.Owner = p.string()
+		case 4:
+			info.Description = p.string()
+		}
+
+		return nil
+	})
+
+	return &info, err
+}
+
+func decodeHelp(b []byte) (*Help, error) {
+	var help Help
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		if field != 1 {
+			return nil
+		}
+
+		var link HelpLink
+
+		err := decodeProtobuf(p.bytes, func(field int, p protobufField) error {
+			switch field {
+			case 1:
+				link.Description = p.string()
+			case 2:
+				link.URL = p.string()
+			}
+
+			return nil
+		})
+
+		help.Links = append(help.Links, link)
+
+		return err
+	})
+
+	return &help, err
+}
+
+func decodeLocalizedMessage(b []byte) (*LocalizedMessage, error) {
+	var msg LocalizedMessage
+
+	err := decodeProtobuf(b, func(field int, p protobufField) error {
+		switch field {
+		case 1:
+			msg.Locale = p.string()
+		case 2:
+			msg.Message = p.string()
+		}
+
+		return nil
+	})
+
+	return &msg, err
+}
+
+// Protocol buffer wire types, see https://protobuf.dev/programming-guides/encoding/.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errMalformedProtobuf = errors.New("malformed protocol buffer")
+
+// protobufField is the value of a single field, varint is set for varint fields and bytes for length
+// delimited fields.
+type protobufField struct {
+	varint uint64
+	bytes  []byte
+}
+
+func (p protobufField) string() string {
+	return string(p.bytes)
+}
+
+// decodeProtobuf walks the fields of a serialized protocol buffer message, calling fn for every varint
+// and length delimited field. Fixed size fields are skipped as none of the supported types use them.
+func decodeProtobuf(b []byte, fn func(field int, p protobufField) error) error {
+	for len(b) > 0 {
+		key, n := decodeVarint(b)
+		if n == 0 {
+			return errMalformedProtobuf
+		}
+
+		b = b[n:]
+
+		field := int(key >> 3)
+
+		var p protobufField
+
+		switch key & 7 {
+		case wireVarint:
+			p.varint, n = decodeVarint(b)
+			if n == 0 {
+				return errMalformedProtobuf
+			}
+
+			b = b[n:]
+		case wireBytes:
+			length, n := decodeVarint(b)
+			if n == 0 || uint64(len(b)-n) < length {
+				return errMalformedProtobuf
+			}
+
+			p.bytes = b[n : n+int(length)]
+			b = b[n+int(length):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errMalformedProtobuf
+			}
+
+			b = b[8:]
+
+			continue
+		case wireFixed32:
+			if len(b) < 4 {
+				return errMalformedProtobuf
+			}
+
+			b = b[4:]
+
+			continue
+		default:
+			return errMalformedProtobuf
+		}
+
+		err := fn(field, p)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeVarint decodes a base 128 varint, returning the number of bytes read or 0 if it is malformed.
+func decodeVarint(b []byte) (uint64, int) {
+	var v uint64
+
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+
+	return 0, 0
+}
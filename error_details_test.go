@@ -0,0 +1,221 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Golden encodings of google.rpc error details, produced by proto.Marshal of the messages of
+// google.golang.org/genproto/googleapis/rpc/errdetails.
+var errorDetailsGolden = []struct {
+	name string
+	hex  string
+	want any
+}{
+	{
+		name: "ErrorInfo",
+		hex:  "0a11494e53554646494349454e545f46554e44120c636f696e626173652e636f6d1a0f0a0863757272656e63791203555344",
+		want: &ErrorInfo{Reason: "INSUFFICIENT_FUND", Domain: "coinbase.com", Metadata: map[string]string{"currency": "USD"}},
+	},
+	{
+		name: "RetryInfo",
+		hex:  "0a0808011080cab5ee01",
+		want: &RetryInfo{RetryDelay: 1500 * time.Millisecond},
+	},
+	{
+		name: "DebugInfo",
+		hex:  "0a06612e676f3a310a06622e676f3a321204626f6f6d",
+		want: &DebugInfo{StackEntries: []string{"a.go:1", "b.go:2"}, Detail: "boom"},
+	},
+	{
+		name: "QuotaFailure",
+		hex:  "0a120a06757365723a311208746f6f206d616e79",
+		want: &QuotaFailure{Violations: []QuotaViolation{{Subject: "user:1", Description: "too many"}}},
+	},
+	{
+		name: "PreconditionFailure",
+		hex:  "0a1b0a03544f531206757365723a311a0c6e6f74206163636570746564",
+		want: &PreconditionFailure{Violations: []PreconditionViolation{{Type: "TOS", Subject: "user:1", Description: "not accepted"}}},
+	},
+	{
+		name: "BadRequest",
+		hex:  "0a160a09626173655f73697a651209746f6f20736d616c6c0a100a047369646512087265717569726564",
+		want: &BadRequest{FieldViolations: []FieldViolation{{Field: "base_size", Description: "too small"}, {Field: "side", Description: "required"}}},
+	},
+	{
+		name: "RequestInfo",
+		hex:  "0a057265712d31120178",
+		want: &RequestInfo{RequestID: "req-1", ServingData: "x"},
+	},
+	{
+		name: "ResourceInfo",
+		hex:  "0a056f726465721201311a026d652204676f6e65",
+		want: &ResourceInfo{ResourceType: "order", ResourceName: "1", Owner: "me", Description: "gone"},
+	},
+	{
+		name: "Help",
+		hex:  "0a250a04646f6373121d68747470733a2f2f646f63732e6364702e636f696e626173652e636f6d",
+		want: &Help{Links: []HelpLink{{Description: "docs", URL: "https://docs.cdp.coinbase.com"}}},
+	},
+	{
+		name: "LocalizedMessage",
+		hex:  "0a05656e2d55531212496e73756666696369656e742066756e6473",
+		want: &LocalizedMessage{Locale: "en-US", Message: "Insufficient funds"},
+	},
+}
+
+func TestErrorDetailsDecodeGolden(t *testing.T) {
+	for _, tt := range errorDetailsGolden {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("invalid golden encoding: %v", err)
+			}
+
+			got, err := ErrorDetails{TypeUrl: "type.googleapis.com/google.rpc." + tt.name, Value: value}.Decode()
+			if err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decoded %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorDetailsDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		typeURL string
+		hex     string
+		want    any
+		err     error // Expected error, errMalformedProtobuf stands for any decoding error.
+	}{
+		{
+			name:    "any host in the type URL",
+			typeURL: "example.com/google.rpc.RetryInfo",
+			hex:     "0a020805",
+			want:    &RetryInfo{RetryDelay: 5 * time.Second},
+		},
+		{
+			name:    "unknown fields are skipped",
+			typeURL: typeURLLocalizedMessage,
+			hex:     "1807" + "210102030405060708" + "2d01020304" + "0a02656e",
+			want:    &LocalizedMessage{Locale: "en"},
+		},
+		{
+			name:    "last value of a field wins",
+			typeURL: typeURLRetryInfo,
+			hex:     "0a0208010a020802",
+			want:    &RetryInfo{RetryDelay: 2 * time.Second},
+		},
+		{
+			name:    "negative delay",
+			typeURL: typeURLRetryInfo,
+			hex:     "0a0b08ffffffffffffffffff01",
+			want:    &RetryInfo{RetryDelay: -time.Second},
+		},
+		{
+			name:    "delay longer than a time.Duration is capped",
+			typeURL: typeURLRetryInfo,
+			hex:     "0a0608808080e024",
+			want:    &RetryInfo{RetryDelay: time.Duration(math.MaxInt64)},
+		},
+		{
+			name:    "empty message",
+			typeURL: typeURLErrorInfo,
+			want:    &ErrorInfo{},
+		},
+		{
+			name:    "unsupported type",
+			typeURL: "type.googleapis.com/google.protobuf.Duration",
+			err:     ErrUnsupportedErrorDetail,
+		},
+		{
+			name:    "truncated length",
+			typeURL: typeURLErrorInfo,
+			hex:     "0a0541",
+			err:     errMalformedProtobuf,
+		},
+		{
+			name:    "truncated varint",
+			typeURL: typeURLRetryInfo,
+			hex:     "0a0208ff",
+			err:     errMalformedProtobuf,
+		},
+		{
+			name:    "truncated fixed64",
+			typeURL: typeURLErrorInfo,
+			hex:     "090102",
+			err:     errMalformedProtobuf,
+		},
+		{
+			name:    "invalid wire type",
+			typeURL: typeURLErrorInfo,
+			hex:     "0b",
+			err:     errMalformedProtobuf,
+		},
+		{
+			name:    "varint longer than 10 bytes",
+			typeURL: typeURLErrorInfo,
+			hex:     "08ffffffffffffffffffff01",
+			err:     errMalformedProtobuf,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("invalid encoding: %v", err)
+			}
+
+			got, err := ErrorDetails{TypeUrl: tt.typeURL, Value: value}.Decode()
+
+			switch {
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error %v, want %v", err, tt.err)
+				}
+			case err != nil:
+				t.Fatalf("failed to decode: %v", err)
+			case !reflect.DeepEqual(got, tt.want):
+				t.Fatalf("decoded %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzErrorDetailsDecode(f *testing.F) {
+	for _, tt := range errorDetailsGolden {
+		value, _ := hex.DecodeString(tt.hex)
+		f.Add("type.googleapis.com/google.rpc."+tt.name, value)
+	}
+
+	f.Fuzz(func(t *testing.T, typeURL string, value []byte) {
+		got, err := ErrorDetails{TypeUrl: typeURL, Value: value}.Decode()
+
+		if (got == nil) == (err == nil) {
+			t.Fatalf("decoded %#v with error %v, want exactly one of them", got, err)
+		}
+
+		// Decoding only reads the value, the same input always gives the same result.
+		again, againErr := ErrorDetails{TypeUrl: typeURL, Value: value}.Decode()
+
+		if !reflect.DeepEqual(got, again) || (err == nil) != (againErr == nil) {
+			t.Fatalf("decoding is not deterministic: %#v, %v then %#v, %v", got, err, again, againErr)
+		}
+	})
+}
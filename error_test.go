@@ -0,0 +1,200 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func TestCoinbaseErrorIs(t *testing.T) {
+	errorInfo := func(reason string) coinbase.ErrorDetails {
+		// google.rpc.ErrorInfo with the reason.
+		return coinbase.ErrorDetails{
+			TypeUrl: "type.googleapis.com/google.rpc.ErrorInfo",
+			Value:   append([]byte{0x0a, byte(len(reason))}, reason...),
+		}
+	}
+
+	code := func(c int32) *int32 { return &c }
+
+	tests := []struct {
+		name string
+		err  coinbase.CoinbaseError
+		want []error
+	}{
+		{
+			name: "status",
+			err:  coinbase.CoinbaseError{StatusCode: http.StatusNotFound},
+			want: []error{coinbase.ErrNotFound},
+		},
+		{
+			name: "grpc code",
+			err:  coinbase.CoinbaseError{StatusCode: http.StatusOK, Code: code(16)},
+			want: []error{coinbase.ErrUnauthorized},
+		},
+		{
+			name: "reason",
+			err:  coinbase.CoinbaseError{Err: coinbase.String("permission_denied")},
+			want: []error{coinbase.ErrPermissionDenied},
+		},
+		{
+			name: "rate limited",
+			err:  coinbase.CoinbaseError{StatusCode: http.StatusTooManyRequests},
+			want: []error{coinbase.ErrRateLimited},
+		},
+		{
+			name: "unavailable",
+			err:  coinbase.CoinbaseError{Code: code(14)},
+			want: []error{coinbase.ErrServiceUnavailable},
+		},
+		{
+			name: "insufficient fund reason",
+			err:  coinbase.CoinbaseError{StatusCode: http.StatusBadRequest, Err: coinbase.String("INSUFFICIENT_FUND")},
+			want: []error{coinbase.ErrInvalidArgument, coinbase.ErrInsufficientFunds},
+		},
+		{
+			name: "preview failure reason",
+			err:  coinbase.CoinbaseError{Err: coinbase.String("PREVIEW_INSUFFICIENT_LEDGER_BALANCE")},
+			want: []error{coinbase.ErrInsufficientFunds},
+		},
+		{
+			name: "error info reason",
+			err:  coinbase.CoinbaseError{Err: coinbase.String("INVALID_ARGUMENT"), Details: []coinbase.ErrorDetails{errorInfo("PREVIEW_INSUFFICIENT_FUND")}},
+			want: []error{coinbase.ErrInvalidArgument, coinbase.ErrInsufficientFunds},
+		},
+		{
+			name: "insufficient in the text is not enough",
+			err: coinbase.CoinbaseError{
+				Err:         coinbase.String("INVALID_ARGUMENT"),
+				Message:     coinbase.String("Insufficient balance in source account"),
+				Description: coinbase.String("insufficient permissions"),
+			},
+			want: []error{coinbase.ErrInvalidArgument},
+		},
+		{
+			name: "unrelated error info reason",
+			err:  coinbase.CoinbaseError{Details: []coinbase.ErrorDetails{errorInfo("INSUFFICIENT_PERMISSIONS")}},
+		},
+		{
+			name: "unexpected response",
+			err:  coinbase.CoinbaseError{Err: coinbase.String(coinbase.ErrUnexpectedAPIResponse.Error())},
+			want: []error{coinbase.ErrUnexpectedAPIResponse},
+		},
+	}
+
+	sentinels := []error{
+		coinbase.ErrUnauthorized,
+		coinbase.ErrPermissionDenied,
+		coinbase.ErrNotFound,
+		coinbase.ErrRateLimited,
+		coinbase.ErrInvalidArgument,
+		coinbase.ErrInsufficientFunds,
+		coinbase.ErrServiceUnavailable,
+		coinbase.ErrUnexpectedAPIResponse,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Callers get the error wrapped by the service.
+			err := fmt.Errorf("failed to get product: %w", &tt.err)
+
+			for _, sentinel := range sentinels {
+				want := false
+
+				for _, w := range tt.want {
+					want = want || w == sentinel
+				}
+
+				if errors.Is(err, sentinel) != want {
+					t.Errorf("errors.Is(%v) = %t, want %t", sentinel, !want, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCoinbaseErrorRetryDelay(t *testing.T) {
+	// google.rpc.RetryInfo of 1.5s, encoded by proto.Marshal.
+	value, _ := hex.DecodeString("0a0808011080cab5ee01")
+
+	err := coinbase.CoinbaseError{Details: []coinbase.ErrorDetails{
+		{TypeUrl: "type.googleapis.com/google.rpc.Help"},
+		{TypeUrl: "type.googleapis.com/google.rpc.RetryInfo", Value: value},
+	}}
+
+	delay, ok := err.RetryDelay()
+	if !ok || delay != 1500*time.Millisecond {
+		t.Fatalf("RetryDelay() = %s, %t, want 1.5s", delay, ok)
+	}
+
+	if _, ok := (coinbase.CoinbaseError{}).RetryDelay(); ok {
+		t.Fatal("error without details has a retry delay")
+	}
+}
+
+func TestRetryInfoDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    string // Hex encoded google.rpc.RetryInfo.
+		requests int
+		minWait  time.Duration
+	}{
+		{name: "delay is honoured", delay: "0a0708001080e1eb17", requests: 2, minWait: 50 * time.Millisecond},
+		{name: "delay beyond the maximum fails", delay: "0a020878", requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests > 1 {
+					w.Write([]byte(`{"product_id":"BTC-USD"}`))
+					return
+				}
+
+				value, _ := hex.DecodeString(tt.delay)
+
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, `{"error":"UNAVAILABLE","details":[{"type_url":"type.googleapis.com/google.rpc.RetryInfo","value":%q}]}`, base64.StdEncoding.EncodeToString(value))
+			}))
+			defer srv.Close()
+
+			client := coinbase.NewClient(coinbase.WithBaseURL(srv.URL))
+			coinbase.WithRetryPolicy(coinbase.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetryAfter: time.Second})(client)
+
+			start := time.Now()
+
+			_, err := client.Products.Get(context.Background(), "BTC-USD")
+
+			if requests != tt.requests {
+				t.Fatalf("%d requests, want %d", requests, tt.requests)
+			}
+
+			if tt.requests == 1 && !errors.Is(err, coinbase.ErrServiceUnavailable) {
+				t.Fatalf("error %v, want %v", err, coinbase.ErrServiceUnavailable)
+			}
+
+			if tt.requests > 1 && (err != nil || time.Since(start) < tt.minWait) {
+				t.Fatalf("error %v after %s, want success after %s", err, time.Since(start), tt.minWait)
+			}
+		})
+	}
+}
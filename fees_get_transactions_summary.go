@@ -9,6 +9,7 @@ package coinbase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 )
 
@@ -28,17 +29,47 @@ type FeeTier struct {
 }
 
 type GetTransactionsSummaryResponse struct {
-	TotalVolume         float64 `json:"total_volume"` // Total volume across assets, denoted in USD.
-	TotalFees           float64 `json:"total_fees"`   // Total fees across assets, denoted in USD.
-	FeeTier             FeeTier `json:"fee_tier"`
+	TotalVolume         json.Number `json:"total_volume"` // Total volume across assets, denoted in USD.
+	TotalFees           json.Number `json:"total_fees"`   // Total fees across assets, denoted in USD.
+	FeeTier             FeeTier     `json:"fee_tier"`
 	GoodsAndServicesTax struct {
 		Rate *string `json:"rate"`
 		Type *string `json:"type"` // Possible values: [INCLUSIVE, EXCLUSIVE] // TODO: enum.
 	} `json:"goods_and_services_tax"`
-	AdvancedTradeOnlyVolume *float64 `json:"advanced_trade_only_volume"` // Advanced Trade volume (non-inclusive of Pro) across assets, denoted in USD.
-	AdvancedTradeOnlyFees   *float64 `json:"advanced_trade_only_fees"`   // Advanced Trade fees (non-inclusive of Pro) across assets, denoted in USD.
-	CoinbaseProVolume       *float64 `json:"coinbase_pro_volume"`        // Coinbase Pro volume across assets, denoted in USD.
-	CoinbaseProFees         *float64 `json:"coinbase_pro_fees"`          // Coinbase Pro fees across assets, denoted in USD.
+	AdvancedTradeOnlyVolume *json.Number `json:"advanced_trade_only_volume"` // Advanced Trade volume (non-inclusive of Pro) across assets, denoted in USD.
+	AdvancedTradeOnlyFees   *json.Number `json:"advanced_trade_only_fees"`   // Advanced Trade fees (non-inclusive of Pro) across assets, denoted in USD.
+	CoinbaseProVolume       *json.Number `json:"coinbase_pro_volume"`        // Coinbase Pro volume across assets, denoted in USD.
+	CoinbaseProFees         *json.Number `json:"coinbase_pro_fees"`          // Coinbase Pro fees across assets, denoted in USD.
+}
+
+// TotalVolumeDecimal parses the total volume across assets.
+func (r GetTransactionsSummaryResponse) TotalVolumeDecimal() (Decimal, error) {
+	return parseNumberField("total_volume", &r.TotalVolume)
+}
+
+// TotalFeesDecimal parses the total fees across assets.
+func (r GetTransactionsSummaryResponse) TotalFeesDecimal() (Decimal, error) {
+	return parseNumberField("total_fees", &r.TotalFees)
+}
+
+// AdvancedTradeOnlyVolumeDecimal parses the Advanced Trade volume across assets.
+func (r GetTransactionsSummaryResponse) AdvancedTradeOnlyVolumeDecimal() (Decimal, error) {
+	return parseNumberField("advanced_trade_only_volume", r.AdvancedTradeOnlyVolume)
+}
+
+// AdvancedTradeOnlyFeesDecimal parses the Advanced Trade fees across assets.
+func (r GetTransactionsSummaryResponse) AdvancedTradeOnlyFeesDecimal() (Decimal, error) {
+	return parseNumberField("advanced_trade_only_fees", r.AdvancedTradeOnlyFees)
+}
+
+// CoinbaseProVolumeDecimal parses the Coinbase Pro volume across assets.
+func (r GetTransactionsSummaryResponse) CoinbaseProVolumeDecimal() (Decimal, error) {
+	return parseNumberField("coinbase_pro_volume", r.CoinbaseProVolume)
+}
+
+// CoinbaseProFeesDecimal parses the Coinbase Pro fees across assets.
+func (r GetTransactionsSummaryResponse) CoinbaseProFeesDecimal() (Decimal, error) {
+	return parseNumberField("coinbase_pro_fees", r.CoinbaseProFees)
 }
 
 // GetTransactionsSummary gets a summary of transactions with fee tiers, total volume, and fees.
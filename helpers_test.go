@@ -0,0 +1,76 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// recordedRequest is a request received by an apiServer.
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// apiServer answers every request with the status and body, recording the requests it receives.
+type apiServer struct {
+	*httptest.Server
+
+	Status   int
+	Body     string
+	Requests []recordedRequest
+}
+
+func newAPIServer(t *testing.T, status int, body string) *apiServer {
+	t.Helper()
+
+	s := &apiServer{Status: status, Body: body}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		s.Requests = append(s.Requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(b)})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Status)
+		io.WriteString(w, s.Body)
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// client creates an unauthenticated client calling the server.
+func (s *apiServer) client(opts ...func(*coinbase.Client)) *coinbase.Client {
+	c := coinbase.NewClient(coinbase.WithBaseURL(s.URL))
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// last returns the last request received.
+func (s *apiServer) last(t *testing.T) recordedRequest {
+	t.Helper()
+
+	if len(s.Requests) == 0 {
+		t.Fatal("no request received")
+	}
+
+	return s.Requests[len(s.Requests)-1]
+}
@@ -8,12 +8,14 @@
 package coinbase
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -21,6 +23,9 @@ import (
 // ErrUnexpectedAPIResponse - coinbase API returned a response outside the API documetation.
 var ErrUnexpectedAPIResponse = errors.New("coinbase API returned a response outside the API documetation")
 
+// ErrMissingAuthenticator - the client has no authentication method, it was not created with NewClient().
+var ErrMissingAuthenticator = errors.New("client is missing authentication method, please regenerate the client with NewClient() to use in an unauthenticated state")
+
 func handleRequestError(resp *http.Response) error {
 	if resp == nil {
 		return fmt.Errorf("unable to handle null HTTP response")
@@ -40,55 +45,224 @@ func handleRequestError(resp *http.Response) error {
 		status := int32(resp.StatusCode)
 		message := string(body)
 
-		return &CoinbaseError{
+		cbError = CoinbaseError{
 			Err:     &errString,
 			Code:    &status,
 			Message: &message,
 		}
 	}
 
+	cbError.StatusCode = resp.StatusCode
+	cbError.RequestID = resp.Header.Get("X-Request-Id")
+
+	if resp.Request != nil {
+		cbError.Method = resp.Request.Method
+		cbError.Endpoint = resp.Request.URL.Path
+	}
+
 	return &cbError
 }
 
 // doWithAuthentication adds authentication to the HTTP request with the clients configured
 // authentication method. An error is returned if a method is not configured. If you wish
 // to proceed as an unauthenticated user set the authentication method to unauthenticated{}.
+//
+// If the client has a retry policy, requests that are safe to repeat are retried on transient
+// errors. Every attempt is sent as a copy of the request, with the body rewound and the
+// authentication added again.
+//
+// Every attempt goes through the middleware of the client, authentication is added after it.
 func (c *Client) doWithAuthentication(r *http.Request, successCode int, v any) error {
 	// Add required authentication to request.
 	if c.authenticator == nil {
-		return fmt.Errorf("client is missing authentication method, please regenerate the client with NewClient() to use in an unauthenticated state.")
+		return &AuthenticationError{Err: ErrMissingAuthenticator}
+	}
+
+	endpoint := endpointOf(r)
+
+	// Authentication is the innermost step, so middleware never sees credentials.
+	handler := c.chain(func(call *Call) error {
+		call.Start = time.Now()
+
+		// Sign a copy, the request of the call is left without credentials.
+		req := call.Request.Clone(call.Request.Context())
+
+		err := c.authenticator.Authenticate(req)
+		if err != nil {
+			call.Err = &AuthenticationError{Err: err}
+		} else {
+			call.Response, call.Err = c.do(req, successCode, v)
+		}
+
+		// Credentials that can be refreshed, such as OAuth tokens, may be revoked before they expire.
+		// A rejected request was not carried out, so it is sent once more with the new credentials.
+		if ref, ok := c.authenticator.(refresher); ok && call.Response != nil && call.Response.StatusCode == http.StatusUnauthorized {
+			call.Response, call.Err = c.retryUnauthorized(ref, req, successCode, v, call.Response, call.Err)
+		}
+
+		call.Duration = time.Since(call.Start)
+
+		return call.Err
+	})
+
+	attempts := 1
+
+	// A request whose body cannot be rewound can only be sent once.
+	if c.retryPolicy != nil && isIdempotent(r) && (r.Body == nil || r.Body == http.NoBody || r.GetBody != nil) {
+		attempts = c.retryPolicy.MaxAttempts
 	}
 
-	c.authenticator.Authenticate(r)
+	for attempt := 1; ; attempt++ {
+		req := r
+
+		if attempts > 1 {
+			req = r.Clone(r.Context())
+
+			if r.GetBody != nil {
+				body, err := r.GetBody()
+				if err != nil {
+					return &notSentError{err: fmt.Errorf("failed to rewind HTTP request body: %w", err)}
+				}
+
+				req.Body = body
+			}
+		}
+
+		// Wait before authenticating so the signature is as fresh as possible when the request is sent.
+		err := c.limiter(req).wait(req.Context())
+		if err != nil {
+			return &notSentError{err: fmt.Errorf("failed to wait for rate limit: %w", err)}
+		}
+
+		call := Call{Endpoint: endpoint, Request: req, Attempt: attempt}
+
+		err = handler(&call)
+
+		// The call never reached the innermost handler, middleware failed it without sending it.
+		if err != nil && call.Start.IsZero() {
+			return &notSentError{err: err}
+		}
+
+		if err == nil || attempt >= attempts {
+			return err
+		}
+
+		var authErr *AuthenticationError
+
+		// Middleware that failed the call after it was answered and authentication errors are not transient.
+		if call.Err == nil || errors.As(call.Err, &authErr) {
+			return err
+		}
+
+		resp := call.Response
+
+		// Only transient failures are retried, a network error has no response.
+		if resp != nil && !isRetryableStatus(resp.StatusCode) {
+			return err
+		}
 
-	return c.do(r, successCode, v)
+		if r.Context().Err() != nil {
+			return err
+		}
+
+		wait := c.retryPolicy.backoff(attempt)
+
+		var cbError *CoinbaseError
+
+		// Coinbase may also say when to retry in a google.rpc.RetryInfo detail of the error.
+		if errors.As(err, &cbError) {
+			if delay, ok := cbError.RetryDelay(); ok {
+				if delay > c.retryPolicy.MaxRetryAfter {
+					return err
+				}
+
+				if delay > wait {
+					wait = delay
+				}
+			}
+		}
+
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+				if retryAfter > c.retryPolicy.MaxRetryAfter {
+					return err
+				}
+
+				if retryAfter > wait {
+					wait = retryAfter
+				}
+			}
+		}
+
+		if sleepErr := sleep(r.Context(), wait); sleepErr != nil {
+			return err
+		}
+	}
 }
 
-func (c *Client) do(r *http.Request, successCode int, v any) error {
-	r.Header.Add("Accept", "application/json")
+// retryUnauthorized refreshes the credentials the request was rejected with and sends it again. The
+// original response and error are returned if the body cannot be rewound, and the error of the refresh
+// is added to the original one if it fails.
+func (c *Client) retryUnauthorized(ref refresher, req *http.Request, successCode int, v any, resp *http.Response, respErr error) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, respErr
+	}
 
-	resp, err := c.httpClient.Do(r)
+	err := ref.refresh(req)
 	if err != nil {
-		return fmt.Errorf("failed HTTP request to Coinbase API: %w", err)
+		return resp, errors.Join(respErr, &AuthenticationError{Err: err})
 	}
 
-	defer resp.Body.Close()
+	retry := req.Clone(req.Context())
 
-	if resp.StatusCode != successCode {
-		return handleRequestError(resp)
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return resp, respErr
+		}
+	}
+
+	err = c.authenticator.Authenticate(retry)
+	if err != nil {
+		return nil, &AuthenticationError{Err: err}
+	}
+
+	return c.do(retry, successCode, v)
+}
+
+// do sends the request and decodes the response into v. The response is returned along with
+// any error so the caller can inspect its status code and headers. Its body is already read
+// and closed, but replaced with a copy that can be read again.
+func (c *Client) do(r *http.Request, successCode int, v any) (*http.Response, error) {
+	r.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed HTTP request to Coinbase API: %w", err)
 	}
 
 	buf, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return resp, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(buf))
+
+	if resp.StatusCode != successCode {
+		err = handleRequestError(resp)
+		resp.Body = io.NopCloser(bytes.NewReader(buf))
+
+		return resp, err
 	}
 
 	err = json.Unmarshal(buf, v)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal HTTP response '%s' into '%T': %w", buf, v, err)
+		return resp, fmt.Errorf("failed to unmarshal HTTP response '%s' into '%T': %w", buf, v, err)
 	}
 
-	return nil
+	return resp, nil
 }
 
 func (c *Client) get(ctx context.Context, url string, params any, v any) error {
@@ -118,7 +292,7 @@ func (c *Client) post(ctx context.Context, url string, body io.Reader, v any) er
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return &notSentError{err: fmt.Errorf("failed to create HTTP request: %w", err)}
 	}
 
 	err = c.doWithAuthentication(req, http.StatusOK, v)
@@ -129,6 +303,11 @@ func (c *Client) post(ctx context.Context, url string, body io.Reader, v any) er
 	return nil
 }
 
+// postIdempotent sends a POST request that Coinbase deduplicates, so it can be retried safely.
+func (c *Client) postIdempotent(ctx context.Context, url string, body io.Reader, v any) error {
+	return c.post(withIdempotent(ctx), url, body, v)
+}
+
 func (c *Client) put(ctx context.Context, url string, body io.Reader, v any) error {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
@@ -0,0 +1,112 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// The services of the Client are described by interfaces so code using them can depend on the interface
+// and be tested with a mock, such as the ones of the coinbasemock package, instead of a Coinbase server.
+var (
+	_ Accounts       = (*AccountService)(nil)
+	_ Converts       = (*ConvertsService)(nil)
+	_ Fees           = (*FeesService)(nil)
+	_ Futures        = (*FuturesService)(nil)
+	_ Orders         = (*OrdersService)(nil)
+	_ PaymentMethods = (*PaymentMethodsService)(nil)
+	_ Portfolios     = (*PortfoliosService)(nil)
+	_ Products       = (*ProductsService)(nil)
+	_ Public         = (*PublicService)(nil)
+)
+
+// Accounts is the interface of AccountService, the client of the Accounts API.
+type Accounts interface {
+	All(ctx context.Context, options *AccountListOptions, opts ...PagerOption) *Pager[Account]
+	Get(ctx context.Context, id string) (*Account, error)
+	List(ctx context.Context, options *AccountListOptions) (*ListAccountsResponse, error)
+}
+
+// Converts is the interface of ConvertsService, the client of the Converts API.
+type Converts interface {
+	Commit(ctx context.Context, tradeID string, options CommitConvertTradeOptions) (*ConvertTrade, error)
+	CreateQuote(ctx context.Context, options CreateConvertQuoteOptions) (*ConvertTrade, error)
+	GetTrade(ctx context.Context, tradeID string, options GetConvertTradeOptions) (*ConvertTrade, error)
+}
+
+// Fees is the interface of FeesService, the client of the Fees API.
+type Fees interface {
+	GetTransactionsSummary(ctx context.Context, options *GetTransactionsSummaryOptions) (*GetTransactionsSummaryResponse, error)
+}
+
+// Futures is the interface of FuturesService, the client of the Futures API.
+type Futures interface {
+	CancelPendingSweep(ctx context.Context) (bool, error)
+	GetBalanceSummary(ctx context.Context) (*BalanceSummary, error)
+	GetPosition(ctx context.Context, id string) (*FuturesPosition, error)
+	ListPositions(ctx context.Context) ([]FuturesPosition, error)
+	ListSweeps(ctx context.Context) ([]FuturesSweep, error)
+	ScheduleSweep(ctx context.Context, options ScheduleSweepOptions) (*ScheduleSweepResponse, error)
+}
+
+// Orders is the interface of OrdersService, the client of the Orders API.
+type Orders interface {
+	All(ctx context.Context, options *ListOrdersOptions, opts ...PagerOption) *Pager[Order]
+	AllFills(ctx context.Context, options *ListOrderFillsOptions, opts ...PagerOption) *Pager[Fill]
+	Cancel(ctx context.Context, ids ...string) ([]CancelledOrder, error)
+	Create(ctx context.Context, options CreateOrderOptions) (*CreateOrderResponse, error)
+	Edit(ctx context.Context, options EditOrderOptions) (*EditOrderResponse, error)
+	EditPreview(ctx context.Context, options EditOrderOptions) (*EditOrderResponse, error)
+	Get(ctx context.Context, id string) (*Order, error)
+	List(ctx context.Context, options *ListOrdersOptions) (*ListOrdersResponse, error)
+	ListFills(ctx context.Context, options *ListOrderFillsOptions) (*ListFillsResponse, error)
+	Place(ctx context.Context, options CreateOrderOptions, opts ...PlaceOrderOption) (*PlaceOrderResult, error)
+	Preview(ctx context.Context, options CreateOrderOptions) (*PreviewOrderResponse, error)
+}
+
+// PaymentMethods is the interface of PaymentMethodsService, the client of the Payment Methods API.
+type PaymentMethods interface {
+	Get(ctx context.Context, id string) (*PaymentMethod, error)
+	List(ctx context.Context) ([]PaymentMethod, error)
+}
+
+// Portfolios is the interface of PortfoliosService, the client of the Portfolios API.
+type Portfolios interface {
+	Allocate(ctx context.Context, options AllocatePortfolioOptions) error
+	Create(ctx context.Context, name string) (*Portfolio, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Edit(ctx context.Context, id uuid.UUID, options EditPortfolioOptions) (*Portfolio, error)
+	GetPortfolioBreakdown(ctx context.Context, id string) (*PortfolioBreakdown, error)
+	List(ctx context.Context, options *ListPortfoliosOptions) (*ListPortfoliosResponse, error)
+	MoveFunds(ctx context.Context, options PortfolioMoveFundsOptions) (*PorfoliosMoveFundsResponse, error)
+}
+
+// Products is the interface of ProductsService, the client of the Products API.
+type Products interface {
+	Get(ctx context.Context, id string) (*Product, error)
+	GetBestBidAsk(ctx context.Context, ids ...string) (*GetBestBidAskResponse, error)
+	GetCandleHistory(ctx context.Context, options GetCandleHistoryOptions) (*CandleHistory, error)
+	GetMarketTrades(ctx context.Context, options GetMarketTradeOptions) (*GetMarketTradesResponse, error)
+	GetProductBook(ctx context.Context, id string, limit *int) (*PriceBook, error)
+	GetProductCandles(ctx context.Context, options GetProductCandlesOptions) ([]Candles, error)
+	List(ctx context.Context, options *ListProductsOptions) ([]Product, error)
+	MaintainOrderBook(ctx context.Context, productID string, options *OrderBookOptions) (*OrderBook, error)
+}
+
+// Public is the interface of PublicService, the client of the Public API.
+type Public interface {
+	GetCandleHistory(ctx context.Context, options GetCandleHistoryOptions) (*CandleHistory, error)
+	GetMarketTrades(ctx context.Context, options GetMarketTradeOptions) (*GetMarketTradesResponse, error)
+	GetProduct(ctx context.Context, id string) (*Product, error)
+	GetProductBook(ctx context.Context, opts GetProductBookOptions) (*PriceBook, error)
+	GetProductCandles(ctx context.Context, options GetProductCandlesOptions) ([]Candles, error)
+	GetServerTime(ctx context.Context) (*CoinbaseServerTime, error)
+	ListProducts(ctx context.Context, options *ListProductsOptions) ([]Product, error)
+}
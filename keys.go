@@ -0,0 +1,106 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by LoadAPIKeyFromEnv.
+const (
+	EnvAPIKeyName       = "COINBASE_API_KEY_NAME"    // Name of the cloud API key.
+	EnvAPIKeyPrivateKey = "COINBASE_API_PRIVATE_KEY" // Private key of the cloud API key.
+	EnvAPIKeyFile       = "COINBASE_API_KEY_FILE"    // Path of the CDP JSON key file, used instead of the two above.
+)
+
+// redacted replaces secrets when formatting values that hold them.
+const redacted = "[REDACTED]"
+
+// ErrAPIKeyNotFound - the environment does not configure a cloud API key.
+var ErrAPIKeyNotFound = errors.New("cloud API key not found in the environment")
+
+// APIKey is a cloud API key, as downloaded from the Coinbase Developer Platform. The private key is
+// redacted when the key is formatted.
+type APIKey struct {
+	Name       string `json:"name"`       // Name of the key, organizations/{org_id}/apiKeys/{key_id} or the ID of newer keys.
+	PrivateKey string `json:"privateKey"` // PEM encoded EC key or base64 encoded Ed25519 key, see ParsePrivateKey.
+}
+
+func (k APIKey) String() string {
+	return fmt.Sprintf("{Name: %s, PrivateKey: %s}", k.Name, redacted)
+}
+
+func (k APIKey) GoString() string {
+	return fmt.Sprintf("coinbase.APIKey{Name: %q, PrivateKey: %q}", k.Name, redacted)
+}
+
+// LoadAPIKeyFile reads the JSON key file downloaded from the Coinbase Developer Platform. Ed25519 key
+// files name the key with an id field instead of name, both are accepted.
+func LoadAPIKeyFile(path string) (APIKey, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	var file struct {
+		APIKey
+		ID string `json:"id"`
+	}
+
+	// Decoding errors may quote the file, which holds the private key.
+	if json.Unmarshal(buf, &file) != nil {
+		return APIKey{}, fmt.Errorf("failed to decode API key file '%s': not a CDP JSON key file", path)
+	}
+
+	key := file.APIKey
+
+	if key.Name == "" {
+		key.Name = file.ID
+	}
+
+	if key.Name == "" || key.PrivateKey == "" {
+		return APIKey{}, fmt.Errorf("API key file '%s' is missing the name or private key", path)
+	}
+
+	return key, nil
+}
+
+// LoadAPIKeyFromEnv reads the cloud API key from the file at COINBASE_API_KEY_FILE if it is set,
+// otherwise from COINBASE_API_KEY_NAME and COINBASE_API_PRIVATE_KEY. ErrAPIKeyNotFound is returned
+// if neither are set.
+func LoadAPIKeyFromEnv() (APIKey, error) {
+	if path := os.Getenv(EnvAPIKeyFile); path != "" {
+		return LoadAPIKeyFile(path)
+	}
+
+	key := APIKey{
+		Name:       strings.TrimSpace(os.Getenv(EnvAPIKeyName)),
+		PrivateKey: os.Getenv(EnvAPIKeyPrivateKey),
+	}
+
+	switch {
+	case key.Name == "" && key.PrivateKey == "":
+		return APIKey{}, ErrAPIKeyNotFound
+	case key.Name == "":
+		return APIKey{}, fmt.Errorf("%w: %s is not set", ErrAPIKeyNotFound, EnvAPIKeyName)
+	case key.PrivateKey == "":
+		return APIKey{}, fmt.Errorf("%w: %s is not set", ErrAPIKeyNotFound, EnvAPIKeyPrivateKey)
+	}
+
+	return key, nil
+}
+
+// NewWithAPIKey creates a new Coinbase Advanced Trade REST API client, using the cloud API key for
+// authentication. See LoadAPIKeyFile and LoadAPIKeyFromEnv.
+func NewWithAPIKey(key APIKey, opts ...option) (*Client, error) {
+	return NewWithCloud(key.Name, key.PrivateKey, opts...)
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Call is a single attempt of a request to the Advanced Trade REST API, as seen by middleware.
+type Call struct {
+	Endpoint Endpoint      // Name of the endpoint, empty if the path is not a known endpoint.
+	Request  *http.Request // Request about to be sent, authentication is only added after all middleware ran.
+	Attempt  int           // Number of the attempt starting at 1, only greater with WithRetryPolicy.
+
+	// Set once the request was sent.
+	Response *http.Response // Response to the request, nil if none was received. Its body was read by the client but can be read again.
+	Err      error          // Error of the attempt, a *CoinbaseError if Coinbase returned one or an *AuthenticationError if signing failed.
+	Start    time.Time      // When the attempt started, before authentication.
+	Duration time.Duration  // Time spent authenticating, sending the request and reading the response.
+}
+
+// Handler sends a call and returns its error, which is what the caller of the client method sees.
+type Handler func(call *Call) error
+
+// Middleware wraps every call made by the client. It may change the request before calling next,
+// inspect the response and error after, or return without calling next to fail the call.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware wraps every request made by the client with the middleware, to add logging,
+// metrics, headers or auditing. The first middleware is the outermost. Middleware runs once for
+// every attempt of a retried request, after the rate limiter allowed it.
+func WithMiddleware(middleware ...Middleware) func(*Client) {
+	return func(c *Client) {
+		for _, m := range middleware {
+			if m != nil {
+				c.middleware = append(c.middleware, m)
+			}
+		}
+	}
+}
+
+// chain wraps the handler with the middleware of the client.
+func (c *Client) chain(h Handler) Handler {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+
+	return h
+}
+
+// Endpoint is the name of an Advanced Trade REST API endpoint, taken from the Coinbase API reference.
+type Endpoint string
+
+const (
+	EndpointListAccounts              Endpoint = "ListAccounts"
+	EndpointGetAccount                Endpoint = "GetAccount"
+	EndpointCreateConvertQuote        Endpoint = "CreateConvertQuote"
+	EndpointCommitConvertTrade        Endpoint = "CommitConvertTrade"
+	EndpointGetConvertTrade           Endpoint = "GetConvertTrade"
+	EndpointGetTransactionSummary     Endpoint = "GetTransactionSummary"
+	EndpointGetFuturesBalanceSummary  Endpoint = "GetFuturesBalanceSummary"
+	EndpointListFuturesPositions      Endpoint = "ListFuturesPositions"
+	EndpointGetFuturesPosition        Endpoint = "GetFuturesPosition"
+	EndpointListFuturesSweeps         Endpoint = "ListFuturesSweeps"
+	EndpointScheduleFuturesSweep      Endpoint = "ScheduleFuturesSweep"
+	EndpointCancelPendingFuturesSweep Endpoint = "CancelPendingFuturesSweep"
+	EndpointCreateOrder               Endpoint = "CreateOrder"
+	EndpointPreviewOrder              Endpoint = "PreviewOrder"
+	EndpointEditOrder                 Endpoint = "EditOrder"
+	EndpointEditOrderPreview          Endpoint = "EditOrderPreview"
+	EndpointCancelOrders              Endpoint = "CancelOrders"
+	EndpointListOrders                Endpoint = "ListOrders"
+	EndpointListFills                 Endpoint = "ListFills"
+	EndpointGetOrder                  Endpoint = "GetOrder"
+	EndpointListPaymentMethods        Endpoint = "ListPaymentMethods"
+	EndpointGetPaymentMethod          Endpoint = "GetPaymentMethod"
+	EndpointAllocatePortfolio         Endpoint = "AllocatePortfolio"
+	EndpointListPortfolios            Endpoint = "ListPortfolios"
+	EndpointCreatePortfolio           Endpoint = "CreatePortfolio"
+	EndpointMovePortfolioFunds        Endpoint = "MovePortfolioFunds"
+	EndpointGetPortfolioBreakdown     Endpoint = "GetPortfolioBreakdown"
+	EndpointEditPortfolio             Endpoint = "EditPortfolio"
+	EndpointDeletePortfolio           Endpoint = "DeletePortfolio"
+	EndpointGetProductBook            Endpoint = "GetProductBook"
+	EndpointGetBestBidAsk             Endpoint = "GetBestBidAsk"
+	EndpointListProducts              Endpoint = "ListProducts"
+	EndpointGetProduct                Endpoint = "GetProduct"
+	EndpointGetProductCandles         Endpoint = "GetProductCandles"
+	EndpointGetMarketTrades           Endpoint = "GetMarketTrades"
+	EndpointListPublicProducts        Endpoint = "ListPublicProducts"
+	EndpointGetPublicProduct          Endpoint = "GetPublicProduct"
+	EndpointGetPublicProductCandles   Endpoint = "GetPublicProductCandles"
+	EndpointGetPublicMarketTrades     Endpoint = "GetPublicMarketTrades"
+	EndpointGetPublicProductBook      Endpoint = "GetPublicProductBook"
+	EndpointGetServerTime             Endpoint = "GetServerTime"
+)
+
+const brokeragePath = "/api/v3/brokerage/"
+
+// route maps a method and path, relative to the brokerage API, to an endpoint. A * matches any
+// single path segment.
+type route struct {
+	method   string
+	path     string
+	endpoint Endpoint
+}
+
+var routes = []route{
+	{http.MethodGet, "accounts", EndpointListAccounts},
+	{http.MethodGet, "accounts/*", EndpointGetAccount},
+	{http.MethodPost, "convert/quote", EndpointCreateConvertQuote},
+	{http.MethodPost, "convert/trade/*", EndpointCommitConvertTrade},
+	{http.MethodGet, "convert/trade/*", EndpointGetConvertTrade},
+	{http.MethodGet, "transaction_summary", EndpointGetTransactionSummary},
+	{http.MethodGet, "cfm/balance_summary", EndpointGetFuturesBalanceSummary},
+	{http.MethodGet, "cfm/positions", EndpointListFuturesPositions},
+	{http.MethodGet, "cfm/positions/*", EndpointGetFuturesPosition},
+	{http.MethodGet, "cfm/sweeps", EndpointListFuturesSweeps},
+	{http.MethodPost, "cfm/sweeps/schedule", EndpointScheduleFuturesSweep},
+	{http.MethodDelete, "cfm/sweeps", EndpointCancelPendingFuturesSweep},
+	{http.MethodPost, "orders", EndpointCreateOrder},
+	{http.MethodPost, "orders/preview", EndpointPreviewOrder},
+	{http.MethodPost, "orders/edit", EndpointEditOrder},
+	{http.MethodPost, "orders/edit_preview", EndpointEditOrderPreview},
+	{http.MethodPost, "orders/batch_cancel", EndpointCancelOrders},
+	{http.MethodGet, "orders/historical/batch", EndpointListOrders},
+	{http.MethodGet, "orders/historical/fills", EndpointListFills},
+	{http.MethodGet, "orders/historical/*", EndpointGetOrder},
+	{http.MethodGet, "payment_methods", EndpointListPaymentMethods},
+	{http.MethodGet, "payment_methods/*", EndpointGetPaymentMethod},
+	{http.MethodPost, "intx/allocate", EndpointAllocatePortfolio},
+	{http.MethodGet, "portfolios", EndpointListPortfolios},
+	{http.MethodPost, "portfolios", EndpointCreatePortfolio},
+	{http.MethodPost, "portfolios/move_funds", EndpointMovePortfolioFunds},
+	{http.MethodGet, "portfolios/*", EndpointGetPortfolioBreakdown},
+	{http.MethodPut, "portfolios/*", EndpointEditPortfolio},
+	{http.MethodDelete, "portfolios/*", EndpointDeletePortfolio},
+	{http.MethodGet, "product_book", EndpointGetProductBook},
+	{http.MethodGet, "best_bid_ask", EndpointGetBestBidAsk},
+	{http.MethodGet, "products", EndpointListProducts},
+	{http.MethodGet, "products/*", EndpointGetProduct},
+	{http.MethodGet, "products/*/candles", EndpointGetProductCandles},
+	{http.MethodGet, "products/*/ticker", EndpointGetMarketTrades},
+	{http.MethodGet, "market/products", EndpointListPublicProducts},
+	{http.MethodGet, "market/products/*", EndpointGetPublicProduct},
+	{http.MethodGet, "market/products/*/candles", EndpointGetPublicProductCandles},
+	{http.MethodGet, "market/products/*/ticker", EndpointGetPublicMarketTrades},
+	{http.MethodGet, "market/product_book", EndpointGetPublicProductBook},
+	{http.MethodGet, "time", EndpointGetServerTime},
+}
+
+// endpointOf returns the endpoint the request is sent to, or an empty string if it is unknown.
+// The base URL may have a path of its own, so only the part after the brokerage prefix is matched.
+func endpointOf(r *http.Request) Endpoint {
+	i := strings.Index(r.URL.Path, brokeragePath)
+	if i < 0 {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path[i+len(brokeragePath):], "/"), "/")
+
+	for _, rt := range routes {
+		if rt.method == r.Method && matchSegments(strings.Split(rt.path, "/"), segments) {
+			return rt.endpoint
+		}
+	}
+
+	return ""
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if p != "*" && p != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
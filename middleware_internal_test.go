@@ -0,0 +1,55 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEndpointOf(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   Endpoint
+	}{
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/accounts", EndpointListAccounts},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/accounts/8bfc20d7-f7c6-4422-bf07-8243ca4169fe", EndpointGetAccount},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/accounts/?limit=1", EndpointListAccounts},
+		{http.MethodPost, "https://api.coinbase.com/api/v3/brokerage/orders", EndpointCreateOrder},
+		{http.MethodPost, "https://api.coinbase.com/api/v3/brokerage/orders/batch_cancel", EndpointCancelOrders},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/orders/historical/batch?product_id=BTC-USD", EndpointListOrders},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/orders/historical/fills", EndpointListFills},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/orders/historical/abc", EndpointGetOrder},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/portfolios/abc", EndpointGetPortfolioBreakdown},
+		{http.MethodPut, "https://api.coinbase.com/api/v3/brokerage/portfolios/abc", EndpointEditPortfolio},
+		{http.MethodDelete, "https://api.coinbase.com/api/v3/brokerage/portfolios/abc", EndpointDeletePortfolio},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/products/BTC-USD", EndpointGetProduct},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/products/BTC-USD/candles", EndpointGetProductCandles},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/market/products/BTC-USD/ticker", EndpointGetPublicMarketTrades},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/time", EndpointGetServerTime},
+		{http.MethodGet, "http://127.0.0.1:8080/proxy/api/v3/brokerage/time", EndpointGetServerTime},
+		{http.MethodPost, "https://api.coinbase.com/api/v3/brokerage/accounts", ""},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/unknown", ""},
+		{http.MethodGet, "https://api.coinbase.com/api/v3/brokerage/products/BTC-USD/candles/extra", ""},
+		{http.MethodGet, "https://api.coinbase.com/v2/accounts", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			r, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+
+			if got := endpointOf(r); got != tt.want {
+				t.Fatalf("endpoint is '%s', want '%s'", got, tt.want)
+			}
+		})
+	}
+}
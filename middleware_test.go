@@ -0,0 +1,140 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// tracing records the calls it sees under the name, before and after they are sent.
+func tracing(name string, trace *[]string, calls *[]coinbase.Call) coinbase.Middleware {
+	return func(next coinbase.Handler) coinbase.Handler {
+		return func(call *coinbase.Call) error {
+			*trace = append(*trace, name+" before")
+
+			err := next(call)
+
+			*trace = append(*trace, name+" after")
+
+			if calls != nil {
+				*calls = append(*calls, *call)
+			}
+
+			return err
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	srv := newScriptedServer(t, scriptedResponse{status: http.StatusServiceUnavailable}, scriptedResponse{status: http.StatusOK})
+
+	var (
+		trace []string
+		calls []coinbase.Call
+	)
+
+	header := func(next coinbase.Handler) coinbase.Handler {
+		return func(call *coinbase.Call) error {
+			call.Request.Header.Set("X-Test", "1")
+
+			return next(call)
+		}
+	}
+
+	seen := make(chan string, 2)
+
+	client := coinbase.NewWithLegacy("key", "secret", coinbase.WithBaseURL(srv.URL))
+	coinbase.WithRetryPolicy(fastRetries)(client)
+	coinbase.WithMiddleware(tracing("outer", &trace, nil), nil, tracing("inner", &trace, &calls), header, func(next coinbase.Handler) coinbase.Handler {
+		return func(call *coinbase.Call) error {
+			// Middleware runs before authentication.
+			seen <- call.Request.Header.Get("CB-ACCESS-SIGN")
+
+			return next(call)
+		}
+	})(client)
+
+	product, err := client.Products.Get(context.Background(), "BTC-USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if product.ID != "BTC-USD" {
+		t.Fatalf("product is '%s', want 'BTC-USD'", product.ID)
+	}
+
+	want := []string{"outer before", "inner before", "inner after", "outer after", "outer before", "inner before", "inner after", "outer after"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Fatalf("middleware ran in order %v, want %v", trace, want)
+	}
+
+	for i := 0; i < 2; i++ {
+		if auth := <-seen; auth != "" {
+			t.Fatalf("middleware saw the credentials %q", auth)
+		}
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("%d calls, want 2", len(calls))
+	}
+
+	for i, call := range calls {
+		if call.Endpoint != coinbase.EndpointGetProduct || call.Attempt != i+1 {
+			t.Fatalf("call %d is attempt %d of %s, want attempt %d of %s", i, call.Attempt, call.Endpoint, i+1, coinbase.EndpointGetProduct)
+		}
+
+		if call.Response == nil || call.Start.IsZero() || call.Duration <= 0 {
+			t.Fatalf("call %d was not completed: %+v", i, call)
+		}
+
+		if call.Request.Header.Get("X-Test") != "1" {
+			t.Fatalf("call %d lost the header set by middleware", i)
+		}
+	}
+
+	var cbError *coinbase.CoinbaseError
+	if !errors.As(calls[0].Err, &cbError) || cbError.StatusCode != http.StatusServiceUnavailable || calls[0].Response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("first call failed with %v, want a 503", calls[0].Err)
+	}
+
+	// The body of the response can be read again by middleware.
+	b, err := io.ReadAll(calls[1].Response.Body)
+	if err != nil || string(b) != `{"product_id":"BTC-USD"}` {
+		t.Fatalf("response body is %q, %v", b, err)
+	}
+}
+
+func TestMiddlewareFailsCall(t *testing.T) {
+	srv := newScriptedServer(t, scriptedResponse{status: http.StatusOK})
+	errBlocked := errors.New("blocked")
+
+	client := coinbase.NewClient(coinbase.WithBaseURL(srv.URL))
+	coinbase.WithRetryPolicy(fastRetries)(client)
+	coinbase.WithMiddleware(func(next coinbase.Handler) coinbase.Handler {
+		return func(call *coinbase.Call) error {
+			return errBlocked
+		}
+	})(client)
+
+	_, err := client.Public.GetServerTime(context.Background())
+	if !errors.Is(err, errBlocked) {
+		t.Fatalf("error is %v, want %v", err, errBlocked)
+	}
+
+	// A call failed by middleware was not sent, so it is neither sent nor retried.
+	if srv.count() != 0 {
+		t.Fatalf("server received %d requests, want 0", srv.count())
+	}
+}
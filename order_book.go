@@ -0,0 +1,587 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultOrderBookPollInterval = time.Second
+
+// orderBookStalePolls is the number of poll intervals the book stays synced after polls start failing.
+const orderBookStalePolls = 3
+
+// ErrOrderBookNotSynced - the order book has not received a snapshot since it was created or last invalidated.
+var ErrOrderBookNotSynced = errors.New("order book is not synced")
+
+type bookLevel struct {
+	price     *big.Rat
+	size      *big.Rat
+	priceText string // Original representation, returned to callers so no precision is lost or added.
+	sizeText  string
+}
+
+func (l bookLevel) bidAsk() BidAsk {
+	return BidAsk{Price: String(l.priceText), Size: String(l.sizeText)}
+}
+
+// OrderBook is a local copy of the order book of a single product, kept sorted by price.
+// It can be read safely from many goroutines while it is being updated.
+type OrderBook struct {
+	productID string
+
+	mu      sync.RWMutex
+	bids    []bookLevel // Best (highest) price first.
+	asks    []bookLevel // Best (lowest) price first.
+	synced  bool
+	err     error // Why the book was invalidated, if known.
+	updated time.Time
+}
+
+// NewOrderBook creates an empty order book for the product. The book must receive a snapshot,
+// from ApplyLevel2 or ApplyPriceBook, before it is usable.
+func NewOrderBook(productID string) *OrderBook {
+	return &OrderBook{productID: productID}
+}
+
+// ProductID returns the product the book belongs to.
+func (b *OrderBook) ProductID() string {
+	return b.productID
+}
+
+// Synced reports whether the book has received a snapshot since it was created or last invalidated.
+func (b *OrderBook) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.synced
+}
+
+// Updated returns the time of the last change applied to the book.
+func (b *OrderBook) Updated() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.updated
+}
+
+// Err returns why the book is out of sync, nil while it is synced. The error always matches
+// ErrOrderBookNotSynced, and wraps the error that invalidated the book when it was maintained
+// by MaintainOrderBook.
+func (b *OrderBook) Err() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	switch {
+	case b.synced:
+		return nil
+	case b.err != nil:
+		return fmt.Errorf("%w: %w", ErrOrderBookNotSynced, b.err)
+	default:
+		return ErrOrderBookNotSynced
+	}
+}
+
+// Invalidate marks the book as out of sync, it must receive a new snapshot before it is updated again.
+func (b *OrderBook) Invalidate() {
+	b.invalidate(nil)
+}
+
+// invalidate marks the book as out of sync because of the error, if nil the error that invalidated
+// it before is kept.
+func (b *OrderBook) invalidate(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.synced = false
+
+	if err != nil {
+		b.err = err
+	}
+}
+
+// ApplyLevel2 applies a level2 event from the WebSocket feed. A snapshot replaces the whole book,
+// updates are rejected with ErrOrderBookNotSynced until a snapshot has been applied.
+func (b *OrderBook) ApplyLevel2(event Level2Event) error {
+	if event.ProductID != b.productID {
+		return fmt.Errorf("level2 event for product '%s' applied to order book for '%s'", event.ProductID, b.productID)
+	}
+
+	// Parse everything up front so a malformed event never leaves the book half updated.
+	levels := make([]bookLevel, len(event.Updates))
+	for i, u := range event.Updates {
+		level, err := parseBookLevel(u.PriceLevel, u.NewQuantity)
+		if err != nil {
+			return fmt.Errorf("invalid level2 update for product '%s': %w", b.productID, err)
+		}
+
+		levels[i] = level
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.Type == StreamEventTypeSnapshot {
+		b.bids, b.asks = nil, nil
+		b.synced = true
+		b.err = nil
+	} else if !b.synced {
+		return ErrOrderBookNotSynced
+	}
+
+	for i, u := range event.Updates {
+		if u.Side == Level2SideBid {
+			b.bids = setLevel(b.bids, levels[i], true)
+		} else {
+			b.asks = setLevel(b.asks, levels[i], false)
+		}
+	}
+
+	b.updated = time.Now()
+
+	return nil
+}
+
+// ApplyPriceBook replaces the contents of the book with a snapshot from the REST API.
+func (b *OrderBook) ApplyPriceBook(book PriceBook) error {
+	if book.ProductID != "" && book.ProductID != b.productID {
+		return fmt.Errorf("price book for product '%s' applied to order book for '%s'", book.ProductID, b.productID)
+	}
+
+	bids, err := parseBookSide(book.Bids, true)
+	if err != nil {
+		return fmt.Errorf("invalid bids for product '%s': %w", b.productID, err)
+	}
+
+	asks, err := parseBookSide(book.Asks, false)
+	if err != nil {
+		return fmt.Errorf("invalid asks for product '%s': %w", b.productID, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids, b.asks = bids, asks
+	b.synced = true
+	b.err = nil
+	b.updated = time.Now()
+
+	if book.Time != nil {
+		b.updated = *book.Time
+	}
+
+	return nil
+}
+
+// BestBid returns the highest bid, false if there are no bids.
+func (b *OrderBook) BestBid() (BidAsk, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.bids) == 0 {
+		return BidAsk{}, false
+	}
+
+	return b.bids[0].bidAsk(), true
+}
+
+// BestAsk returns the lowest ask, false if there are no asks.
+func (b *OrderBook) BestAsk() (BidAsk, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.asks) == 0 {
+		return BidAsk{}, false
+	}
+
+	return b.asks[0].bidAsk(), true
+}
+
+// Depth returns up to n of the best price levels on each side of the book, best price first.
+func (b *OrderBook) Depth(n int) (bids []BidAsk, asks []BidAsk) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return copyLevels(b.bids, n), copyLevels(b.asks, n)
+}
+
+// CumulativeSize returns the total size resting on one side of the book at prices at least as good as
+// the given price, that is bids at or above the price and offers at or below it.
+func (b *OrderBook) CumulativeSize(side Level2Side, price string) (string, error) {
+	limit, ok := new(big.Rat).SetString(price)
+	if !ok {
+		return "", fmt.Errorf("invalid price '%s'", price)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	levels, descending := b.asks, false
+	if side == Level2SideBid {
+		levels, descending = b.bids, true
+	}
+
+	total := new(big.Rat)
+
+	for _, l := range levels {
+		cmp := l.price.Cmp(limit)
+		if (descending && cmp < 0) || (!descending && cmp > 0) {
+			break
+		}
+
+		total.Add(total, l.size)
+	}
+
+	return formatRat(total), nil
+}
+
+// Snapshot returns a consistent copy of the whole book.
+func (b *OrderBook) Snapshot() PriceBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return PriceBook{
+		ProductID: b.productID,
+		Bids:      copyLevels(b.bids, len(b.bids)),
+		Asks:      copyLevels(b.asks, len(b.asks)),
+		Time:      Time(b.updated),
+	}
+}
+
+func parseBookLevel(price string, size string) (bookLevel, error) {
+	p, ok := new(big.Rat).SetString(price)
+	if !ok {
+		return bookLevel{}, fmt.Errorf("invalid price '%s'", price)
+	}
+
+	s, ok := new(big.Rat).SetString(size)
+	if !ok {
+		return bookLevel{}, fmt.Errorf("invalid size '%s'", size)
+	}
+
+	return bookLevel{price: p, size: s, priceText: price, sizeText: size}, nil
+}
+
+func parseBookSide(entries []BidAsk, descending bool) ([]bookLevel, error) {
+	levels := make([]bookLevel, 0, len(entries))
+
+	for _, e := range entries {
+		if e.Price == nil || e.Size == nil {
+			continue
+		}
+
+		level, err := parseBookLevel(*e.Price, *e.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		levels = setLevel(levels, level, descending)
+	}
+
+	return levels, nil
+}
+
+// setLevel inserts, replaces or removes (if the size is zero) the price level, keeping the levels sorted.
+func setLevel(levels []bookLevel, level bookLevel, descending bool) []bookLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].price.Cmp(level.price) <= 0
+		}
+
+		return levels[i].price.Cmp(level.price) >= 0
+	})
+
+	exists := i < len(levels) && levels[i].price.Cmp(level.price) == 0
+
+	switch {
+	case level.size.Sign() == 0 && exists:
+		return append(levels[:i], levels[i+1:]...)
+	case level.size.Sign() == 0:
+		return levels
+	case exists:
+		levels[i] = level
+		return levels
+	default:
+		levels = append(levels, bookLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = level
+
+		return levels
+	}
+}
+
+func copyLevels(levels []bookLevel, n int) []BidAsk {
+	if n > len(levels) {
+		n = len(levels)
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	out := make([]BidAsk, n)
+	for i := range out {
+		out[i] = levels[i].bidAsk()
+	}
+
+	return out
+}
+
+type OrderBookOptions struct {
+	Poll         bool          // Maintain the book by polling GetProductBook instead of the WebSocket feed.
+	PollInterval time.Duration // Interval between polls, defaults to one second. If set the book falls back to polling when the feed is unavailable.
+	PollLimit    *int          // Number of price levels to request per side when polling, defaults to the full book.
+}
+
+// MaintainOrderBook builds a local order book for the product and keeps it up to date in the background
+// until the context is cancelled.
+//
+// By default the book is driven by the level2 channel of the WebSocket feed. When a sequence gap is detected,
+// or an update arrives while the book is out of sync, the book is invalidated and the channel resubscribed,
+// which makes Coinbase send a fresh snapshot. If the connection drops the book is invalidated until a snapshot
+// is received after reconnecting, or polled in the meantime if PollInterval is set. A polled book stays synced
+// with the last successful poll for a few intervals after polls start failing, then it is invalidated. Err
+// reports why the book is out of sync.
+//
+// The call returns once the book has received its first snapshot.
+func (s *ProductsService) MaintainOrderBook(ctx context.Context, productID string, options *OrderBookOptions) (*OrderBook, error) {
+	if options == nil {
+		options = &OrderBookOptions{}
+	}
+
+	book := NewOrderBook(productID)
+
+	if !options.Poll {
+		sub := Subscription{Channel: ChannelLevel2, ProductIDs: []string{productID}}
+
+		stream, err := s.client.Stream.Connect(ctx, StreamOptions{
+			Subscriptions: []Subscription{{Channel: ChannelHeartbeats}, sub},
+		})
+		if err == nil {
+			err = s.followStream(ctx, book, stream, sub, options)
+			if err != nil {
+				stream.Close()
+				return nil, err
+			}
+
+			return book, nil
+		}
+
+		if options.PollInterval <= 0 {
+			return nil, fmt.Errorf("failed to maintain order book for product '%s': %w", productID, err)
+		}
+	}
+
+	err := s.pollOrderBook(ctx, book, options.PollLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.pollOrderBookEvery(ctx, book, options)
+
+	return book, nil
+}
+
+func (s *ProductsService) pollOrderBook(ctx context.Context, book *OrderBook, limit *int) error {
+	snapshot, err := s.GetProductBook(ctx, book.productID, limit)
+	if err != nil {
+		return err
+	}
+
+	return book.ApplyPriceBook(*snapshot)
+}
+
+// pollOrderBookEvery polls the book at the interval of the options until the context is done.
+func (s *ProductsService) pollOrderBookEvery(ctx context.Context, book *OrderBook, options *OrderBookOptions) {
+	ticker := time.NewTicker(pollInterval(options))
+	defer ticker.Stop()
+
+	p := &orderBookPoller{s: s, book: book, options: options, succeeded: time.Now()}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+// orderBookPoller polls a book and invalidates it once polls have failed for too long.
+type orderBookPoller struct {
+	s         *ProductsService
+	book      *OrderBook
+	options   *OrderBookOptions
+	succeeded time.Time // When the book was last polled successfully.
+}
+
+func (p *orderBookPoller) poll(ctx context.Context) {
+	err := p.s.pollOrderBook(ctx, p.book, p.options.PollLimit)
+	if err == nil {
+		p.succeeded = time.Now()
+		return
+	}
+
+	// Errors are usually transient, the book stays in sync with the last successful poll for a few intervals.
+	if time.Since(p.succeeded) >= orderBookStalePolls*pollInterval(p.options) {
+		p.book.invalidate(fmt.Errorf("failed to poll order book for product '%s': %w", p.book.productID, err))
+	}
+}
+
+func pollInterval(options *OrderBookOptions) time.Duration {
+	if options.PollInterval <= 0 {
+		return defaultOrderBookPollInterval
+	}
+
+	return options.PollInterval
+}
+
+// orderBookResyncTimeout is how long to wait for the snapshot after resubscribing before asking again.
+const orderBookResyncTimeout = 10 * time.Second
+
+// orderBookFeed applies the level2 channel of a stream to a book. Only accessed by the goroutine reading the stream.
+type orderBookFeed struct {
+	s       *ProductsService
+	book    *OrderBook
+	stream  *Stream
+	sub     Subscription
+	options *OrderBookOptions
+
+	resyncing time.Time // When the channel was last resubscribed, zero once the snapshot arrived.
+	poller    *orderBookPoller
+}
+
+// followStream waits for the first snapshot and then applies the feed to the book in the background.
+func (s *ProductsService) followStream(ctx context.Context, book *OrderBook, stream *Stream, sub Subscription, options *OrderBookOptions) error {
+	f := &orderBookFeed{s: s, book: book, stream: stream, sub: sub, options: options}
+	f.poller = &orderBookPoller{s: s, book: book, options: options}
+
+	for !book.Synced() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-stream.Messages():
+			if !ok {
+				return ErrStreamClosed
+			}
+
+			f.apply(ctx, msg)
+		}
+	}
+
+	go f.run(ctx)
+
+	return nil
+}
+
+func (f *orderBookFeed) run(ctx context.Context) {
+	defer f.stream.Close()
+
+	ticker := time.NewTicker(pollInterval(f.options))
+	defer ticker.Stop()
+
+	errs := f.stream.Errors()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Disconnect errors may have been dropped, the connection state is checked on every tick instead.
+			if !f.stream.Connected() {
+				f.disconnected(ctx)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+
+			// Errors and messages are read in no particular order, the disconnect may only be
+			// read after messages of the new connection.
+			var disconnected *StreamDisconnectedError
+			if errors.As(err, &disconnected) && !f.stream.Connected() {
+				f.book.invalidate(err)
+			}
+		case msg, ok := <-f.stream.Messages():
+			if !ok {
+				f.book.invalidate(ErrStreamClosed)
+
+				// The stream is gone for good, keep the book up to date by polling if allowed.
+				if f.options.PollInterval > 0 && ctx.Err() == nil {
+					f.s.pollOrderBookEvery(ctx, f.book, f.options)
+				}
+
+				return
+			}
+
+			f.apply(ctx, msg)
+		}
+	}
+}
+
+// disconnected keeps the book up to date by polling while the feed is down if allowed, or invalidates it.
+func (f *orderBookFeed) disconnected(ctx context.Context) {
+	if f.options.PollInterval <= 0 {
+		f.book.Invalidate()
+		return
+	}
+
+	f.poller.poll(ctx)
+}
+
+func (f *orderBookFeed) apply(ctx context.Context, msg *StreamMessage) {
+	if msg.Reconnected {
+		// Subscriptions were replayed, the snapshot is on its way.
+		f.book.Invalidate()
+		f.resyncing = time.Now()
+	}
+
+	if msg.Gap != nil {
+		f.resync(ctx, msg.Gap)
+	}
+
+	for _, event := range msg.Level2 {
+		if event.ProductID != f.book.productID {
+			continue
+		}
+
+		if event.Type == StreamEventTypeSnapshot {
+			f.resyncing = time.Time{}
+		}
+
+		// Updates arriving out of sync mean the snapshot was missed, or the book was invalidated after it arrived.
+		err := f.book.ApplyLevel2(event)
+		if errors.Is(err, ErrOrderBookNotSynced) {
+			f.resync(ctx, nil)
+		} else if err != nil {
+			f.resync(ctx, err)
+		}
+	}
+}
+
+// resync invalidates the book because of the error and resubscribes to the channel, unless a snapshot
+// was already requested.
+func (f *orderBookFeed) resync(ctx context.Context, err error) {
+	f.book.invalidate(err)
+
+	if !f.resyncing.IsZero() && time.Since(f.resyncing) < orderBookResyncTimeout {
+		return
+	}
+
+	if f.stream.Resubscribe(ctx, f.sub) == nil {
+		f.resyncing = time.Now()
+	}
+}
@@ -0,0 +1,408 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+func level2(typ coinbase.StreamEventType, updates ...string) coinbase.Level2Event {
+	event := coinbase.Level2Event{Type: typ, ProductID: "BTC-USD"}
+
+	for _, u := range updates {
+		var side, price, size string
+
+		fmt.Sscanf(u, "%s %s %s", &side, &price, &size)
+
+		event.Updates = append(event.Updates, coinbase.Level2Update{Side: coinbase.Level2Side(side), PriceLevel: price, NewQuantity: size})
+	}
+
+	return event
+}
+
+func levels(entries []coinbase.BidAsk) string {
+	s := ""
+
+	for _, e := range entries {
+		s += *e.Price + "x" + *e.Size + " "
+	}
+
+	return s
+}
+
+func TestOrderBookApplyLevel2(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []coinbase.Level2Event
+		err    error  // Expected error of the last event.
+		bids   string // Expected bids, best first.
+		asks   string // Expected asks, best first.
+	}{
+		{
+			name:   "snapshot is sorted",
+			events: []coinbase.Level2Event{level2(coinbase.StreamEventTypeSnapshot, "bid 99 1", "bid 100 2", "offer 102 1", "offer 101 3")},
+			bids:   "100x2 99x1 ",
+			asks:   "101x3 102x1 ",
+		},
+		{
+			name: "updates insert, replace and remove levels",
+			events: []coinbase.Level2Event{
+				level2(coinbase.StreamEventTypeSnapshot, "bid 99 1", "bid 100 2", "offer 101 3"),
+				level2(coinbase.StreamEventTypeUpdate, "bid 99.5 4", "bid 100 0", "offer 101 1.5"),
+			},
+			bids: "99.5x4 99x1 ",
+			asks: "101x1.5 ",
+		},
+		{
+			name:   "update before a snapshot",
+			events: []coinbase.Level2Event{level2(coinbase.StreamEventTypeUpdate, "bid 99 1")},
+			err:    coinbase.ErrOrderBookNotSynced,
+		},
+		{
+			name: "snapshot replaces the book",
+			events: []coinbase.Level2Event{
+				level2(coinbase.StreamEventTypeSnapshot, "bid 99 1", "offer 101 3"),
+				level2(coinbase.StreamEventTypeSnapshot, "bid 98 1"),
+			},
+			bids: "98x1 ",
+		},
+		{
+			name: "malformed update leaves the book untouched",
+			events: []coinbase.Level2Event{
+				level2(coinbase.StreamEventTypeSnapshot, "bid 99 1"),
+				level2(coinbase.StreamEventTypeUpdate, "bid 98 1", "bid x 1"),
+			},
+			err:  errors.New("invalid"),
+			bids: "99x1 ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := coinbase.NewOrderBook("BTC-USD")
+
+			var err error
+			for _, event := range tt.events {
+				err = book.ApplyLevel2(event)
+			}
+
+			switch {
+			case tt.err == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != nil && err == nil:
+				t.Fatalf("expected error %v", tt.err)
+			case errors.Is(tt.err, coinbase.ErrOrderBookNotSynced) && !errors.Is(err, coinbase.ErrOrderBookNotSynced):
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+
+			bids, asks := book.Depth(10)
+
+			if levels(bids) != tt.bids || levels(asks) != tt.asks {
+				t.Fatalf("book %q / %q, want %q / %q", levels(bids), levels(asks), tt.bids, tt.asks)
+			}
+		})
+	}
+}
+
+func TestOrderBookCumulativeSize(t *testing.T) {
+	book := coinbase.NewOrderBook("BTC-USD")
+
+	err := book.ApplyLevel2(level2(coinbase.StreamEventTypeSnapshot, "bid 100 1", "bid 99 2", "bid 98 4", "offer 101 0.5", "offer 102 1.25"))
+	if err != nil {
+		t.Fatalf("failed to apply snapshot: %v", err)
+	}
+
+	tests := []struct {
+		side  coinbase.Level2Side
+		price string
+		want  string
+	}{
+		{coinbase.Level2SideBid, "100", "1"},
+		{coinbase.Level2SideBid, "98.5", "3"},
+		{coinbase.Level2SideBid, "50", "7"},
+		{coinbase.Level2SideBid, "100.5", "0"},
+		{coinbase.Level2SideOffer, "101", "0.5"},
+		{coinbase.Level2SideOffer, "200", "1.75"},
+	}
+
+	for _, tt := range tests {
+		got, err := book.CumulativeSize(tt.side, tt.price)
+		if err != nil || got != tt.want {
+			t.Errorf("CumulativeSize(%s, %s) = %s, %v, want %s", tt.side, tt.price, got, err, tt.want)
+		}
+	}
+}
+
+func writeLevel2(t *testing.T, conn *websocket.Conn, sequence int64, event string) {
+	t.Helper()
+
+	msg := fmt.Sprintf(`{"channel":"l2_data","timestamp":"2024-01-01T00:00:00Z","sequence_num":%d,"events":[%s]}`, sequence, event)
+
+	err := conn.Write(context.Background(), websocket.MessageText, []byte(msg))
+	if err != nil {
+		t.Fatalf("failed to write message: %v", err)
+	}
+}
+
+func l2Event(typ string, side string, price string, size string) string {
+	return fmt.Sprintf(`{"type":%q,"product_id":"BTC-USD","updates":[{"side":%q,"price_level":%q,"new_quantity":%q}]}`, typ, side, price, size)
+}
+
+// eventually waits for the condition to hold.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func bestBid(book *coinbase.OrderBook) string {
+	bid, ok := book.BestBid()
+	if !ok {
+		return ""
+	}
+
+	return *bid.Price
+}
+
+// maintainOrderBook starts maintaining the book from the feed, and sends the first snapshot.
+func maintainOrderBook(t *testing.T, f *feed, client *coinbase.Client, options *coinbase.OrderBookOptions) (*coinbase.OrderBook, *websocket.Conn) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	type result struct {
+		book *coinbase.OrderBook
+		err  error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		book, err := client.Products.MaintainOrderBook(ctx, "BTC-USD", options)
+		done <- result{book, err}
+	}()
+
+	conn := f.accept(t)
+
+	for i := 0; i < 2; i++ {
+		readSubscription(t, conn)
+	}
+
+	writeLevel2(t, conn, 0, l2Event("snapshot", "bid", "100", "1"))
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("failed to maintain order book: %v", res.err)
+	}
+
+	return res.book, conn
+}
+
+func TestMaintainOrderBookResyncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		disrupt func(t *testing.T, conn *websocket.Conn, book *coinbase.OrderBook)
+	}{
+		{
+			name: "sequence gap",
+			disrupt: func(t *testing.T, conn *websocket.Conn, book *coinbase.OrderBook) {
+				writeLevel2(t, conn, 2, l2Event("update", "bid", "101", "1"))
+			},
+		},
+		{
+			name: "malformed update",
+			disrupt: func(t *testing.T, conn *websocket.Conn, book *coinbase.OrderBook) {
+				writeLevel2(t, conn, 1, l2Event("update", "bid", "x", "1"))
+			},
+		},
+		{
+			name: "update to an invalidated book",
+			disrupt: func(t *testing.T, conn *websocket.Conn, book *coinbase.OrderBook) {
+				book.Invalidate()
+				writeLevel2(t, conn, 1, l2Event("update", "bid", "101", "1"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeed(t)
+			client := coinbase.NewClient(coinbase.WithWebSocketURL(f.url))
+
+			book, conn := maintainOrderBook(t, f, client, nil)
+
+			tt.disrupt(t, conn, book)
+
+			// The channel is resubscribed to get a fresh snapshot.
+			for _, want := range []string{"unsubscribe", "subscribe"} {
+				if msg := readSubscription(t, conn); msg["type"] != want || msg["channel"] != "level2" {
+					t.Fatalf("sent %v %v, want %s level2", msg["type"], msg["channel"], want)
+				}
+			}
+
+			if book.Synced() {
+				t.Fatal("book still synced while waiting for the snapshot")
+			}
+
+			conn.CloseRead(context.Background())
+
+			// Updates are rejected until the snapshot arrives.
+			writeLevel2(t, conn, 3, l2Event("update", "bid", "102", "1"))
+			writeLevel2(t, conn, 4, l2Event("snapshot", "bid", "105", "1"))
+
+			eventually(t, "the snapshot", func() bool { return book.Synced() && bestBid(book) == "105" })
+		})
+	}
+}
+
+func TestMaintainOrderBookResyncsAfterReconnect(t *testing.T) {
+	f := newFeed(t)
+	client := coinbase.NewClient(coinbase.WithWebSocketURL(f.url))
+
+	book, conn := maintainOrderBook(t, f, client, nil)
+
+	conn.CloseNow()
+
+	eventually(t, "the book to be invalidated", func() bool { return !book.Synced() })
+
+	conn = f.accept(t)
+
+	for i := 0; i < 2; i++ {
+		readSubscription(t, conn)
+	}
+
+	conn.CloseRead(context.Background())
+
+	writeLevel2(t, conn, 0, l2Event("snapshot", "bid", "103", "2"))
+
+	eventually(t, "the snapshot", func() bool { return book.Synced() && bestBid(book) == "103" })
+
+	// Updates keep being applied, the disconnect must not invalidate the book once it is resynced.
+	writeLevel2(t, conn, 1, l2Event("update", "bid", "104", "1"))
+
+	eventually(t, "the update", func() bool { return book.Synced() && bestBid(book) == "104" })
+}
+
+func TestMaintainOrderBookPollsWhileDisconnected(t *testing.T) {
+	f := newFeed(t)
+
+	srv := coinbasetest.NewServer()
+	defer srv.Close()
+
+	srv.AddProduct(coinbase.Product{ID: "BTC-USD"})
+	srv.SetProductBook(coinbase.PriceBook{
+		ProductID: "BTC-USD",
+		Bids:      []coinbase.BidAsk{{Price: coinbase.String("90"), Size: coinbase.String("1")}},
+	})
+
+	client, err := srv.Client(coinbase.WithWebSocketURL(f.url))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	book, conn := maintainOrderBook(t, f, client, &coinbase.OrderBookOptions{PollInterval: 10 * time.Millisecond})
+
+	if bestBid(book) != "100" {
+		t.Fatalf("best bid %s, want the snapshot of the feed", bestBid(book))
+	}
+
+	conn.CloseNow()
+
+	// The stream waits a second before reconnecting, the book is polled in the meantime.
+	eventually(t, "the book to be polled", func() bool { return book.Synced() && bestBid(book) == "90" })
+
+	conn = f.accept(t)
+
+	for i := 0; i < 2; i++ {
+		readSubscription(t, conn)
+	}
+
+	conn.CloseRead(context.Background())
+
+	writeLevel2(t, conn, 0, l2Event("snapshot", "bid", "106", "1"))
+
+	eventually(t, "the snapshot of the feed", func() bool { return bestBid(book) == "106" })
+
+	// Polling stopped once the feed was back.
+	time.Sleep(50 * time.Millisecond)
+
+	if bestBid(book) != "106" {
+		t.Fatalf("best bid %s, polling did not stop after reconnecting", bestBid(book))
+	}
+}
+
+func TestMaintainOrderBookPollFailures(t *testing.T) {
+	var failing atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":"INVALID_ARGUMENT","message":"unavailable"}`)
+
+			return
+		}
+
+		io.WriteString(w, `{"pricebook":{"product_id":"BTC-USD","bids":[{"price":"100","size":"1"}],"asks":[]}}`)
+	}))
+	defer srv.Close()
+
+	client := coinbase.NewClient(coinbase.WithBaseURL(srv.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	book, err := client.Products.MaintainOrderBook(ctx, "BTC-USD", &coinbase.OrderBookOptions{Poll: true, PollInterval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to maintain order book: %v", err)
+	}
+
+	if book.Err() != nil {
+		t.Fatalf("unexpected error: %v", book.Err())
+	}
+
+	failing.Store(true)
+
+	// A single failed poll keeps the book synced with the previous one.
+	time.Sleep(60 * time.Millisecond)
+
+	if !book.Synced() {
+		t.Fatal("book invalidated after a single failed poll")
+	}
+
+	eventually(t, "the book to be invalidated", func() bool { return !book.Synced() })
+
+	var apiErr *coinbase.CoinbaseError
+	if err := book.Err(); !errors.Is(err, coinbase.ErrOrderBookNotSynced) || !errors.As(err, &apiErr) {
+		t.Fatalf("error is %v, want the poll error", err)
+	}
+
+	failing.Store(false)
+
+	eventually(t, "the book to be synced", func() bool { return book.Synced() && book.Err() == nil })
+}
@@ -0,0 +1,439 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrProductTradingDisabled = errors.New("trading is disabled for the product")
+	ErrProductCancelOnly      = errors.New("product is in cancel only mode, new orders are not accepted")
+	ErrProductLimitOnly       = errors.New("product is in limit only mode, market orders are not accepted")
+	ErrProductPostOnly        = errors.New("product is in post only mode, only post only limit orders are accepted")
+)
+
+// OrderValidationError is returned by the OrderBuilder when a field of an order does not satisfy the
+// product's increments or limits.
+type OrderValidationError struct {
+	ProductID string // The product the order was validated against.
+	Field     string // Name of the field in the API, i.e. 'base_size'.
+	Value     string // Value of the field.
+	Reason    string // Why the value is invalid.
+}
+
+func (e *OrderValidationError) Error() string {
+	return fmt.Sprintf("invalid %s '%s' for product '%s': %s", e.Field, e.Value, e.ProductID, e.Reason)
+}
+
+type orderBuilderOptions struct {
+	strict bool
+}
+
+type OrderBuilderOption func(*orderBuilderOptions)
+
+// WithStrictIncrements rejects sizes and prices that are not a multiple of the product's increments,
+// instead of rounding them.
+func WithStrictIncrements() OrderBuilderOption {
+	return func(o *orderBuilderOptions) {
+		o.strict = true
+	}
+}
+
+// OrderBuilder creates orders that satisfy the increments, size limits and trading modes of a product,
+// so bad orders are rejected locally with a descriptive error instead of by Coinbase.
+//
+// Unless WithStrictIncrements is used, sizes are rounded down to the product's increments and prices are
+// rounded away from the market, down for buys and up for sells, so an order never spends more than requested.
+type OrderBuilder struct {
+	product Product
+	options orderBuilderOptions
+
+	baseIncrement  Decimal
+	quoteIncrement Decimal
+	priceIncrement Decimal
+	baseMin        Decimal
+	baseMax        Decimal
+	quoteMin       Decimal
+	quoteMax       Decimal
+}
+
+// NewOrderBuilder creates a builder for the product, as returned by ProductsService.Get.
+func NewOrderBuilder(product Product, opts ...OrderBuilderOption) (*OrderBuilder, error) {
+	b := OrderBuilder{product: product}
+
+	for _, opt := range opts {
+		opt(&b.options)
+	}
+
+	for _, f := range []struct {
+		dst   *Decimal
+		parse func() (Decimal, error)
+	}{
+		{&b.baseIncrement, product.BaseIncrementDecimal},
+		{&b.quoteIncrement, product.QuoteIncrementDecimal},
+		{&b.priceIncrement, product.PriceIncrementDecimal},
+		{&b.baseMin, product.BaseMinimumSizeDecimal},
+		{&b.baseMax, product.BaseMaximumSizeDecimal},
+		{&b.quoteMin, product.QuoteMinimumSizeDecimal},
+		{&b.quoteMax, product.QuoteMaximumSizeDecimal},
+	} {
+		d, err := f.parse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order builder for product '%s': %w", product.ID, err)
+		}
+
+		*f.dst = d
+	}
+
+	return &b, nil
+}
+
+// Product returns the product the builder validates against.
+func (b *OrderBuilder) Product() Product {
+	return b.product
+}
+
+// Build creates an order for any configuration, i.e. coinbase.NewLimitFOK(coinbase.BaseSize("0.01"), "42000").
+func (b *OrderBuilder) Build(side Side, config OrderConfiguration) (CreateOrderOptions, error) {
+	return b.Normalize(CreateOrderOptions{Side: &side, OrderConfiguration: config})
+}
+
+// MarketIOC creates a market order for an amount of the base currency, typically used to sell.
+func (b *OrderBuilder) MarketIOC(side Side, baseSize Decimal) (CreateOrderOptions, error) {
+	return b.Build(side, NewMarketIOC(BaseSize(baseSize.String())))
+}
+
+// MarketIOCQuote creates a market order for an amount of the quote currency, typically used to buy.
+func (b *OrderBuilder) MarketIOCQuote(side Side, quoteSize Decimal) (CreateOrderOptions, error) {
+	return b.Build(side, NewMarketIOC(QuoteSize(quoteSize.String())))
+}
+
+// LimitGTC creates a limit order that remains on the book until it is canceled.
+func (b *OrderBuilder) LimitGTC(side Side, baseSize Decimal, limitPrice Decimal, postOnly bool) (CreateOrderOptions, error) {
+	return b.Build(side, NewLimitGTC(BaseSize(baseSize.String()), limitPrice.String(), postOnly))
+}
+
+// LimitGTD creates a limit order that is canceled at the end time if it has not been filled.
+func (b *OrderBuilder) LimitGTD(side Side, baseSize Decimal, limitPrice Decimal, endTime time.Time, postOnly bool) (CreateOrderOptions, error) {
+	return b.Build(side, NewLimitGTD(BaseSize(baseSize.String()), limitPrice.String(), endTime, postOnly))
+}
+
+// StopLimitGTC creates a stop limit order that remains on the book until it is canceled.
+func (b *OrderBuilder) StopLimitGTC(side Side, baseSize Decimal, limitPrice Decimal, stopPrice Decimal, direction StopDirection) (CreateOrderOptions, error) {
+	return b.Build(side, NewStopLimitGTC(baseSize.String(), limitPrice.String(), stopPrice.String(), direction))
+}
+
+// StopLimitGTD creates a stop limit order that is canceled at the end time if it has not been filled.
+func (b *OrderBuilder) StopLimitGTD(side Side, baseSize Decimal, limitPrice Decimal, stopPrice Decimal, endTime time.Time, direction StopDirection) (CreateOrderOptions, error) {
+	return b.Build(side, NewStopLimitGTD(baseSize.String(), limitPrice.String(), stopPrice.String(), endTime, direction))
+}
+
+// Normalize validates an order against the product, rounding its sizes and prices to the product's
+// increments. The product ID is filled in if it is empty. The options passed in are not modified.
+func (b *OrderBuilder) Normalize(options CreateOrderOptions) (CreateOrderOptions, error) {
+	p := b.product
+
+	if options.ProductID == "" {
+		options.ProductID = p.ID
+	} else if options.ProductID != p.ID {
+		return options, fmt.Errorf("order for product '%s' validated against product '%s'", options.ProductID, p.ID)
+	}
+
+	switch {
+	case p.TradingDisabled || p.IsDisabled:
+		return options, fmt.Errorf("order for product '%s' rejected: %w", p.ID, ErrProductTradingDisabled)
+	case p.CancelOnly:
+		return options, fmt.Errorf("order for product '%s' rejected: %w", p.ID, ErrProductCancelOnly)
+	}
+
+	if options.Side == nil || (*options.Side != SideBuy && *options.Side != SideSell) {
+		return options, b.invalid("side", string(derefSide(options.Side)), "must be BUY or SELL")
+	}
+
+	var (
+		f      orderFields
+		config = &options.OrderConfiguration
+	)
+
+	if config.count() != 1 {
+		return options, fmt.Errorf("order for product '%s' must have exactly one order configuration, has %d", p.ID, config.count())
+	}
+
+	// Each configuration is copied before it is rounded, so the caller's options are left untouched.
+	switch {
+	case config.MarketIOC != nil:
+		c := *config.MarketIOC
+		config.MarketIOC = &c
+		f = orderFields{market: true, baseSize: &c.BaseSize, quoteSize: &c.QuoteSize}
+	case config.MarketFOK != nil:
+		c := *config.MarketFOK
+		config.MarketFOK = &c
+		f = orderFields{market: true, baseSize: &c.BaseSize, quoteSize: &c.QuoteSize}
+	case config.SORLimitIOC != nil:
+		c := *config.SORLimitIOC
+		config.SORLimitIOC = &c
+		f = orderFields{baseSize: &c.BaseSize, quoteSize: &c.QuoteSize, limitPrice: &c.LimitPrice}
+	case config.LimitGTC != nil:
+		c := *config.LimitGTC
+		config.LimitGTC = &c
+		f = orderFields{baseSize: &c.BaseSize, quoteSize: &c.QuoteSize, limitPrice: &c.LimitPrice, postOnly: c.PostOnly}
+	case config.LimitGTD != nil:
+		c := *config.LimitGTD
+		config.LimitGTD = &c
+		f = orderFields{baseSize: &c.BaseSize, quoteSize: &c.QuoteSize, limitPrice: &c.LimitPrice, postOnly: c.PostOnly, endTime: c.EndTime, expires: true}
+	case config.LimitFOK != nil:
+		c := *config.LimitFOK
+		config.LimitFOK = &c
+		f = orderFields{baseSize: &c.BaseSize, quoteSize: &c.QuoteSize, limitPrice: &c.LimitPrice}
+	case config.StopLimitGTC != nil:
+		c := *config.StopLimitGTC
+		config.StopLimitGTC = &c
+		f = orderFields{baseSize: &c.BaseSize, limitPrice: &c.LimitPrice, stopPrice: &c.StopPrice, stopPriceName: "stop_price"}
+	case config.StopLimitGTD != nil:
+		c := *config.StopLimitGTD
+		config.StopLimitGTD = &c
+		f = orderFields{baseSize: &c.BaseSize, limitPrice: &c.LimitPrice, stopPrice: &c.StopPrice, stopPriceName: "stop_price", endTime: c.EndTime, expires: true}
+	case config.TriggerBracketGTC != nil:
+		c := *config.TriggerBracketGTC
+		config.TriggerBracketGTC = &c
+		f = orderFields{baseSize: &c.BaseSize, limitPrice: &c.LimitPrice, stopPrice: &c.StopTriggerPrice, stopPriceName: "stop_trigger_price"}
+	case config.TriggerBracketGTD != nil:
+		c := *config.TriggerBracketGTD
+		config.TriggerBracketGTD = &c
+		f = orderFields{baseSize: &c.BaseSize, limitPrice: &c.LimitPrice, stopPrice: &c.StopTriggerPrice, stopPriceName: "stop_trigger_price", endTime: c.EndTime, expires: true}
+	}
+
+	if f.expires && (f.endTime == nil || !f.endTime.After(time.Now())) {
+		var value string
+		if f.endTime != nil {
+			value = f.endTime.Format(time.RFC3339)
+		}
+
+		return options, b.invalid("end_time", value, "must be in the future")
+	}
+
+	if f.market {
+		return options, b.market(f)
+	}
+
+	return options, b.limit(*options.Side, f)
+}
+
+// orderFields points at the fields of an order configuration that are validated, fields the configuration
+// does not have are nil.
+type orderFields struct {
+	market        bool
+	baseSize      **string
+	quoteSize     **string
+	limitPrice    **string
+	stopPrice     **string
+	stopPriceName string
+	postOnly      *bool
+	endTime       *time.Time
+	expires       bool
+}
+
+// size returns which of the base and quote sizes is set, exactly one of them must be.
+func (b *OrderBuilder) size(f orderFields) (hasBase bool, err error) {
+	hasBase = f.baseSize != nil && *f.baseSize != nil && **f.baseSize != ""
+	hasQuote := f.quoteSize != nil && *f.quoteSize != nil && **f.quoteSize != ""
+
+	if hasBase == hasQuote {
+		if f.quoteSize == nil {
+			return false, b.invalid("base_size", "", "is required")
+		}
+
+		return false, fmt.Errorf("order for product '%s' must have exactly one of base_size or quote_size", b.product.ID)
+	}
+
+	return hasBase, nil
+}
+
+// count returns the number of order types that are populated.
+func (c OrderConfiguration) count() int {
+	n := 0
+
+	for _, set := range []bool{
+		c.MarketIOC != nil,
+		c.MarketFOK != nil,
+		c.SORLimitIOC != nil,
+		c.LimitGTC != nil,
+		c.LimitGTD != nil,
+		c.LimitFOK != nil,
+		c.StopLimitGTC != nil,
+		c.StopLimitGTD != nil,
+		c.TriggerBracketGTC != nil,
+		c.TriggerBracketGTD != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (b *OrderBuilder) market(f orderFields) error {
+	if b.product.LimitOnly {
+		return fmt.Errorf("order for product '%s' rejected: %w", b.product.ID, ErrProductLimitOnly)
+	}
+
+	if b.product.PostOnly {
+		return fmt.Errorf("order for product '%s' rejected: %w", b.product.ID, ErrProductPostOnly)
+	}
+
+	hasBase, err := b.size(f)
+	if err != nil {
+		return err
+	}
+
+	if hasBase {
+		_, err = b.base(f.baseSize)
+		return err
+	}
+
+	_, err = b.quote(f.quoteSize)
+
+	return err
+}
+
+func (b *OrderBuilder) limit(side Side, f orderFields) error {
+	if b.product.PostOnly && (f.postOnly == nil || !*f.postOnly) {
+		return fmt.Errorf("order for product '%s' rejected: %w", b.product.ID, ErrProductPostOnly)
+	}
+
+	hasBase, err := b.size(f)
+	if err != nil {
+		return err
+	}
+
+	price, err := b.price("limit_price", side, f.limitPrice)
+	if err != nil {
+		return err
+	}
+
+	if f.stopPrice != nil {
+		_, err = b.price(f.stopPriceName, side, f.stopPrice)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !hasBase {
+		_, err = b.quote(f.quoteSize)
+		return err
+	}
+
+	size, err := b.base(f.baseSize)
+	if err != nil {
+		return err
+	}
+
+	// The value of the order in quote currency is subject to the quote limits as well.
+	notional := size.Mul(price)
+
+	if notional.LessThan(b.quoteMin) {
+		return b.invalid("base_size", **f.baseSize, fmt.Sprintf("order value %s is below the minimum of %s", notional, b.quoteMin))
+	}
+
+	if !b.quoteMax.IsZero() && notional.GreaterThan(b.quoteMax) {
+		return b.invalid("base_size", **f.baseSize, fmt.Sprintf("order value %s is above the maximum of %s", notional, b.quoteMax))
+	}
+
+	return nil
+}
+
+// base fits the size to the base increment and checks it against the base limits.
+func (b *OrderBuilder) base(field **string) (Decimal, error) {
+	size, err := b.fit("base_size", field, b.baseIncrement, RoundDown)
+	if err != nil {
+		return size, err
+	}
+
+	return size, b.bounds("base_size", size, b.baseMin, b.baseMax)
+}
+
+// quote fits the size to the quote increment and checks it against the quote limits.
+func (b *OrderBuilder) quote(field **string) (Decimal, error) {
+	size, err := b.fit("quote_size", field, b.quoteIncrement, RoundDown)
+	if err != nil {
+		return size, err
+	}
+
+	return size, b.bounds("quote_size", size, b.quoteMin, b.quoteMax)
+}
+
+// price fits the price to the price increment, rounding away from the market.
+func (b *OrderBuilder) price(name string, side Side, field **string) (Decimal, error) {
+	mode := RoundDown
+	if side == SideSell {
+		mode = RoundUp
+	}
+
+	return b.fit(name, field, b.priceIncrement, mode)
+}
+
+// fit parses the field and rounds it to a multiple of the increment, replacing the field with the rounded value.
+func (b *OrderBuilder) fit(name string, field **string, increment Decimal, mode RoundingMode) (Decimal, error) {
+	if *field == nil || **field == "" {
+		return Decimal{}, b.invalid(name, "", "is required")
+	}
+
+	value, err := ParseDecimal(**field)
+	if err != nil {
+		return value, b.invalid(name, **field, "is not a decimal number")
+	}
+
+	if value.Sign() <= 0 {
+		return value, b.invalid(name, **field, "must be greater than zero")
+	}
+
+	fitted := value.Quantize(increment, mode)
+
+	if !fitted.Equal(value) {
+		if b.options.strict {
+			return value, b.invalid(name, **field, fmt.Sprintf("must be a multiple of %s", increment))
+		}
+
+		if fitted.IsZero() {
+			return value, b.invalid(name, **field, fmt.Sprintf("is zero when rounded to a multiple of %s", increment))
+		}
+	}
+
+	*field = String(fitted.String())
+
+	return fitted, nil
+}
+
+// bounds checks the value against the limits, a zero maximum means there is no maximum.
+func (b *OrderBuilder) bounds(name string, value Decimal, min Decimal, max Decimal) error {
+	if value.LessThan(min) {
+		return b.invalid(name, value.String(), fmt.Sprintf("is below the minimum of %s", min))
+	}
+
+	if !max.IsZero() && value.GreaterThan(max) {
+		return b.invalid(name, value.String(), fmt.Sprintf("is above the maximum of %s", max))
+	}
+
+	return nil
+}
+
+func (b *OrderBuilder) invalid(field string, value string, reason string) error {
+	return &OrderValidationError{ProductID: b.product.ID, Field: field, Value: value, Reason: reason}
+}
+
+func derefSide(s *Side) Side {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,292 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func btcUSD() coinbase.Product {
+	return coinbase.Product{
+		ID:               "BTC-USD",
+		BaseIncrement:    "0.00000001",
+		QuoteIncrement:   "0.01",
+		BaseMinimimSize:  "0.0001",
+		BaseMaximumSize:  "100",
+		QuoteMinimumSize: "1",
+		QuoteMaximumSize: "1000000",
+	}
+}
+
+func TestOrderBuilderNormalize(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		product func(p *coinbase.Product)
+		options []coinbase.OrderBuilderOption
+		side    coinbase.Side
+		config  coinbase.OrderConfiguration
+		field   string // Field of the expected OrderValidationError.
+		err     error  // Expected error otherwise.
+		check   func(t *testing.T, config coinbase.OrderConfiguration)
+	}{
+		{
+			name:   "sizes are rounded down and buy prices down",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("0.0123456789"), "42000.005", true),
+			check: func(t *testing.T, c coinbase.OrderConfiguration) {
+				if *c.LimitGTC.BaseSize != "0.01234567" || *c.LimitGTC.LimitPrice != "42000.00" {
+					t.Fatalf("normalized to %s @ %s", *c.LimitGTC.BaseSize, *c.LimitGTC.LimitPrice)
+				}
+			},
+		},
+		{
+			name:   "sell prices are rounded up",
+			side:   coinbase.SideSell,
+			config: coinbase.NewLimitFOK(coinbase.BaseSize("0.01"), "42000.001"),
+			check: func(t *testing.T, c coinbase.OrderConfiguration) {
+				if *c.LimitFOK.LimitPrice != "42000.01" {
+					t.Fatalf("limit price %s", *c.LimitFOK.LimitPrice)
+				}
+			},
+		},
+		{
+			name:    "price increment takes precedence over the quote increment",
+			product: func(p *coinbase.Product) { p.PriceIncrement = coinbase.String("0.5") },
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewStopLimitGTC("0.01", "42000.7", "41999.9", coinbase.StopDirectionDown),
+			check: func(t *testing.T, c coinbase.OrderConfiguration) {
+				if *c.StopLimitGTC.LimitPrice != "42000.5" || *c.StopLimitGTC.StopPrice != "41999.5" {
+					t.Fatalf("prices %s / %s", *c.StopLimitGTC.LimitPrice, *c.StopLimitGTC.StopPrice)
+				}
+			},
+		},
+		{
+			name:   "market quote size",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewMarketIOC(coinbase.QuoteSize("10.009")),
+			check: func(t *testing.T, c coinbase.OrderConfiguration) {
+				if *c.MarketIOC.QuoteSize != "10.00" {
+					t.Fatalf("quote size %s", *c.MarketIOC.QuoteSize)
+				}
+			},
+		},
+		{
+			name:    "strict increments",
+			options: []coinbase.OrderBuilderOption{coinbase.WithStrictIncrements()},
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewLimitGTC(coinbase.BaseSize("0.0123456789"), "42000", false),
+			field:   "base_size",
+		},
+		{
+			name:   "size rounds to zero",
+			side:   coinbase.SideSell,
+			config: coinbase.NewMarketFOK(coinbase.BaseSize("0.000000001")),
+			field:  "base_size",
+		},
+		{
+			name:   "below the base minimum",
+			side:   coinbase.SideSell,
+			config: coinbase.NewMarketIOC(coinbase.BaseSize("0.00001")),
+			field:  "base_size",
+		},
+		{
+			name:   "above the base maximum",
+			side:   coinbase.SideSell,
+			config: coinbase.NewMarketIOC(coinbase.BaseSize("101")),
+			field:  "base_size",
+		},
+		{
+			name:   "below the quote minimum",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewMarketIOC(coinbase.QuoteSize("0.5")),
+			field:  "quote_size",
+		},
+		{
+			name:   "order value below the quote minimum",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("0.0001"), "5000", false),
+			field:  "base_size",
+		},
+		{
+			name:   "negative price",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "-1", false),
+			field:  "limit_price",
+		},
+		{
+			name:   "malformed size",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewSORLimitIOC(coinbase.BaseSize("abc"), "42000"),
+			field:  "base_size",
+		},
+		{
+			name:   "end time in the past",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewLimitGTD(coinbase.BaseSize("0.01"), "42000", time.Now().Add(-time.Minute), false),
+			field:  "end_time",
+		},
+		{
+			name:   "end time in the future",
+			side:   coinbase.SideBuy,
+			config: coinbase.NewTriggerBracketGTD("0.01", "43000", "41000", future),
+		},
+		{
+			name:   "invalid side",
+			side:   coinbase.Side("HOLD"),
+			config: coinbase.NewMarketIOC(coinbase.QuoteSize("10")),
+			field:  "side",
+		},
+		{
+			name:    "trading disabled",
+			product: func(p *coinbase.Product) { p.TradingDisabled = true },
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewMarketIOC(coinbase.QuoteSize("10")),
+			err:     coinbase.ErrProductTradingDisabled,
+		},
+		{
+			name:    "cancel only",
+			product: func(p *coinbase.Product) { p.CancelOnly = true },
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "42000", true),
+			err:     coinbase.ErrProductCancelOnly,
+		},
+		{
+			name:    "limit only rejects market orders",
+			product: func(p *coinbase.Product) { p.LimitOnly = true },
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewMarketIOC(coinbase.QuoteSize("10")),
+			err:     coinbase.ErrProductLimitOnly,
+		},
+		{
+			name:    "post only rejects taker limit orders",
+			product: func(p *coinbase.Product) { p.PostOnly = true },
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "42000", false),
+			err:     coinbase.ErrProductPostOnly,
+		},
+		{
+			name:    "post only accepts maker limit orders",
+			product: func(p *coinbase.Product) { p.PostOnly = true },
+			side:    coinbase.SideBuy,
+			config:  coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "42000", true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := btcUSD()
+			if tt.product != nil {
+				tt.product(&product)
+			}
+
+			b, err := coinbase.NewOrderBuilder(product, tt.options...)
+			if err != nil {
+				t.Fatalf("failed to create builder: %v", err)
+			}
+
+			order, err := b.Build(tt.side, tt.config)
+
+			var invalid *coinbase.OrderValidationError
+
+			switch {
+			case tt.field != "":
+				if !errors.As(err, &invalid) || invalid.Field != tt.field {
+					t.Fatalf("error %v, want an invalid %s", err, tt.field)
+				}
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error %v, want %v", err, tt.err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			default:
+				// The client order ID is left to the caller, or to Place.
+				if order.ProductID != "BTC-USD" || order.ClientOrderID != "" {
+					t.Fatalf("product %q not filled in or client order ID %q generated", order.ProductID, order.ClientOrderID)
+				}
+
+				if tt.check != nil {
+					tt.check(t, order.OrderConfiguration)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderBuilderNormalizeLeavesOptionsUntouched(t *testing.T) {
+	b, err := coinbase.NewOrderBuilder(btcUSD())
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	side := coinbase.SideBuy
+	options := coinbase.CreateOrderOptions{
+		Side:               &side,
+		ClientOrderID:      "my-order",
+		OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("0.0123456789"), "42000.005", false),
+	}
+
+	order, err := b.Normalize(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if order.ClientOrderID != "my-order" {
+		t.Fatalf("client order ID replaced with %s", order.ClientOrderID)
+	}
+
+	if *options.OrderConfiguration.LimitGTC.BaseSize != "0.0123456789" || *options.OrderConfiguration.LimitGTC.LimitPrice != "42000.005" {
+		t.Fatal("options of the caller were rounded")
+	}
+}
+
+func TestOrderBuilderRejects(t *testing.T) {
+	b, err := coinbase.NewOrderBuilder(btcUSD())
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	side := coinbase.SideBuy
+
+	tests := []struct {
+		name    string
+		options coinbase.CreateOrderOptions
+	}{
+		{"other product", coinbase.CreateOrderOptions{ProductID: "ETH-USD", Side: &side, OrderConfiguration: coinbase.NewMarketIOC(coinbase.QuoteSize("10"))}},
+		{"no configuration", coinbase.CreateOrderOptions{Side: &side}},
+		{"two configurations", coinbase.CreateOrderOptions{Side: &side, OrderConfiguration: coinbase.OrderConfiguration{
+			MarketIOC: coinbase.NewMarketIOC(coinbase.QuoteSize("10")).MarketIOC,
+			LimitFOK:  coinbase.NewLimitFOK(coinbase.BaseSize("0.01"), "42000").LimitFOK,
+		}}},
+		{"base and quote size", coinbase.CreateOrderOptions{Side: &side, OrderConfiguration: coinbase.OrderConfiguration{
+			MarketIOC: &coinbase.MarketOrderIOC{BaseSize: coinbase.String("0.01"), QuoteSize: coinbase.String("10")},
+		}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := b.Normalize(tt.options); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestNewOrderBuilderInvalidProduct(t *testing.T) {
+	product := btcUSD()
+	product.BaseIncrement = "tiny"
+
+	_, err := coinbase.NewOrderBuilder(product)
+	if err == nil {
+		t.Fatal("expected an error for a malformed increment")
+	}
+}
@@ -80,14 +80,25 @@ type CreateOrderResponse struct {
 func (s *OrdersService) Create(ctx context.Context, options CreateOrderOptions) (*CreateOrderResponse, error) {
 	b, err := json.Marshal(&options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal OrderRequest to JSON: %w", err)
+		return nil, &notSentError{err: fmt.Errorf("failed to marshal OrderRequest to JSON: %w", err)}
+	}
+
+	post := s.client.post
+
+	// Coinbase returns the existing order when a client order ID is reused, so the request can be retried safely.
+	if options.ClientOrderID != "" {
+		post = s.client.postIdempotent
 	}
 
 	var orderResp CreateOrderResponse
-	err = s.client.post(ctx, s.client.baseURL+"/api/v3/brokerage/orders", bytes.NewBuffer(b), &orderResp)
+	err = post(ctx, s.client.baseURL+"/api/v3/brokerage/orders", bytes.NewBuffer(b), &orderResp)
 	if err != nil {
-		err = fmt.Errorf("failed to create order: %w", err)
+		return &orderResp, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	if s.client.strictOrders && !orderResp.Success {
+		return &orderResp, newOrderRejectedError(options, &orderResp)
 	}
 
-	return &orderResp, err
+	return &orderResp, nil
 }
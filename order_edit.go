@@ -37,12 +37,14 @@ type EditOrderOptions struct {
 	Size    *string `json:"size"`     // New size for order.
 }
 
+type EditOrderError struct {
+	EditFailureReason    *EditFailureReason    `json:"edit_failure_reason"`
+	PreviewFailureReason *PreviewFailureReason `json:"preview_failure_reason"`
+}
+
 type EditOrderResponse struct {
-	Success bool `json:"success"` // Whether the order edit request was placed.
-	Errors  []struct {
-		EditFailureReason    *EditFailureReason    `json:"edit_failure_reason"`
-		PreviewFailureReason *PreviewFailureReason `json:"preview_failure_reason"`
-	} `json:"errors"` // Details of any errors that may have occured.
+	Success bool             `json:"success"` // Whether the order edit request was placed.
+	Errors  []EditOrderError `json:"errors"`  // Details of any errors that may have occured.
 }
 
 func (s *OrdersService) edit(ctx context.Context, url string, options EditOrderOptions) (*EditOrderResponse, error) {
@@ -54,10 +56,14 @@ func (s *OrdersService) edit(ctx context.Context, url string, options EditOrderO
 	var orderResp EditOrderResponse
 	err = s.client.post(ctx, url, bytes.NewBuffer(b), &orderResp)
 	if err != nil {
-		err = fmt.Errorf("failed to edit order: %w", err)
+		return &orderResp, fmt.Errorf("failed to edit order: %w", err)
+	}
+
+	if s.client.strictOrders && !orderResp.Success {
+		return &orderResp, newEditRejectedError(options.OrderID, &orderResp)
 	}
 
-	return &orderResp, err
+	return &orderResp, nil
 }
 
 // EditPreview simulates an edit order request with a specified new size, or new price, to preview the result of an edit. Only limit order types, with time in force type of good-till-cancelled can be edited
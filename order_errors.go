@@ -0,0 +1,299 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithStrictOrders makes OrdersService.Create, Edit, EditPreview and Cancel return an error when Coinbase
+// accepted the request but did not carry it out, instead of only reporting it in the response. The error
+// is an *OrderRejectedError, *EditRejectedError or *CancelError respectively, and the response is still
+// returned alongside it.
+func WithStrictOrders() func(*Client) {
+	return func(c *Client) {
+		c.strictOrders = true
+	}
+}
+
+// Sentinel errors matched by errors.Is against the errors returned in strict mode. The failure reasons are
+// also matched against ErrInsufficientFunds, ErrNotFound and ErrInvalidArgument.
+var (
+	ErrOrderRejected = errors.New("coinbase: order rejected") // Any *OrderRejectedError.
+	ErrEditRejected  = errors.New("coinbase: edit rejected")  // Any *EditRejectedError.
+	ErrCancelFailed  = errors.New("coinbase: cancel failed")  // Any *CancelError.
+)
+
+// reasonSentinels are the sentinel errors matched by failure reasons other than the insufficient funds ones.
+var reasonSentinels = map[string]error{
+	string(OrderFailureReasonUnsupportedOrderConfiguration): ErrInvalidArgument,
+	string(OrderFailureReasonInvalidSide):                   ErrInvalidArgument,
+	string(OrderFailureReasonInvalidProductID):              ErrInvalidArgument,
+	string(OrderFailureReasonInvalidSizePrecision):          ErrInvalidArgument,
+	string(OrderFailureReasonInvalidPricePrecision):         ErrInvalidArgument,
+	string(OrderFailureReasonInvalidLimitPricePostOnly):     ErrInvalidArgument,
+	string(OrderFailureReasonInvalidLimitPrice):             ErrInvalidArgument,
+	string(OrderFailureReasonInvalidRequest):                ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidSide):                 ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidOrderConfig):          ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidProductID):            ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidSizePrecision):        ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidPricePrecision):       ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidLimitPricePostOnly):   ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidLimitPrice):           ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidStopPrice):            ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidBaseSizeTooLarge):     ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidBaseSizeTooSmall):     ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidQuoteSizePrecision):   ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidQuoteSizeTooLarge):    ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidPriceTooLarge):        ErrInvalidArgument,
+	string(PreviewFailureReasonInvalidQuoteSizeTooSmall):    ErrInvalidArgument,
+	string(EditFailureReasonBelowFilledSize):                ErrInvalidArgument,
+	string(EditFailureReasonOnlyLimitOrderEditsSupported):   ErrInvalidArgument,
+	string(EditFailureReasonInvalidEditedSize):              ErrInvalidArgument,
+	string(EditFailureReasonInvalidEditedPrice):             ErrInvalidArgument,
+	string(EditFailureReasonEditEqualToOriginal):            ErrInvalidArgument,
+	string(CancelOrderFailureReasonInvalidRequest):          ErrInvalidArgument,
+	string(EditFailureReasonNotFound):                       ErrNotFound,
+	string(CancelOrderFailureReasonUnknownOrder):            ErrNotFound,
+}
+
+// reasonIs reports whether any of the failure reasons matches the sentinel error.
+func reasonIs(target error, reasons ...string) bool {
+	for _, reason := range reasons {
+		if target == ErrInsufficientFunds && isInsufficientFundsReason(reason) {
+			return true
+		}
+
+		if sentinel, ok := reasonSentinels[reason]; ok && sentinel == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderRejectedError is returned by OrdersService.Create in strict mode when the order was not created.
+// It matches ErrOrderRejected with errors.Is, and the sentinel errors its failure reasons stand for.
+type OrderRejectedError struct {
+	ProductID             string                // The product the order was for.
+	ClientOrderID         string                // Client specified ID of the order.
+	Reason                string                // Most specific reason the order was not created.
+	FailureReason         *OrderFailureReason   // Reason the order was not created.
+	PreviewFailureReason  *PreviewFailureReason // Reason the order failed validation, if it did.
+	NewOrderFailureReason *OrderFailureReason   // Reason the order was rejected when it was placed, if it was.
+	Message               string                // Generic error message explaining why the order was not created.
+	Details               string                // Descriptive error message explaining why the order was not created.
+	Response              *CreateOrderResponse  // The response returned by Coinbase.
+}
+
+func newOrderRejectedError(options CreateOrderOptions, resp *CreateOrderResponse) *OrderRejectedError {
+	e := OrderRejectedError{
+		ProductID:             options.ProductID,
+		ClientOrderID:         options.ClientOrderID,
+		FailureReason:         resp.OrderFailureReason,
+		PreviewFailureReason:  resp.ErrorResponse.PreviewFailureReason,
+		NewOrderFailureReason: resp.ErrorResponse.NewOrderFailureReason,
+		Response:              resp,
+	}
+
+	if e.FailureReason == nil {
+		e.FailureReason = resp.ErrorResponse.Error
+	}
+
+	if resp.ErrorResponse.Message != nil {
+		e.Message = *resp.ErrorResponse.Message
+	}
+
+	if resp.ErrorResponse.ErrorDetails != nil {
+		e.Details = *resp.ErrorResponse.ErrorDetails
+	}
+
+	// The failure reason is often unknown while the preview or new order failure reason tells what went wrong.
+	for _, reason := range []string{
+		derefString((*string)(e.PreviewFailureReason)),
+		derefString((*string)(e.NewOrderFailureReason)),
+		derefString((*string)(e.FailureReason)),
+	} {
+		if reason != "" && reason != string(PreviewFailureReasonUnknown) && reason != string(OrderFailureReasonUnknown) {
+			e.Reason = reason
+			break
+		}
+	}
+
+	if e.Reason == "" && e.FailureReason != nil {
+		e.Reason = string(*e.FailureReason)
+	}
+
+	return &e
+}
+
+func (e *OrderRejectedError) Error() string {
+	reasons := e.reasons()
+
+	msg := fmt.Sprintf("order for product '%s' was rejected", e.ProductID)
+
+	if len(reasons) > 0 {
+		msg += ": " + strings.Join(reasons, ", ")
+	}
+
+	if e.Details != "" {
+		msg += ": " + e.Details
+	} else if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	return msg
+}
+
+// Is matches ErrOrderRejected, and the sentinel errors of the failure reasons such as ErrInsufficientFunds.
+func (e *OrderRejectedError) Is(target error) bool {
+	return target == ErrOrderRejected || reasonIs(target, e.reasons()...)
+}
+
+// reasons returns the failure reasons of the order.
+func (e *OrderRejectedError) reasons() []string {
+	var reasons []string
+
+	if e.FailureReason != nil {
+		reasons = append(reasons, string(*e.FailureReason))
+	}
+
+	if e.PreviewFailureReason != nil {
+		reasons = append(reasons, string(*e.PreviewFailureReason))
+	}
+
+	// The new order failure reason often repeats the failure reason.
+	if e.NewOrderFailureReason != nil && (e.FailureReason == nil || *e.NewOrderFailureReason != *e.FailureReason) {
+		reasons = append(reasons, string(*e.NewOrderFailureReason))
+	}
+
+	return reasons
+}
+
+// EditRejectedError is returned by OrdersService.Edit and EditPreview in strict mode when the edit was not placed.
+// It matches ErrEditRejected with errors.Is, and the sentinel errors its failure reasons stand for.
+type EditRejectedError struct {
+	OrderID  string             // ID of the order that was not edited.
+	Reason   string             // First reason the edit was rejected.
+	Errors   []EditOrderError   // Reasons the edit was rejected.
+	Response *EditOrderResponse // The response returned by Coinbase.
+}
+
+func newEditRejectedError(orderID string, resp *EditOrderResponse) *EditRejectedError {
+	e := EditRejectedError{OrderID: orderID, Errors: resp.Errors, Response: resp}
+
+	if reasons := e.reasons(); len(reasons) > 0 {
+		e.Reason = reasons[0]
+	}
+
+	return &e
+}
+
+func (e *EditRejectedError) Error() string {
+	reasons := e.reasons()
+
+	if len(reasons) == 0 {
+		return fmt.Sprintf("edit of order '%s' was rejected", e.OrderID)
+	}
+
+	return fmt.Sprintf("edit of order '%s' was rejected: %s", e.OrderID, strings.Join(reasons, ", "))
+}
+
+// Is matches ErrEditRejected, and the sentinel errors of the failure reasons such as ErrNotFound.
+func (e *EditRejectedError) Is(target error) bool {
+	return target == ErrEditRejected || reasonIs(target, e.reasons()...)
+}
+
+// reasons returns the failure reasons of the edit.
+func (e *EditRejectedError) reasons() []string {
+	var reasons []string
+
+	for _, err := range e.Errors {
+		if err.EditFailureReason != nil {
+			reasons = append(reasons, string(*err.EditFailureReason))
+		}
+
+		if err.PreviewFailureReason != nil {
+			reasons = append(reasons, string(*err.PreviewFailureReason))
+		}
+	}
+
+	return reasons
+}
+
+// CancelError is returned by OrdersService.Cancel in strict mode when one or more orders were not cancelled.
+// It matches ErrCancelFailed with errors.Is, and the sentinel errors its failure reasons stand for.
+type CancelError struct {
+	Reason    string           // Failure reason of the first order that was not cancelled.
+	Failed    []CancelledOrder // Results of the orders that were not cancelled.
+	Cancelled []CancelledOrder // Results of the orders that were cancelled.
+}
+
+func (e *CancelError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+
+	for _, order := range e.Failed {
+		reason := CancelOrderFailureReasonUnknown
+		if order.FailureReason != nil {
+			reason = *order.FailureReason
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %s", order.ID, reason))
+	}
+
+	return fmt.Sprintf(
+		"failed to cancel %d of %d orders: %s",
+		len(e.Failed),
+		len(e.Failed)+len(e.Cancelled),
+		strings.Join(failures, ", "),
+	)
+}
+
+// Is matches ErrCancelFailed, and the sentinel errors of the failure reasons such as ErrNotFound.
+func (e *CancelError) Is(target error) bool {
+	return target == ErrCancelFailed || reasonIs(target, e.reasons()...)
+}
+
+// reasons returns the failure reasons of the orders that were not cancelled.
+func (e *CancelError) reasons() []string {
+	var reasons []string
+
+	for _, order := range e.Failed {
+		if order.FailureReason != nil {
+			reasons = append(reasons, string(*order.FailureReason))
+		}
+	}
+
+	return reasons
+}
+
+// newCancelError returns a CancelError if any of the orders was not cancelled.
+func newCancelError(results []CancelledOrder) *CancelError {
+	var e CancelError
+
+	for _, order := range results {
+		if order.Success {
+			e.Cancelled = append(e.Cancelled, order)
+		} else {
+			e.Failed = append(e.Failed, order)
+		}
+	}
+
+	if len(e.Failed) == 0 {
+		return nil
+	}
+
+	if reasons := e.reasons(); len(reasons) > 0 {
+		e.Reason = reasons[0]
+	}
+
+	return &e
+}
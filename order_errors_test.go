@@ -0,0 +1,164 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func TestStrictOrders(t *testing.T) {
+	side := coinbase.SideBuy
+	order := coinbase.CreateOrderOptions{ProductID: "BTC-USD", Side: &side, OrderConfiguration: coinbase.NewMarketIOC(coinbase.QuoteSize("10"))}
+
+	create := func(c *coinbase.Client) error {
+		_, err := c.Orders.Create(context.Background(), order)
+		return err
+	}
+
+	edit := func(c *coinbase.Client) error {
+		_, err := c.Orders.Edit(context.Background(), coinbase.EditOrderOptions{OrderID: "1", Price: coinbase.String("42000")})
+		return err
+	}
+
+	editPreview := func(c *coinbase.Client) error {
+		_, err := c.Orders.EditPreview(context.Background(), coinbase.EditOrderOptions{OrderID: "1", Size: coinbase.String("1")})
+		return err
+	}
+
+	cancel := func(c *coinbase.Client) error {
+		_, err := c.Orders.Cancel(context.Background(), "1", "2")
+		return err
+	}
+
+	tests := []struct {
+		name   string
+		call   func(c *coinbase.Client) error
+		body   string
+		reason string  // Expected Reason of the error, empty if the call succeeds.
+		is     []error // Sentinel errors the error must match.
+		isNot  []error // Sentinel errors the error must not match.
+	}{
+		{
+			name:   "order rejected for insufficient funds",
+			call:   create,
+			body:   `{"success":false,"failure_reason":"UNKNOWN_FAILURE_REASON","error_response":{"error":"INSUFFICIENT_FUND","message":"Insufficient balance","preview_failure_reason":"PREVIEW_INSUFFICIENT_FUND","new_order_failure_reason":"INSUFFICIENT_FUND"}}`,
+			reason: "PREVIEW_INSUFFICIENT_FUND",
+			is:     []error{coinbase.ErrOrderRejected, coinbase.ErrInsufficientFunds},
+			isNot:  []error{coinbase.ErrInvalidArgument, coinbase.ErrEditRejected},
+		},
+		{
+			name:   "order rejected for an invalid price",
+			call:   create,
+			body:   `{"success":false,"failure_reason":"UNKNOWN_FAILURE_REASON","error_response":{"new_order_failure_reason":"INVALID_LIMIT_PRICE_POST_ONLY"}}`,
+			reason: "INVALID_LIMIT_PRICE_POST_ONLY",
+			is:     []error{coinbase.ErrOrderRejected, coinbase.ErrInvalidArgument},
+			isNot:  []error{coinbase.ErrInsufficientFunds},
+		},
+		{
+			name:   "order rejected for an unknown reason",
+			call:   create,
+			body:   `{"success":false,"failure_reason":"UNKNOWN_FAILURE_REASON"}`,
+			reason: "UNKNOWN_FAILURE_REASON",
+			is:     []error{coinbase.ErrOrderRejected},
+			isNot:  []error{coinbase.ErrInsufficientFunds, coinbase.ErrInvalidArgument, coinbase.ErrNotFound},
+		},
+		{
+			name: "order created",
+			call: create,
+			body: `{"success":true,"success_response":{"order_id":"1"}}`,
+		},
+		{
+			name:   "edit of a missing order",
+			call:   edit,
+			body:   `{"success":false,"errors":[{"edit_failure_reason":"ORDER_NOT_FOUND"}]}`,
+			reason: "ORDER_NOT_FOUND",
+			is:     []error{coinbase.ErrEditRejected, coinbase.ErrNotFound},
+			isNot:  []error{coinbase.ErrOrderRejected},
+		},
+		{
+			name:   "edit preview without funds",
+			call:   editPreview,
+			body:   `{"success":false,"errors":[{"preview_failure_reason":"PREVIEW_INSUFFICIENT_FUND"}]}`,
+			reason: "PREVIEW_INSUFFICIENT_FUND",
+			is:     []error{coinbase.ErrEditRejected, coinbase.ErrInsufficientFunds},
+		},
+		{
+			name:   "cancel of an unknown order",
+			call:   cancel,
+			body:   `{"results":[{"success":true,"order_id":"1"},{"success":false,"failure_reason":"UNKNOWN_CANCEL_ORDER","order_id":"2"}]}`,
+			reason: "UNKNOWN_CANCEL_ORDER",
+			is:     []error{coinbase.ErrCancelFailed, coinbase.ErrNotFound},
+			isNot:  []error{coinbase.ErrInvalidArgument},
+		},
+		{
+			name: "every order cancelled",
+			call: cancel,
+			body: `{"results":[{"success":true,"order_id":"1"},{"success":true,"order_id":"2"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newAPIServer(t, http.StatusOK, tt.body)
+
+			// Without strict mode the failure is only reported in the response.
+			if err := tt.call(srv.client()); err != nil {
+				t.Fatalf("unexpected error without strict mode: %v", err)
+			}
+
+			err := tt.call(srv.client(coinbase.WithStrictOrders()))
+
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var (
+				orderErr  *coinbase.OrderRejectedError
+				editErr   *coinbase.EditRejectedError
+				cancelErr *coinbase.CancelError
+				reason    string
+			)
+
+			switch {
+			case errors.As(err, &orderErr):
+				reason = orderErr.Reason
+			case errors.As(err, &editErr):
+				reason = editErr.Reason
+			case errors.As(err, &cancelErr):
+				reason = cancelErr.Reason
+			default:
+				t.Fatalf("error %v (%T), want a rejection", err, err)
+			}
+
+			if reason != tt.reason {
+				t.Errorf("reason %s, want %s", reason, tt.reason)
+			}
+
+			for _, target := range tt.is {
+				if !errors.Is(err, target) {
+					t.Errorf("%v does not match %v", err, target)
+				}
+			}
+
+			for _, target := range tt.isNot {
+				if errors.Is(err, target) {
+					t.Errorf("%v matches %v", err, target)
+				}
+			}
+		})
+	}
+}
@@ -16,11 +16,15 @@ import (
 type OrderStatus string
 
 const (
-	OrderStatusOpen      OrderStatus = "OPEN"
-	OrderStatusFilled    OrderStatus = "FILLED"
-	OrderStatusCancelled OrderStatus = "CANCELLED"
-	OrderStatusExpired   OrderStatus = "EXPIRED"
-	OrderStatusUnknown   OrderStatus = "UNKNOWN_ORDER_STATUS"
+	OrderStatusPending      OrderStatus = "PENDING"
+	OrderStatusOpen         OrderStatus = "OPEN"
+	OrderStatusFilled       OrderStatus = "FILLED"
+	OrderStatusCancelled    OrderStatus = "CANCELLED"
+	OrderStatusExpired      OrderStatus = "EXPIRED"
+	OrderStatusFailed       OrderStatus = "FAILED"
+	OrderStatusQueued       OrderStatus = "QUEUED"
+	OrderStatusCancelQueued OrderStatus = "CANCEL_QUEUED"
+	OrderStatusUnknown      OrderStatus = "UNKNOWN_ORDER_STATUS"
 )
 
 type TimeInForce string
@@ -30,6 +34,7 @@ const (
 	TimeInForceGoodUntilDate      TimeInForce = "GOOD_UNTIL_DATE_TIME"  // Orders are valid till a specified date or time.
 	TimeInForceGoodUntilCancelled TimeInForce = "GOOD_UNTIL_CANCELLED"  //  orders remain open on the book until canceled.
 	TimeInForceImmediateOrCancel  TimeInForce = "IMMEDIATE_OR_CANCEL"   // orders instantly cancel the remaining size of the limit order instead of opening it on the book.
+	TimeInForceFillOrKill         TimeInForce = "FILL_OR_KILL"          // Orders are cancelled entirely unless they can be filled in full immediately.
 )
 
 type TriggerStatus string
@@ -49,6 +54,7 @@ const (
 	OrderTypeLimt      OrderType = "LIMIT"
 	OrderTypeStop      OrderType = "STOP"
 	OrderTypeStopLimit OrderType = "STOP_LIMIT"
+	OrderTypeBracket   OrderType = "BRACKET"
 )
 
 type RejectReason string
@@ -85,7 +91,7 @@ type Order struct {
 	SizeInclusiveOfFees   bool                  `json:"size_inclusive_of_fees"`  // Whether the order size includes fees.
 	TotalValueAfterFees   string                `json:"total_value_after_fees"`  // Derived field defined as (filled_value + total_fees) for buy orders and (filled_value - total_fees) for sell orders.
 	TriggerStatus         *TriggerStatus        `json:"trigger_status"`          // Possible values: [UNKNOWN_TRIGGER_STATUS, INVALID_ORDER_TYPE, STOP_PENDING, STOP_TRIGGERED].
-	Type                  *OrderType            `json:"order_type"`              // Possible values: [UNKNOWN_ORDER_TYPE, MARKET, LIMIT, STOP, STOP_LIMIT].
+	Type                  *OrderType            `json:"order_type"`              // Possible values: [UNKNOWN_ORDER_TYPE, MARKET, LIMIT, STOP, STOP_LIMIT, BRACKET].
 	RejectReason          *RejectReason         `json:"reject_reason"`           // Rejection Reason; Possible values: [REJECT_REASON_UNSPECIFIED].
 	Settled               *bool                 `json:"settled"`                 // True if the order is fully filled, false otherwise.
 	ProductType           *ProductType          `json:"product_type"`            // Possible values: [SPOT, FUTURE].
@@ -102,6 +108,31 @@ type Order struct {
 	} `json:"edit_history"` // An array of the latest 5 edits per order.
 }
 
+// FilledSizeDecimal parses the portion of the order, in base currency, that has been filled.
+func (o Order) FilledSizeDecimal() (Decimal, error) {
+	return parseDecimalField("filled_size", o.FilledSize)
+}
+
+// FilledValueDecimal parses the portion of the order, in quote currency, that has been filled.
+func (o Order) FilledValueDecimal() (Decimal, error) {
+	return parseDecimalField("filled_value", o.FilledValue)
+}
+
+// AverageFilledPriceDecimal parses the average price of the fills of the order.
+func (o Order) AverageFilledPriceDecimal() (Decimal, error) {
+	return parseDecimalField("average_filled_price", &o.AverageFilledPrice)
+}
+
+// TotalFeesDecimal parses the total fees for the order.
+func (o Order) TotalFeesDecimal() (Decimal, error) {
+	return parseDecimalField("total_fees", &o.TotalFees)
+}
+
+// TotalValueAfterFeesDecimal parses the filled value of the order including fees.
+func (o Order) TotalValueAfterFeesDecimal() (Decimal, error) {
+	return parseDecimalField("total_value_after_fees", &o.TotalValueAfterFees)
+}
+
 type getOrderResponse struct {
 	Order Order `json:"order"`
 }
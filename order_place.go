@@ -0,0 +1,314 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultReconcileTimeout  = 30 * time.Second
+	defaultReconcileAttempts = 3
+	defaultReconcileInterval = time.Second
+
+	// Orders created this long before the request was sent are ignored when reconciling, this leaves
+	// room for the local clock to be behind Coinbase's.
+	reconcileClockSkew = 5 * time.Minute
+
+	// Most orders a single lookup goes through, the order history of a busy account is never searched in full.
+	reconcileMaxOrders = 1000
+)
+
+// ErrOrderStateUnknown - the order may have been placed, but Coinbase could not be asked whether it was.
+var ErrOrderStateUnknown = errors.New("order may have been placed but its state is unknown")
+
+type PlaceOutcome string
+
+const (
+	PlaceOutcomePlaced    PlaceOutcome = "PLACED"     // The order exists on Coinbase.
+	PlaceOutcomeNotPlaced PlaceOutcome = "NOT_PLACED" // The order does not exist and can be submitted again.
+	PlaceOutcomeUnknown   PlaceOutcome = "UNKNOWN"    // The order may have been placed but its state could not be determined. Look it up by ClientOrderID before submitting it again.
+)
+
+type PlaceOrderResult struct {
+	Outcome       PlaceOutcome         // Whether the order was placed.
+	ClientOrderID string               // Client order ID the order was submitted with, generated if none was given.
+	OrderID       string               // ID of the order if it was placed.
+	Resubmitted   bool                 // Whether an order with the same client order ID had already been placed, in which case no new order was created.
+	Reconciled    bool                 // Whether the outcome was determined by looking the order up after an ambiguous failure.
+	Response      *CreateOrderResponse // Response of the create order request, nil if none was received.
+	Order         *Order               // The order found when reconciling, if any.
+}
+
+type placeOrderOptions struct {
+	reconcileTimeout  time.Duration
+	reconcileAttempts int
+	reconcileInterval time.Duration
+}
+
+type PlaceOrderOption func(*placeOrderOptions)
+
+// WithReconcileTimeout limits how long Place may spend looking up an order after an ambiguous failure,
+// defaults to 30s. The lookup outlives the cancellation of the context passed to Place.
+func WithReconcileTimeout(d time.Duration) PlaceOrderOption {
+	return func(o *placeOrderOptions) {
+		o.reconcileTimeout = d
+	}
+}
+
+// WithReconcileAttempts sets how many times Place looks up an order after an ambiguous failure and the
+// wait between lookups, defaults to 3 lookups a second apart. Orders can take a moment to appear in the
+// order history, so the lookups stop as soon as one finds the order. If every lookup missed it the
+// outcome stays unknown, the order may still appear later.
+func WithReconcileAttempts(attempts int, interval time.Duration) PlaceOrderOption {
+	return func(o *placeOrderOptions) {
+		o.reconcileAttempts = attempts
+		o.reconcileInterval = interval
+	}
+}
+
+// NewClientOrderID generates a unique client order ID. Persist it before placing the order to be able to
+// find the order again after a crash.
+func NewClientOrderID() string {
+	return uuid.NewString()
+}
+
+// Place creates an order and reports whether it was placed.
+//
+// A client order ID is generated if the options have none, which makes the request safe to retry. If the
+// request fails without a clear answer from Coinbase, e.g. the context was cancelled after it was sent, the
+// connection was reset or Coinbase returned a 5xx, the order is looked up by its client order ID to find
+// out whether it was created. An order that is not found is reported as PlaceOutcomeUnknown, not finding it
+// does not prove it was not created. Requests that failed before they were sent, such as authentication or
+// rate limiter errors, and orders Coinbase rejected are reported as PlaceOutcomeNotPlaced without a lookup,
+// unless the rejection was for a duplicate client order ID. Submitting a client order ID that was already
+// placed is reported as PlaceOutcomePlaced with Resubmitted set, so Place can be called again with the same
+// options to retry.
+//
+// The result is always returned. The error is nil only if the order was placed, a rejected order returns an
+// *OrderRejectedError and an undetermined outcome wraps ErrOrderStateUnknown.
+func (s *OrdersService) Place(ctx context.Context, options CreateOrderOptions, opts ...PlaceOrderOption) (*PlaceOrderResult, error) {
+	o := placeOrderOptions{
+		reconcileTimeout:  defaultReconcileTimeout,
+		reconcileAttempts: defaultReconcileAttempts,
+		reconcileInterval: defaultReconcileInterval,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	generated := options.ClientOrderID == ""
+	if generated {
+		options.ClientOrderID = NewClientOrderID()
+	}
+
+	result := PlaceOrderResult{ClientOrderID: options.ClientOrderID}
+	sent := time.Now()
+
+	resp, err := s.Create(ctx, options)
+
+	var rejected *OrderRejectedError
+
+	switch {
+	case err == nil && resp.Success:
+		result.Outcome = PlaceOutcomePlaced
+		result.Response = resp
+		result.OrderID = createdOrderID(resp)
+
+		return &result, nil
+	case err == nil || errors.As(err, &rejected):
+		result.Response = resp
+
+		// Coinbase returns the existing order when the client order ID was already used.
+		if id := createdOrderID(resp); id != "" {
+			result.Outcome = PlaceOutcomePlaced
+			result.OrderID = id
+			result.Resubmitted = true
+
+			return &result, nil
+		}
+
+		if rejected == nil {
+			rejected = newOrderRejectedError(options, resp)
+		}
+
+		// The order was rejected, it only needs to be looked up if its client order ID was used before. A
+		// generated one cannot have been.
+		if generated || !isDuplicateClientOrderID(rejected) {
+			result.Outcome = PlaceOutcomeNotPlaced
+
+			return &result, rejected
+		}
+
+		return s.reconcile(ctx, &result, options, sent, o, rejected, true)
+	case !isAmbiguousCreateError(err):
+		result.Outcome = PlaceOutcomeNotPlaced
+
+		return &result, err
+	default:
+		return s.reconcile(ctx, &result, options, sent, o, err, false)
+	}
+}
+
+// reconcile looks up the order by its client order ID to settle the outcome of a request that failed
+// with cause. A found order is resubmitted if Coinbase answered the request, the order already existed.
+func (s *OrdersService) reconcile(
+	ctx context.Context,
+	result *PlaceOrderResult,
+	options CreateOrderOptions,
+	sent time.Time,
+	o placeOrderOptions,
+	cause error,
+	answered bool,
+) (*PlaceOrderResult, error) {
+	// The lookup has to happen even if the request failed because ctx was cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.reconcileTimeout)
+	defer cancel()
+
+	result.Reconciled = true
+
+	attempts := o.reconcileAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	// Lookups of orders that were answered only need to cover the case of an older order with the same ID.
+	if answered {
+		attempts = 1
+	}
+
+	var lookupErr error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, o.reconcileInterval); err != nil {
+				lookupErr = err
+				break
+			}
+		}
+
+		var order *Order
+
+		order, lookupErr = s.findByClientOrderID(ctx, options.ProductID, options.ClientOrderID, sent, answered)
+		if lookupErr != nil {
+			continue
+		}
+
+		if order != nil {
+			result.Outcome = PlaceOutcomePlaced
+			result.OrderID = order.ID
+			result.Order = order
+			result.Resubmitted = answered
+
+			return result, nil
+		}
+	}
+
+	if lookupErr != nil {
+		result.Outcome = PlaceOutcomeUnknown
+
+		return result, fmt.Errorf("%w: client order ID '%s': %w", ErrOrderStateUnknown, options.ClientOrderID, errors.Join(cause, lookupErr))
+	}
+
+	// Coinbase rejected the request, so it did not create the order.
+	if answered {
+		result.Outcome = PlaceOutcomeNotPlaced
+
+		return result, cause
+	}
+
+	// The request may have created an order that does not show up in the order history yet.
+	result.Outcome = PlaceOutcomeUnknown
+
+	return result, fmt.Errorf("%w: client order ID '%s' not found: %w", ErrOrderStateUnknown, options.ClientOrderID, cause)
+}
+
+// findByClientOrderID returns the order of the product with the client order ID, or nil if there is none.
+// Unless all is set, only orders created after since are searched. Either way at most reconcileMaxOrders
+// of the most recent orders are searched, an error is returned if there are more.
+func (s *OrdersService) findByClientOrderID(ctx context.Context, productID string, clientOrderID string, since time.Time, all bool) (*Order, error) {
+	limit := int32(reconcileMaxOrders)
+	options := ListOrdersOptions{ProductID: &productID, Limit: &limit}
+
+	if !all {
+		start := since.Add(-reconcileClockSkew)
+		options.StartDate = &start
+	}
+
+	orders := s.All(ctx, &options)
+
+	for searched := 0; orders.Next(); searched++ {
+		if searched == reconcileMaxOrders {
+			return nil, fmt.Errorf("failed to look up order with client order ID '%s': not among the last %d orders of product '%s'", clientOrderID, reconcileMaxOrders, productID)
+		}
+
+		if order := orders.Value(); order.ClientOrderID == clientOrderID {
+			return &order, nil
+		}
+	}
+
+	if err := orders.Err(); err != nil {
+		return nil, fmt.Errorf("failed to look up order with client order ID '%s': %w", clientOrderID, err)
+	}
+
+	return nil, nil
+}
+
+// isDuplicateClientOrderID reports whether Coinbase rejected the order because its client order ID was already used.
+func isDuplicateClientOrderID(e *OrderRejectedError) bool {
+	for _, s := range []string{e.Reason, e.Message, e.Details} {
+		if strings.Contains(strings.ToUpper(s), "DUPLICATE") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createdOrderID returns the ID of the order in the response, if there is one.
+func createdOrderID(resp *CreateOrderResponse) string {
+	if resp == nil {
+		return ""
+	}
+
+	if resp.SuccessResponse.OrderID != "" {
+		return resp.SuccessResponse.OrderID
+	}
+
+	if resp.OrderID != nil {
+		return *resp.OrderID
+	}
+
+	return ""
+}
+
+// isAmbiguousCreateError reports whether a create order request that failed with the error might still
+// have created the order. Requests that failed before they were sent and error responses from Coinbase
+// other than a 5xx did not, a 5xx or a transport error once the request was sent may have.
+func isAmbiguousCreateError(err error) bool {
+	if wasNotSent(err) {
+		return false
+	}
+
+	var cbError *CoinbaseError
+	if errors.As(err, &cbError) && cbError.StatusCode != 0 {
+		return cbError.StatusCode >= http.StatusInternalServerError
+	}
+
+	return true
+}
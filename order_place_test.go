@@ -0,0 +1,382 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+// failEndpoint fails the calls of the endpoint with err, after sending them if sent is set.
+func failEndpoint(endpoint coinbase.Endpoint, err error, sent bool) coinbase.Middleware {
+	return func(next coinbase.Handler) coinbase.Handler {
+		return func(call *coinbase.Call) error {
+			if call.Endpoint != endpoint {
+				return next(call)
+			}
+
+			if sent {
+				next(call)
+			}
+
+			return err
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	errReset := errors.New("connection reset by peer")
+	errUnavailable := &coinbase.CoinbaseError{StatusCode: http.StatusServiceUnavailable}
+
+	tests := []struct {
+		name          string
+		clientOrderID string
+		balance       string                // USD balance, too low to buy 0.01 BTC at 40000 if empty.
+		existing      bool                  // Whether an order with the client order ID was placed before.
+		middleware    []coinbase.Middleware // Failures of the requests.
+		outcome       coinbase.PlaceOutcome
+		resubmitted   bool
+		reconciled    bool
+		orders        int // Orders on the server afterwards.
+		lookups       int // List orders requests made.
+		err           error
+		rejected      bool // Whether the error is an *OrderRejectedError.
+	}{
+		{
+			name:    "placed",
+			balance: "1000",
+			outcome: coinbase.PlaceOutcomePlaced,
+			orders:  1,
+		},
+		{
+			name:          "placed with client order id",
+			clientOrderID: "my-order",
+			balance:       "1000",
+			outcome:       coinbase.PlaceOutcomePlaced,
+			orders:        1,
+		},
+		{
+			name:          "client order id already placed",
+			clientOrderID: "my-order",
+			balance:       "1000",
+			existing:      true,
+			outcome:       coinbase.PlaceOutcomePlaced,
+			orders:        1,
+		},
+		{
+			name:     "generated id rejected without lookup",
+			outcome:  coinbase.PlaceOutcomeNotPlaced,
+			err:      coinbase.ErrInsufficientFunds,
+			rejected: true,
+		},
+		{
+			name:          "client order id rejected without lookup",
+			clientOrderID: "my-order",
+			outcome:       coinbase.PlaceOutcomeNotPlaced,
+			err:           coinbase.ErrInsufficientFunds,
+			rejected:      true,
+		},
+		{
+			name:          "duplicate client order id looked up",
+			clientOrderID: "my-order",
+			balance:       "1000",
+			existing:      true,
+			middleware:    []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, &coinbase.OrderRejectedError{Reason: "DUPLICATE_CLIENT_ORDER_ID"}, false)},
+			outcome:       coinbase.PlaceOutcomePlaced,
+			resubmitted:   true,
+			reconciled:    true,
+			orders:        1,
+			lookups:       1,
+		},
+		{
+			name:          "authentication failure not reconciled",
+			clientOrderID: "my-order",
+			balance:       "1000",
+			middleware:    []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, &coinbase.AuthenticationError{Err: errors.New("key removed")}, false)},
+			outcome:       coinbase.PlaceOutcomeNotPlaced,
+		},
+		{
+			name:       "failure before sending not reconciled",
+			balance:    "1000",
+			middleware: []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, errReset, false)},
+			outcome:    coinbase.PlaceOutcomeNotPlaced,
+			err:        errReset,
+		},
+		{
+			name:          "client error not reconciled",
+			clientOrderID: "my-order",
+			balance:       "1000",
+			middleware:    []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, &coinbase.CoinbaseError{StatusCode: http.StatusBadRequest}, false)},
+			outcome:       coinbase.PlaceOutcomeNotPlaced,
+		},
+		{
+			name:       "5xx after the order was created",
+			balance:    "1000",
+			middleware: []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, errUnavailable, true)},
+			outcome:    coinbase.PlaceOutcomePlaced,
+			reconciled: true,
+			orders:     1,
+			lookups:    1,
+		},
+		{
+			name:       "connection reset after the order was created",
+			balance:    "1000",
+			middleware: []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, errReset, true)},
+			outcome:    coinbase.PlaceOutcomePlaced,
+			reconciled: true,
+			orders:     1,
+			lookups:    1,
+		},
+		{
+			// The server rejects the order for lack of funds, but the client only sees the 5xx.
+			name:       "ambiguous failure and order not found",
+			middleware: []coinbase.Middleware{failEndpoint(coinbase.EndpointCreateOrder, errUnavailable, true)},
+			outcome:    coinbase.PlaceOutcomeUnknown,
+			reconciled: true,
+			lookups:    3,
+			err:        coinbase.ErrOrderStateUnknown,
+		},
+		{
+			name:    "ambiguous failure and lookup failed",
+			balance: "1000",
+			middleware: []coinbase.Middleware{
+				failEndpoint(coinbase.EndpointCreateOrder, errReset, true),
+				failEndpoint(coinbase.EndpointListOrders, errUnavailable, true),
+			},
+			outcome:    coinbase.PlaceOutcomeUnknown,
+			reconciled: true,
+			orders:     1,
+			lookups:    3,
+			err:        coinbase.ErrOrderStateUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := coinbasetest.NewServer()
+			defer srv.Close()
+
+			srv.AddProduct(btcUSD())
+
+			if tt.balance != "" {
+				srv.SetBalance("USD", tt.balance)
+			}
+
+			side := coinbase.SideBuy
+			options := coinbase.CreateOrderOptions{
+				ClientOrderID:      tt.clientOrderID,
+				ProductID:          "BTC-USD",
+				Side:               &side,
+				OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "40000", false),
+			}
+
+			var existingID string
+
+			if tt.existing {
+				client, err := srv.Client()
+				if err != nil {
+					t.Fatalf("failed to create client: %v", err)
+				}
+
+				resp, err := client.Orders.Create(context.Background(), options)
+				if err != nil || !resp.Success {
+					t.Fatalf("failed to place the existing order: %v", err)
+				}
+
+				existingID = resp.SuccessResponse.OrderID
+			}
+
+			client, err := srv.Client(coinbase.WithStrictOrders(), coinbase.WithMiddleware(tt.middleware...))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			srv.ResetRequests()
+
+			result, err := client.Orders.Place(context.Background(), options, coinbase.WithReconcileAttempts(3, time.Millisecond))
+
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("error is %v, want %v", err, tt.err)
+			}
+
+			if tt.outcome == coinbase.PlaceOutcomePlaced && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.outcome != coinbase.PlaceOutcomePlaced && err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var rejected *coinbase.OrderRejectedError
+			if errors.As(err, &rejected) != tt.rejected {
+				t.Fatalf("error is %v, want a rejection: %t", err, tt.rejected)
+			}
+
+			if result == nil {
+				t.Fatal("no result")
+			}
+
+			if result.Outcome != tt.outcome {
+				t.Fatalf("outcome is %s, want %s", result.Outcome, tt.outcome)
+			}
+
+			if result.Resubmitted != tt.resubmitted || result.Reconciled != tt.reconciled {
+				t.Fatalf("resubmitted %t and reconciled %t, want %t and %t", result.Resubmitted, result.Reconciled, tt.resubmitted, tt.reconciled)
+			}
+
+			if result.ClientOrderID == "" || (tt.clientOrderID != "" && result.ClientOrderID != tt.clientOrderID) {
+				t.Fatalf("client order id is '%s', want '%s'", result.ClientOrderID, tt.clientOrderID)
+			}
+
+			orders := srv.Orders()
+			if len(orders) != tt.orders {
+				t.Fatalf("%d orders on the server, want %d", len(orders), tt.orders)
+			}
+
+			if tt.outcome == coinbase.PlaceOutcomePlaced {
+				if result.OrderID != orders[0].ID || orders[0].ClientOrderID != result.ClientOrderID {
+					t.Fatalf("placed order '%s', want '%s'", result.OrderID, orders[0].ID)
+				}
+
+				if existingID != "" && result.OrderID != existingID {
+					t.Fatalf("placed order '%s', want the existing order '%s'", result.OrderID, existingID)
+				}
+			}
+
+			if tt.reconciled && tt.outcome == coinbase.PlaceOutcomePlaced && result.Order == nil {
+				t.Fatal("reconciled order not returned")
+			}
+
+			var lookups int
+
+			for _, r := range srv.Requests() {
+				if r.Method == http.MethodGet && strings.HasSuffix(r.Path, "/orders/historical/batch") {
+					lookups++
+				}
+			}
+
+			if lookups != tt.lookups {
+				t.Fatalf("%d lookups, want %d", lookups, tt.lookups)
+			}
+		})
+	}
+}
+
+func TestPlaceResubmitted(t *testing.T) {
+	// Coinbase answers a reused client order ID with a failure that carries the existing order.
+	srv := newAPIServer(t, http.StatusOK, `{"success":false,"order_id":"existing","failure_reason":"UNKNOWN_FAILURE_REASON"}`)
+	client := srv.client()
+
+	side := coinbase.SideBuy
+
+	result, err := client.Orders.Place(context.Background(), coinbase.CreateOrderOptions{
+		ClientOrderID:      "my-order",
+		ProductID:          "BTC-USD",
+		Side:               &side,
+		OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "40000", false),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Outcome != coinbase.PlaceOutcomePlaced || !result.Resubmitted || result.Reconciled || result.OrderID != "existing" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if len(srv.Requests) != 1 {
+		t.Fatalf("%d requests, want 1", len(srv.Requests))
+	}
+}
+
+func TestPlaceReconcileOutlivesContext(t *testing.T) {
+	srv := coinbasetest.NewServer()
+	defer srv.Close()
+
+	srv.AddProduct(btcUSD())
+	srv.SetBalance("USD", "1000")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The caller gives up once the request was sent.
+	cancelled := func(next coinbase.Handler) coinbase.Handler {
+		return func(call *coinbase.Call) error {
+			err := next(call)
+
+			if call.Endpoint == coinbase.EndpointCreateOrder {
+				cancel()
+				return context.Canceled
+			}
+
+			return err
+		}
+	}
+
+	client, err := srv.Client(coinbase.WithMiddleware(cancelled))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	side := coinbase.SideBuy
+
+	result, err := client.Orders.Place(ctx, coinbase.CreateOrderOptions{
+		ProductID:          "BTC-USD",
+		Side:               &side,
+		OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "40000", false),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Outcome != coinbase.PlaceOutcomePlaced || !result.Reconciled {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestPlaceCancelledBeforeSending(t *testing.T) {
+	srv := coinbasetest.NewServer()
+	defer srv.Close()
+
+	srv.AddProduct(btcUSD())
+	srv.SetBalance("USD", "1000")
+
+	client, err := srv.Client(coinbase.WithRateLimit(coinbase.RateLimits{Private: coinbase.RateLimit{Rate: 10}}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	side := coinbase.SideBuy
+
+	// The rate limiter fails the request before it is sent, so the order cannot have been placed.
+	result, err := client.Orders.Place(ctx, coinbase.CreateOrderOptions{
+		ClientOrderID:      "my-order",
+		ProductID:          "BTC-USD",
+		Side:               &side,
+		OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("0.01"), "40000", false),
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error is %v, want %v", err, context.Canceled)
+	}
+
+	if result.Outcome != coinbase.PlaceOutcomeNotPlaced || result.Reconciled {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if len(srv.Requests()) != 0 {
+		t.Fatalf("%d requests, want none", len(srv.Requests()))
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type PreviewWarning string
+
+const (
+	PreviewWarningUnknown    PreviewWarning = "UNKNOWN"
+	PreviewWarningBigOrder   PreviewWarning = "BIG_ORDER"
+	PreviewWarningSmallOrder PreviewWarning = "SMALL_ORDER"
+)
+
+// Preview order accepts the same body as create order, minus the client order ID.
+type previewOrderRequest struct {
+	ProductID          string             `json:"product_id"`
+	Side               *Side              `json:"side"`
+	OrderConfiguration OrderConfiguration `json:"order_configuration"`
+	Leverage           *string            `json:"leverage,omitempty"`
+	MarginType         *MarginType        `json:"margin_type,omitempty"`
+	RetailPortfolioID  *string            `json:"retail_portfolio_id,omitempty"`
+}
+
+type PreviewOrderResponse struct {
+	OrderTotal       *string                `json:"order_total"`        // The total amount of the order, including commission.
+	CommissionTotal  *string                `json:"commission_total"`   // The total commission charged for the order.
+	Errs             []PreviewFailureReason `json:"errs"`               // Reasons the order would be rejected if it was placed.
+	Warning          []PreviewWarning       `json:"warning"`            // Warnings about the order that would not prevent it from being placed.
+	QuoteSize        *string                `json:"quote_size"`         // Size of the order in quote currency.
+	BaseSize         *string                `json:"base_size"`          // Size of the order in base currency.
+	BestBid          *string                `json:"best_bid"`           // The best bid for the product at the time of the preview, in quote currency.
+	BestAsk          *string                `json:"best_ask"`           // The best ask for the product at the time of the preview, in quote currency.
+	IsMax            *bool                  `json:"is_max"`             // Whether the order is using the maximum available balance.
+	OrderMarginTotal *string                `json:"order_margin_total"` // The total margin required for the order.
+	Leverage         *string                `json:"leverage"`           // The leverage applied to the order.
+	LongLeverage     *string                `json:"long_leverage"`      // The leverage applied to long positions.
+	ShortLeverage    *string                `json:"short_leverage"`     // The leverage applied to short positions.
+	Slippage         *string                `json:"slippage"`           // Expected slippage of the order, as a percentage.
+	PreviewID        *string                `json:"preview_id"`         // ID of the preview.
+}
+
+// Preview simulates an order request to preview the results of the order before it is sent.
+// Any reasons the order would be rejected are returned in Errs, the client order ID is ignored.
+// https://docs.cdp.coinbase.com/advanced-trade/reference/retailbrokerageapi_previeworder/
+func (s *OrdersService) Preview(ctx context.Context, options CreateOrderOptions) (*PreviewOrderResponse, error) {
+	b, err := json.Marshal(&previewOrderRequest{
+		ProductID:          options.ProductID,
+		Side:               options.Side,
+		OrderConfiguration: options.OrderConfiguration,
+		Leverage:           options.Leverage,
+		MarginType:         options.MarginType,
+		RetailPortfolioID:  options.RetailPortfolioID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal preview order request to JSON: %w", err)
+	}
+
+	var previewResp PreviewOrderResponse
+	err = s.client.postIdempotent(ctx, s.client.baseURL+"/api/v3/brokerage/orders/preview", bytes.NewBuffer(b), &previewResp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview order: %w", err)
+	}
+
+	return &previewResp, nil
+}
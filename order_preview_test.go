@@ -0,0 +1,96 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func TestOrdersPreview(t *testing.T) {
+	buy, sell := coinbase.SideBuy, coinbase.SideSell
+
+	tests := []struct {
+		name     string
+		options  coinbase.CreateOrderOptions
+		response string
+		body     string // Expected request body.
+		check    func(*coinbase.PreviewOrderResponse) bool
+	}{
+		{
+			name: "client order ID is not sent",
+			options: coinbase.CreateOrderOptions{
+				ClientOrderID:      "ignored",
+				ProductID:          "BTC-USD",
+				Side:               &buy,
+				OrderConfiguration: coinbase.NewMarketIOC(coinbase.QuoteSize("100")),
+			},
+			response: `{"order_total":"100","commission_total":"0.6","errs":[],"warning":[],"quote_size":"99.4","preview_id":"p-1"}`,
+			body:     `{"product_id":"BTC-USD","side":"BUY","order_configuration":{"market_market_ioc":{"quote_size":"100"}}}`,
+			check: func(r *coinbase.PreviewOrderResponse) bool {
+				return *r.OrderTotal == "100" && *r.CommissionTotal == "0.6" && *r.PreviewID == "p-1" && len(r.Errs) == 0
+			},
+		},
+		{
+			name: "portfolio and leverage are sent",
+			options: coinbase.CreateOrderOptions{
+				ProductID:          "BTC-USD",
+				Side:               &sell,
+				OrderConfiguration: coinbase.NewLimitGTC(coinbase.BaseSize("0.1"), "42000", true),
+				Leverage:           coinbase.String("2"),
+				RetailPortfolioID:  coinbase.String("portfolio-1"),
+			},
+			response: `{"order_total":"4200","errs":[],"warning":["BIG_ORDER"]}`,
+			body:     `{"product_id":"BTC-USD","side":"SELL","order_configuration":{"limit_limit_gtc":{"base_size":"0.1","limit_price":"42000","post_only":true}},"leverage":"2","retail_portfolio_id":"portfolio-1"}`,
+			check: func(r *coinbase.PreviewOrderResponse) bool {
+				return len(r.Warning) == 1 && r.Warning[0] == coinbase.PreviewWarningBigOrder
+			},
+		},
+		{
+			name: "rejection reasons",
+			options: coinbase.CreateOrderOptions{
+				ProductID:          "BTC-USD",
+				Side:               &buy,
+				OrderConfiguration: coinbase.NewMarketIOC(coinbase.QuoteSize("1000000")),
+			},
+			response: `{"errs":["PREVIEW_INSUFFICIENT_FUND"],"warning":[]}`,
+			body:     `{"product_id":"BTC-USD","side":"BUY","order_configuration":{"market_market_ioc":{"quote_size":"1000000"}}}`,
+			check: func(r *coinbase.PreviewOrderResponse) bool {
+				return len(r.Errs) == 1 && r.Errs[0] == coinbase.PreviewFailureReasonInsufficientFund
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newAPIServer(t, http.StatusOK, tt.response)
+
+			resp, err := srv.client().Orders.Preview(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			req := srv.last(t)
+
+			if req.Method != http.MethodPost || req.Path != "/api/v3/brokerage/orders/preview" {
+				t.Fatalf("request %s %s", req.Method, req.Path)
+			}
+
+			if req.Body != tt.body {
+				t.Fatalf("body %s, want %s", req.Body, tt.body)
+			}
+
+			if !tt.check(resp) {
+				t.Fatalf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
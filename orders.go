@@ -64,56 +64,172 @@ const (
 // Market Order Immediate Or Cancel.
 // Market orders are used to BUY or SELL a desired product at the given market price. Immediate Or Cancel (ioc): orders instantly cancel the remaining size of the limit order instead of opening it on the book.
 type MarketOrderIOC struct {
-	QuoteSize *string `json:"quote_size"` // Amount of quote currency to spend on order. Required for BUY orders
-	BaseSize  *string `json:"base_size"`  // Amount of base currency to spend on order. Required for SELL orders.
+	QuoteSize *string `json:"quote_size,omitempty"` // Amount of quote currency to spend on order. Required for BUY orders
+	BaseSize  *string `json:"base_size,omitempty"`  // Amount of base currency to spend on order. Required for SELL orders.
+}
+
+// Market Order Fill Or Kill.
+// Market orders are used to BUY or SELL a desired product at the given market price. Fill Or Kill (fok): orders are cancelled entirely unless they can be filled in full immediately.
+type MarketOrderFOK struct {
+	QuoteSize *string `json:"quote_size,omitempty"` // Amount of quote currency to spend on order. Required for BUY orders
+	BaseSize  *string `json:"base_size,omitempty"`  // Amount of base currency to spend on order. Required for SELL orders.
+}
+
+// Smart Order Routing Limit Order Immediate Or Cancel.
+// The order is routed to the venues offering the best price, up to the limit price. Any size that cannot be filled immediately is cancelled.
+type SORLimitOrderIOC struct {
+	QuoteSize  *string `json:"quote_size,omitempty"`  // Amount of quote currency to spend on order.
+	BaseSize   *string `json:"base_size,omitempty"`   // Amount of base currency to spend on order.
+	LimitPrice *string `json:"limit_price,omitempty"` // Ceiling price for which the order should get filled.
 }
 
 // Limit Order Good Till Canceled.
 // Limit orders are triggered based on the instructions around quantity and price: base_size represents the quantity of your base currency to spend; limit_price represents the maximum price at which the order should be filled.
 // Good Till Canceled (gtc): orders remain open on the book until canceled.
 type LimitOrderGTC struct {
-	BaseSize   *string `json:"base_size"`   // Amount of base currency to spend on order.
-	LimitPrice *string `json:"limit_price"` // Ceiling price for which the order should get filled.
-	PostOnly   *bool   `json:"post_only"`   // The post-only flag indicates that the order should only make liquidity. If any part of the order results in taking liquidity, the order will be rejected and no part of it will execute.
+	QuoteSize  *string `json:"quote_size,omitempty"`  // Amount of quote currency to spend on order.
+	BaseSize   *string `json:"base_size,omitempty"`   // Amount of base currency to spend on order.
+	LimitPrice *string `json:"limit_price,omitempty"` // Ceiling price for which the order should get filled.
+	PostOnly   *bool   `json:"post_only,omitempty"`   // The post-only flag indicates that the order should only make liquidity. If any part of the order results in taking liquidity, the order will be rejected and no part of it will execute.
 }
 
 // Limit Order Good Till Date.
 // Limit orders are triggered based on the instructions around quantity and price: base_size represents the quantity of your base currency to spend; limit_price represents the maximum price at which the order should be filled.
 // Good Till Date (gtd): orders are valid till a specified date or time.
 type LimitOrderGTD struct {
-	BaseSize   *string    `json:"base_size"`   // Amount of base currency to spend on order.
-	LimitPrice *string    `json:"limit_price"` // Ceiling price for which the order should get filled.
-	EndTime    *time.Time `json:"end_time"`    // Time at which the order should be cancelled if it's not filled.
-	PostOnly   *bool      `json:"post_only"`   // The post-only flag indicates that the order should only make liquidity. If any part of the order results in taking liquidity, the order will be rejected and no part of it will execute.
+	QuoteSize  *string    `json:"quote_size,omitempty"`  // Amount of quote currency to spend on order.
+	BaseSize   *string    `json:"base_size,omitempty"`   // Amount of base currency to spend on order.
+	LimitPrice *string    `json:"limit_price,omitempty"` // Ceiling price for which the order should get filled.
+	EndTime    *time.Time `json:"end_time,omitempty"`    // Time at which the order should be cancelled if it's not filled.
+	PostOnly   *bool      `json:"post_only,omitempty"`   // The post-only flag indicates that the order should only make liquidity. If any part of the order results in taking liquidity, the order will be rejected and no part of it will execute.
+}
+
+// Limit Order Fill Or Kill.
+// Fill Or Kill (fok): orders are cancelled entirely unless they can be filled in full immediately at the limit price or better.
+type LimitOrderFOK struct {
+	QuoteSize  *string `json:"quote_size,omitempty"`  // Amount of quote currency to spend on order.
+	BaseSize   *string `json:"base_size,omitempty"`   // Amount of base currency to spend on order.
+	LimitPrice *string `json:"limit_price,omitempty"` // Ceiling price for which the order should get filled.
 }
 
 // Stop Order Good Till Canceled.
 // Stop orders are triggered based on the movement of the last trade price. The last trade price is the last price at which an order was filled.
 // Good Till Canceled (gtc): orders remain open on the book until canceled.
 type StopLimitOrderGTC struct {
-	BaseSize      *string        `json:"base_size"`      // Amount of base currency to spend on order.
-	LimitPrice    *string        `json:"limit_price"`    // Ceiling price for which the order should get filled.
-	StopPrice     *string        `json:"stop_price"`     // Price at which the order should trigger - if stop direction is Up, then the order will trigger when the last trade price goes above this, otherwise order will trigger when last trade price goes below this price.
-	StopDirection *StopDirection `json:"stop_direction"` // Possible values: [STOP_DIRECTION_STOP_UP, STOP_DIRECTION_STOP_DOWN].
+	BaseSize      *string        `json:"base_size,omitempty"`      // Amount of base currency to spend on order.
+	LimitPrice    *string        `json:"limit_price,omitempty"`    // Ceiling price for which the order should get filled.
+	StopPrice     *string        `json:"stop_price,omitempty"`     // Price at which the order should trigger - if stop direction is Up, then the order will trigger when the last trade price goes above this, otherwise order will trigger when last trade price goes below this price.
+	StopDirection *StopDirection `json:"stop_direction,omitempty"` // Possible values: [STOP_DIRECTION_STOP_UP, STOP_DIRECTION_STOP_DOWN].
 }
 
 // Stop Order Good Till Date.
 // Stop orders are triggered based on the movement of the last trade price. The last trade price is the last price at which an order was filled.
 // Good Till Date (gtd): orders are valid till a specified date or time.
 type StopLimitOrderGTD struct {
-	BaseSize      *string    `json:"base_size"`      // Amount of base currency to spend on order.
-	LimitPrice    *string    `json:"limit_price"`    // Ceiling price for which the order should get filled.
-	StopPrice     *string    `json:"stop_price"`     // Price at which the order should trigger - if stop direction is Up, then the order will trigger when the last trade price goes above this, otherwise order will trigger when last trade price goes below this price.
-	EndTime       *time.Time `json:"end_time"`       // Time at which the order should be cancelled if it's not filled.
-	StopDirection *string    `json:"stop_direction"` // Possible values: [STOP_DIRECTION_STOP_UP, STOP_DIRECTION_STOP_DOWN].
+	BaseSize      *string        `json:"base_size,omitempty"`      // Amount of base currency to spend on order.
+	LimitPrice    *string        `json:"limit_price,omitempty"`    // Ceiling price for which the order should get filled.
+	StopPrice     *string        `json:"stop_price,omitempty"`     // Price at which the order should trigger - if stop direction is Up, then the order will trigger when the last trade price goes above this, otherwise order will trigger when last trade price goes below this price.
+	EndTime       *time.Time     `json:"end_time,omitempty"`       // Time at which the order should be cancelled if it's not filled.
+	StopDirection *StopDirection `json:"stop_direction,omitempty"` // Possible values: [STOP_DIRECTION_STOP_UP, STOP_DIRECTION_STOP_DOWN].
+}
+
+// Trigger Bracket Order Good Till Canceled.
+// A limit order placed together with a stop loss. The order rests on the book at the limit price, if the last trade price crosses the stop trigger price the order is replaced by a market order.
+// Good Till Canceled (gtc): orders remain open on the book until canceled.
+type TriggerBracketOrderGTC struct {
+	BaseSize         *string `json:"base_size,omitempty"`          // Amount of base currency to spend on order.
+	LimitPrice       *string `json:"limit_price,omitempty"`        // Price the take profit leg of the order is placed at.
+	StopTriggerPrice *string `json:"stop_trigger_price,omitempty"` // Price at which the stop loss leg of the order triggers.
+}
+
+// Trigger Bracket Order Good Till Date.
+// A limit order placed together with a stop loss. The order rests on the book at the limit price, if the last trade price crosses the stop trigger price the order is replaced by a market order.
+// Good Till Date (gtd): orders are valid till a specified date or time.
+type TriggerBracketOrderGTD struct {
+	BaseSize         *string    `json:"base_size,omitempty"`          // Amount of base currency to spend on order.
+	LimitPrice       *string    `json:"limit_price,omitempty"`        // Price the take profit leg of the order is placed at.
+	StopTriggerPrice *string    `json:"stop_trigger_price,omitempty"` // Price at which the stop loss leg of the order triggers.
+	EndTime          *time.Time `json:"end_time,omitempty"`           // Time at which the order should be cancelled if it's not filled.
 }
 
 // Configuration of the order, it can only consist of a single order type at at time.
-// The rest will not be populated.
+// The rest will not be populated. Use the constructors, i.e. NewLimitGTC, to build a valid configuration.
 type OrderConfiguration struct {
-	MarketIOC    *MarketOrderIOC    `json:"market_market_ioc"`
-	LimitGTC     *LimitOrderGTC     `json:"limit_limit_gtc"`
-	LimitGTD     *LimitOrderGTD     `json:"limit_limit_gtd"`
-	StopLimitGTC *StopLimitOrderGTC `json:"stop_limit_stop_limit_gtc"`
-	StopLimitGTD *StopLimitOrderGTD `json:"stop_limit_stop_limit_gtd"`
+	MarketIOC         *MarketOrderIOC         `json:"market_market_ioc,omitempty"`
+	MarketFOK         *MarketOrderFOK         `json:"market_market_fok,omitempty"`
+	SORLimitIOC       *SORLimitOrderIOC       `json:"sor_limit_ioc,omitempty"`
+	LimitGTC          *LimitOrderGTC          `json:"limit_limit_gtc,omitempty"`
+	LimitGTD          *LimitOrderGTD          `json:"limit_limit_gtd,omitempty"`
+	LimitFOK          *LimitOrderFOK          `json:"limit_limit_fok,omitempty"`
+	StopLimitGTC      *StopLimitOrderGTC      `json:"stop_limit_stop_limit_gtc,omitempty"`
+	StopLimitGTD      *StopLimitOrderGTD      `json:"stop_limit_stop_limit_gtd,omitempty"`
+	TriggerBracketGTC *TriggerBracketOrderGTC `json:"trigger_bracket_gtc,omitempty"`
+	TriggerBracketGTD *TriggerBracketOrderGTD `json:"trigger_bracket_gtd,omitempty"`
+}
+
+// OrderSize is the amount of an order, either in the base or the quote currency but never both.
+// Create one with BaseSize or QuoteSize.
+type OrderSize struct {
+	base  *string
+	quote *string
+}
+
+// BaseSize is an order amount in the base currency, i.e. BTC for 'BTC-USD'.
+func BaseSize(size string) OrderSize {
+	return OrderSize{base: String(size)}
+}
+
+// QuoteSize is an order amount in the quote currency, i.e. USD for 'BTC-USD'.
+func QuoteSize(size string) OrderSize {
+	return OrderSize{quote: String(size)}
+}
+
+// NewMarketIOC configures a market order that cancels any size it cannot fill immediately.
+func NewMarketIOC(size OrderSize) OrderConfiguration {
+	return OrderConfiguration{MarketIOC: &MarketOrderIOC{BaseSize: size.base, QuoteSize: size.quote}}
+}
+
+// NewMarketFOK configures a market order that is cancelled unless it can be filled in full immediately.
+func NewMarketFOK(size OrderSize) OrderConfiguration {
+	return OrderConfiguration{MarketFOK: &MarketOrderFOK{BaseSize: size.base, QuoteSize: size.quote}}
+}
+
+// NewSORLimitIOC configures a smart order routed limit order that cancels any size it cannot fill immediately.
+func NewSORLimitIOC(size OrderSize, limitPrice string) OrderConfiguration {
+	return OrderConfiguration{SORLimitIOC: &SORLimitOrderIOC{BaseSize: size.base, QuoteSize: size.quote, LimitPrice: String(limitPrice)}}
+}
+
+// NewLimitGTC configures a limit order that remains on the book until it is canceled.
+func NewLimitGTC(size OrderSize, limitPrice string, postOnly bool) OrderConfiguration {
+	return OrderConfiguration{LimitGTC: &LimitOrderGTC{BaseSize: size.base, QuoteSize: size.quote, LimitPrice: String(limitPrice), PostOnly: Bool(postOnly)}}
+}
+
+// NewLimitGTD configures a limit order that is canceled at the end time if it has not been filled.
+func NewLimitGTD(size OrderSize, limitPrice string, endTime time.Time, postOnly bool) OrderConfiguration {
+	return OrderConfiguration{LimitGTD: &LimitOrderGTD{BaseSize: size.base, QuoteSize: size.quote, LimitPrice: String(limitPrice), EndTime: Time(endTime), PostOnly: Bool(postOnly)}}
+}
+
+// NewLimitFOK configures a limit order that is cancelled unless it can be filled in full immediately.
+func NewLimitFOK(size OrderSize, limitPrice string) OrderConfiguration {
+	return OrderConfiguration{LimitFOK: &LimitOrderFOK{BaseSize: size.base, QuoteSize: size.quote, LimitPrice: String(limitPrice)}}
+}
+
+// NewStopLimitGTC configures a stop limit order that remains on the book until it is canceled.
+func NewStopLimitGTC(baseSize string, limitPrice string, stopPrice string, direction StopDirection) OrderConfiguration {
+	return OrderConfiguration{StopLimitGTC: &StopLimitOrderGTC{BaseSize: String(baseSize), LimitPrice: String(limitPrice), StopPrice: String(stopPrice), StopDirection: &direction}}
+}
+
+// NewStopLimitGTD configures a stop limit order that is canceled at the end time if it has not been filled.
+func NewStopLimitGTD(baseSize string, limitPrice string, stopPrice string, endTime time.Time, direction StopDirection) OrderConfiguration {
+	return OrderConfiguration{StopLimitGTD: &StopLimitOrderGTD{BaseSize: String(baseSize), LimitPrice: String(limitPrice), StopPrice: String(stopPrice), EndTime: Time(endTime), StopDirection: &direction}}
+}
+
+// NewTriggerBracketGTC configures a bracket order that remains on the book until it is canceled.
+func NewTriggerBracketGTC(baseSize string, limitPrice string, stopTriggerPrice string) OrderConfiguration {
+	return OrderConfiguration{TriggerBracketGTC: &TriggerBracketOrderGTC{BaseSize: String(baseSize), LimitPrice: String(limitPrice), StopTriggerPrice: String(stopTriggerPrice)}}
+}
+
+// NewTriggerBracketGTD configures a bracket order that is canceled at the end time if it has not been filled.
+func NewTriggerBracketGTD(baseSize string, limitPrice string, stopTriggerPrice string, endTime time.Time) OrderConfiguration {
+	return OrderConfiguration{TriggerBracketGTD: &TriggerBracketOrderGTD{BaseSize: String(baseSize), LimitPrice: String(limitPrice), StopTriggerPrice: String(stopTriggerPrice), EndTime: Time(endTime)}}
 }
@@ -11,9 +11,63 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
+// ErrTooManyOrderIDs - more orders were passed to Cancel than Coinbase allows in a single request.
+var ErrTooManyOrderIDs = errors.New("too many order IDs to cancel in one request")
+
+const tooManyOrderIDsMessage = "Too many orderIDs entered, limit is "
+
+// TooManyOrderIDsError is returned by Cancel when Coinbase rejects the request because it exceeds the
+// maximum number of orders that can be cancelled at once. Split the IDs into batches of at most Limit.
+// It matches ErrTooManyOrderIDs and ErrInvalidArgument with errors.Is.
+type TooManyOrderIDsError struct {
+	Limit     int            // Maximum number of orders per request reported by Coinbase.
+	Requested int            // Number of orders in the rejected request.
+	Err       *CoinbaseError // The error returned by Coinbase.
+}
+
+func (e *TooManyOrderIDsError) Error() string {
+	return fmt.Sprintf("too many order IDs: %d requested, limit is %d", e.Requested, e.Limit)
+}
+
+func (e *TooManyOrderIDsError) Is(target error) bool {
+	return target == ErrTooManyOrderIDs
+}
+
+func (e *TooManyOrderIDsError) Unwrap() error {
+	return e.Err
+}
+
+// parseTooManyOrderIDs returns a TooManyOrderIDsError if the error is Coinbase rejecting a cancel request
+// for having too many order IDs.
+func parseTooManyOrderIDs(err error, requested int) (*TooManyOrderIDsError, bool) {
+	var cbError *CoinbaseError
+	if !errors.As(err, &cbError) {
+		return nil, false
+	}
+
+	for _, msg := range []string{cbError.GetMessage(), cbError.getDescription()} {
+		i := strings.Index(msg, tooManyOrderIDsMessage)
+		if i < 0 {
+			continue
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(msg[i+len(tooManyOrderIDsMessage):]))
+		if err != nil {
+			continue
+		}
+
+		return &TooManyOrderIDsError{Limit: limit, Requested: requested, Err: cbError}, true
+	}
+
+	return nil, false
+}
+
 type CancelOrderFailureReason string
 
 const (
@@ -43,7 +97,9 @@ type cancelOrdersResponse struct {
 // The maximum number of order_ids that can be cancelled per request is 100.
 // This number may be subject to change in emergency, but if a request exceeds the max, then an
 // InvalidArgument error code will be returned with an error message denoting the limit
-// Too many orderIDs entered, limit is _.
+// Too many orderIDs entered, limit is _. This is returned as a *TooManyOrderIDsError.
+//
+// With WithStrictOrders a *CancelError is returned if any of the orders was not cancelled.
 func (s *OrdersService) Cancel(ctx context.Context, ids ...string) ([]CancelledOrder, error) {
 	b, err := json.Marshal(&cancelOrdersRequest{OrderIDs: ids})
 	if err != nil {
@@ -55,9 +111,18 @@ func (s *OrdersService) Cancel(ctx context.Context, ids ...string) ([]CancelledO
 	var cancelResp cancelOrdersResponse
 	err = s.client.post(ctx, u, bytes.NewBuffer(b), &cancelResp)
 	if err != nil {
-		// TODO: Parse and create sentinal error for: "Too many orderIDs entered, limit is _".
+		if tooMany, ok := parseTooManyOrderIDs(err, len(ids)); ok {
+			err = tooMany
+		}
+
 		return nil, fmt.Errorf("failed to cancel orders '%v': %w", ids, err)
 	}
 
-	return cancelResp.Results, err
+	if s.client.strictOrders {
+		if cancelErr := newCancelError(cancelResp.Results); cancelErr != nil {
+			return cancelResp.Results, cancelErr
+		}
+	}
+
+	return cancelResp.Results, nil
 }
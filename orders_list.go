@@ -28,6 +28,8 @@ type ListOrdersOptions struct {
 	StartDate            *time.Time            `url:"start_date,omitempty"`             // Start date to fetch orders from, inclusive.
 	EndDate              *time.Time            `url:"end_date,omitempty"`               // An optional end date for the query window, exclusive. If provided only orders with creation time before this date will be returned.
 	OrderType            *OrderType            `url:"order_type,omitempty"`             // Type of orders to return. Default is to return all order types.
+	OrderTypes           []OrderType           `url:"order_types,omitempty"`            // Only orders matching one of these types are returned. Default is to return all order types.
+	TimeInForces         []TimeInForce         `url:"time_in_forces,omitempty"`         // Only orders matching one of these time in force policies are returned. Default is to return all.
 	OrderSide            *OrderSide            `url:"order_side,omitempty"`             // Only orders matching this side are returned. Default is to return all sides.
 	Cursor               *string               `url:"cursor,omitempty"`                 // Cursor used for pagination. When provided, the response returns responses after this cursor.
 	ProductType          *ProductType          `url:"product_type,omitempty"`           // Only orders matching this product type are returned. Default is to return all product types.
@@ -66,3 +68,27 @@ func (s *OrdersService) List(ctx context.Context, options *ListOrdersOptions) (*
 
 	return &orderResp, err
 }
+
+// All returns a pager over every order matching the options, following the cursor until the last page.
+// The options are not modified.
+func (s *OrdersService) All(ctx context.Context, options *ListOrdersOptions, opts ...PagerOption) *Pager[Order] {
+	var query ListOrdersOptions
+	if options != nil {
+		query = *options
+	}
+
+	return newPager(ctx, query.Cursor, func(ctx context.Context, cursor *string) ([]Order, *string, error) {
+		query.Cursor = cursor
+
+		resp, err := s.List(ctx, &query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !resp.HasNext {
+			return resp.Orders, nil, nil
+		}
+
+		return resp.Orders, resp.Cursor, nil
+	}, opts)
+}
@@ -53,7 +53,7 @@ type Fill struct {
 	LiquidityIndicator *LiquidityIndicator `json:"liquidity_indicator"` // Possible values: [UNKNOWN_LIQUIDITY_INDICATOR, MAKER, TAKER]
 	SizeInQuote        *bool               `json:"size_in_quote"`       // Whether the order was placed with quote currency.
 	UserID             *string             `json:"user_id"`             // User that placed the order the fill belongs to.
-	Side               *Side               `json:""`                    // Side the fill is on [BUY, SELL].
+	Side               *Side               `json:"side"`                // Side the fill is on [BUY, SELL].
 }
 
 type ListFillsResponse struct {
@@ -72,3 +72,39 @@ func (s *OrdersService) ListFills(ctx context.Context, options *ListOrderFillsOp
 
 	return &fills, err
 }
+
+// AllFills returns a pager over every fill matching the options, following the cursor until the last page.
+// The options are not modified.
+func (s *OrdersService) AllFills(ctx context.Context, options *ListOrderFillsOptions, opts ...PagerOption) *Pager[Fill] {
+	var query ListOrderFillsOptions
+	if options != nil {
+		query = *options
+	}
+
+	return newPager(ctx, query.Cursor, func(ctx context.Context, cursor *string) ([]Fill, *string, error) {
+		query.Cursor = cursor
+
+		resp, err := s.ListFills(ctx, &query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The fills endpoint does not report has_next, the last page has an empty cursor.
+		return resp.Fills, resp.Cursor, nil
+	}, opts)
+}
+
+// PriceDecimal parses the price the fill was posted at.
+func (f Fill) PriceDecimal() (Decimal, error) {
+	return parseDecimalField("price", f.Price)
+}
+
+// SizeDecimal parses the amount transacted in the fill.
+func (f Fill) SizeDecimal() (Decimal, error) {
+	return parseDecimalField("size", f.Size)
+}
+
+// CommissionDecimal parses the fee charged for the fill.
+func (f Fill) CommissionDecimal() (Decimal, error) {
+	return parseDecimalField("commission", f.Commission)
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+func TestOrderConfigurationJSON(t *testing.T) {
+	end := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config coinbase.OrderConfiguration
+		want   string
+	}{
+		{
+			name:   "market ioc quote size",
+			config: coinbase.NewMarketIOC(coinbase.QuoteSize("10")),
+			want:   `{"market_market_ioc":{"quote_size":"10"}}`,
+		},
+		{
+			name:   "market fok base size",
+			config: coinbase.NewMarketFOK(coinbase.BaseSize("0.01")),
+			want:   `{"market_market_fok":{"base_size":"0.01"}}`,
+		},
+		{
+			name:   "sor limit ioc",
+			config: coinbase.NewSORLimitIOC(coinbase.BaseSize("0.01"), "42000"),
+			want:   `{"sor_limit_ioc":{"base_size":"0.01","limit_price":"42000"}}`,
+		},
+		{
+			name:   "limit gtc quote size",
+			config: coinbase.NewLimitGTC(coinbase.QuoteSize("100"), "42000", true),
+			want:   `{"limit_limit_gtc":{"quote_size":"100","limit_price":"42000","post_only":true}}`,
+		},
+		{
+			name:   "limit gtd",
+			config: coinbase.NewLimitGTD(coinbase.BaseSize("0.01"), "42000", end, false),
+			want:   `{"limit_limit_gtd":{"base_size":"0.01","limit_price":"42000","end_time":"2024-01-02T03:04:05Z","post_only":false}}`,
+		},
+		{
+			name:   "limit fok",
+			config: coinbase.NewLimitFOK(coinbase.BaseSize("0.01"), "42000"),
+			want:   `{"limit_limit_fok":{"base_size":"0.01","limit_price":"42000"}}`,
+		},
+		{
+			name:   "stop limit gtc",
+			config: coinbase.NewStopLimitGTC("0.01", "41000", "41500", coinbase.StopDirectionDown),
+			want:   `{"stop_limit_stop_limit_gtc":{"base_size":"0.01","limit_price":"41000","stop_price":"41500","stop_direction":"STOP_DIRECTION_STOP_DOWN"}}`,
+		},
+		{
+			name:   "stop limit gtd",
+			config: coinbase.NewStopLimitGTD("0.01", "43000", "42500", end, coinbase.StopDirectionUp),
+			want:   `{"stop_limit_stop_limit_gtd":{"base_size":"0.01","limit_price":"43000","stop_price":"42500","end_time":"2024-01-02T03:04:05Z","stop_direction":"STOP_DIRECTION_STOP_UP"}}`,
+		},
+		{
+			name:   "trigger bracket gtc",
+			config: coinbase.NewTriggerBracketGTC("0.01", "45000", "40000"),
+			want:   `{"trigger_bracket_gtc":{"base_size":"0.01","limit_price":"45000","stop_trigger_price":"40000"}}`,
+		},
+		{
+			name:   "trigger bracket gtd",
+			config: coinbase.NewTriggerBracketGTD("0.01", "45000", "40000", end),
+			want:   `{"trigger_bracket_gtd":{"base_size":"0.01","limit_price":"45000","stop_trigger_price":"40000","end_time":"2024-01-02T03:04:05Z"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.config)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+
+			if string(b) != tt.want {
+				t.Fatalf("marshalled to %s, want %s", b, tt.want)
+			}
+
+			var decoded coinbase.OrderConfiguration
+
+			err = json.Unmarshal(b, &decoded)
+			if err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+
+			if !reflect.DeepEqual(decoded, tt.config) {
+				t.Fatalf("round trip changed the configuration: %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestCreateOrderSendsConfiguration(t *testing.T) {
+	srv := newAPIServer(t, http.StatusOK, `{"success":true,"order_id":"1","success_response":{"order_id":"1","product_id":"BTC-USD","side":"BUY","client_order_id":"abc"}}`)
+	client := srv.client()
+
+	side := coinbase.SideBuy
+
+	_, err := client.Orders.Create(context.Background(), coinbase.CreateOrderOptions{
+		ClientOrderID:      "abc",
+		ProductID:          "BTC-USD",
+		Side:               &side,
+		OrderConfiguration: coinbase.NewTriggerBracketGTC("0.01", "45000", "40000"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body struct {
+		Configuration map[string]json.RawMessage `json:"order_configuration"`
+	}
+
+	err = json.Unmarshal([]byte(srv.last(t).Body), &body)
+	if err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	if len(body.Configuration) != 1 || body.Configuration["trigger_bracket_gtc"] == nil {
+		t.Fatalf("sent order configuration %v", body.Configuration)
+	}
+}
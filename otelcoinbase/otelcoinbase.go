@@ -0,0 +1,275 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+// Package otelcoinbase instruments the coinbase client with OpenTelemetry tracing and metrics.
+//
+// Every request made by the client is traced with a client span named after the endpoint, such as
+// coinbase.CreateOrder, and measured by a latency histogram and an error counter:
+//
+//	client, err := coinbase.NewWithCloud(apiKey, apiSecret, coinbase.WithMiddleware(otelcoinbase.Middleware()))
+//
+// A request retried with coinbase.WithRetryPolicy has a span for every attempt.
+//
+// Spans and metrics are keyed by the endpoint name and never carry the URL of the request, which holds
+// order, account and portfolio IDs. Authentication is added by the client after the middleware ran,
+// so credentials are never recorded. Request bodies, which hold order sizes and prices, are only
+// recorded on spans with WithRequestBody.
+package otelcoinbase
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter.
+const ScopeName = "github.com/justinsimmons/go-coinbase/otelcoinbase"
+
+// Attribute keys set on spans and metrics, in addition to the HTTP semantic conventions.
+const (
+	EndpointKey    = attribute.Key("coinbase.endpoint")     // Name of the endpoint, see coinbase.Endpoint.
+	AttemptKey     = attribute.Key("coinbase.attempt")      // Number of the attempt, greater than 1 for retries.
+	ErrorCodeKey   = attribute.Key("coinbase.error.code")   // gRPC code of a Coinbase error.
+	ErrorReasonKey = attribute.Key("coinbase.error.reason") // Reason of a Coinbase error, such as NOT_FOUND.
+	RequestIDKey   = attribute.Key("coinbase.request_id")   // X-Request-Id of the response, useful when contacting Coinbase support.
+	RequestBodyKey = attribute.Key("coinbase.request.body") // Body of the request, only recorded with WithRequestBody.
+)
+
+// Values of the error.type attribute for errors that did not come from Coinbase.
+const (
+	ErrorTypeAuthentication = "authentication" // The request could not be authenticated.
+	ErrorTypeTransport      = "transport"      // No response was received.
+	ErrorTypeResponse       = "response"       // The response could not be read or decoded.
+)
+
+// durationBuckets are the boundaries of the latency histogram in seconds, those recommended for HTTP clients.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// unknownEndpoint names requests to paths the client does not know, made with a custom base URL.
+const unknownEndpoint = "Unknown"
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	requestBody    bool
+}
+
+// Option configures the middleware.
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer, the global provider is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the provider of the meter, the global provider is used by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithRequestBody records the body of requests on their span. Bodies of order requests hold sizes and
+// prices, only opt in if the tracing backend may store them.
+func WithRequestBody() Option {
+	return func(c *config) {
+		c.requestBody = true
+	}
+}
+
+type instruments struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	config   config
+}
+
+// Middleware returns a coinbase.Middleware that traces and measures every request of the client.
+// Errors creating the instruments are reported to the global OpenTelemetry error handler.
+func Middleware(opts ...Option) coinbase.Middleware {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+
+	in := instruments{
+		tracer: cfg.tracerProvider.Tracer(ScopeName),
+		config: cfg,
+	}
+
+	var err error
+
+	in.duration, err = meter.Float64Histogram(
+		"coinbase.client.request.duration",
+		metric.WithDescription("Duration of requests to the Coinbase Advanced Trade REST API."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	in.errors, err = meter.Int64Counter(
+		"coinbase.client.request.errors",
+		metric.WithDescription("Number of failed requests to the Coinbase Advanced Trade REST API."),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return in.middleware
+}
+
+func (in instruments) middleware(next coinbase.Handler) coinbase.Handler {
+	return func(call *coinbase.Call) error {
+		endpoint := string(call.Endpoint)
+		if endpoint == "" {
+			endpoint = unknownEndpoint
+		}
+
+		attrs := []attribute.KeyValue{
+			EndpointKey.String(endpoint),
+			semconv.HTTPRequestMethodKey.String(call.Request.Method),
+		}
+
+		ctx, span := in.tracer.Start(call.Request.Context(), "coinbase."+endpoint,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+			trace.WithAttributes(
+				semconv.ServerAddress(call.Request.URL.Hostname()),
+				AttemptKey.Int(call.Attempt),
+			),
+		)
+		defer span.End()
+
+		if in.config.requestBody {
+			if body, ok := readBody(call); ok {
+				span.SetAttributes(RequestBodyKey.String(body))
+			}
+		}
+
+		// Spans of the HTTP transport, if it is instrumented, are children of this span.
+		call.Request = call.Request.WithContext(ctx)
+
+		start := time.Now()
+
+		err := next(call)
+
+		elapsed := time.Since(start)
+
+		if call.Response != nil {
+			attrs = append(attrs, semconv.HTTPResponseStatusCode(call.Response.StatusCode))
+			span.SetAttributes(semconv.HTTPResponseStatusCode(call.Response.StatusCode))
+
+			if id := call.Response.Header.Get("X-Request-Id"); id != "" {
+				span.SetAttributes(RequestIDKey.String(id))
+			}
+		}
+
+		if err != nil {
+			errorType, description := classify(call, err, span)
+
+			attrs = append(attrs, semconv.ErrorTypeKey.String(errorType))
+
+			span.SetAttributes(semconv.ErrorTypeKey.String(errorType))
+			span.SetStatus(codes.Error, description)
+
+			if in.errors != nil {
+				in.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+		}
+
+		if in.duration != nil {
+			in.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs...))
+		}
+
+		return err
+	}
+}
+
+// classify returns the error.type of the error and a description for the span status, and records
+// the Coinbase error code on the span. Errors decoding a response quote its body, and transport errors
+// quote the URL, so neither is described by its message.
+func classify(call *coinbase.Call, err error, span trace.Span) (string, string) {
+	var cbErr *coinbase.CoinbaseError
+	if errors.As(err, &cbErr) {
+		span.SetAttributes(ErrorCodeKey.Int(cbErr.GetCode()))
+
+		// Responses Coinbase does not document have no reason, only a status.
+		if cbErr.Err == nil || *cbErr.Err == "" || errors.Is(cbErr, coinbase.ErrUnexpectedAPIResponse) {
+			return strconv.Itoa(cbErr.StatusCode), err.Error()
+		}
+
+		span.SetAttributes(ErrorReasonKey.String(*cbErr.Err))
+
+		return *cbErr.Err, err.Error()
+	}
+
+	var authErr *coinbase.AuthenticationError
+	if errors.As(err, &authErr) {
+		return ErrorTypeAuthentication, err.Error()
+	}
+
+	if call.Response == nil {
+		return ErrorTypeTransport, transportDescription(err)
+	}
+
+	return ErrorTypeResponse, "failed to read or decode the response"
+}
+
+// transportDescription describes a transport error by the error underneath the *url.Error, which holds
+// the URL of the request.
+func transportDescription(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Err != nil {
+		return "failed to send the request: " + urlErr.Err.Error()
+	}
+
+	return "failed to send the request"
+}
+
+// readBody returns a copy of the body of the request, leaving the body that is sent untouched.
+func readBody(call *coinbase.Call) (string, bool) {
+	if call.Request.GetBody == nil {
+		return "", false
+	}
+
+	body, err := call.Request.GetBody()
+	if err != nil {
+		return "", false
+	}
+
+	defer body.Close()
+
+	buf, err := io.ReadAll(body)
+	if err != nil || len(buf) == 0 {
+		return "", false
+	}
+
+	return string(buf), true
+}
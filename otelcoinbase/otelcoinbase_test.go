@@ -0,0 +1,295 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package otelcoinbase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/otelcoinbase"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// span is a span recorded by tracer.
+type span struct {
+	noop.Span
+
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	desc   string
+	ended  bool
+}
+
+func (s *span) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *span) SetStatus(code codes.Code, desc string) { s.status, s.desc = code, desc }
+func (s *span) End(...trace.SpanEndOption)             { s.ended = true }
+
+// tracer records the spans it starts.
+type tracer struct {
+	noop.Tracer
+
+	spans []*span
+}
+
+type tracerProvider struct {
+	noop.TracerProvider
+	t *tracer
+}
+
+func (p tracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer { return p.t }
+
+func (t *tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+
+	s := &span{name: name, kind: cfg.SpanKind(), attrs: make(map[attribute.Key]attribute.Value)}
+	s.SetAttributes(cfg.Attributes()...)
+
+	t.spans = append(t.spans, s)
+
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// meter records the measurements of the duration histogram and the error counter.
+type meter struct {
+	metricnoop.Meter
+
+	durations []attribute.Set
+	errors    []attribute.Set
+}
+
+type histogram struct {
+	metricnoop.Float64Histogram
+	m *meter
+}
+
+func (h histogram) Record(_ context.Context, _ float64, opts ...metric.RecordOption) {
+	h.m.durations = append(h.m.durations, metric.NewRecordConfig(opts).Attributes())
+}
+
+type counter struct {
+	metricnoop.Int64Counter
+	m *meter
+}
+
+func (c counter) Add(_ context.Context, _ int64, opts ...metric.AddOption) {
+	c.m.errors = append(c.m.errors, metric.NewAddConfig(opts).Attributes())
+}
+
+type meterProvider struct {
+	metricnoop.MeterProvider
+	m *meter
+}
+
+func (p meterProvider) Meter(string, ...metric.MeterOption) metric.Meter { return p.m }
+
+func (m *meter) Float64Histogram(string, ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return histogram{m: m}, nil
+}
+
+func (m *meter) Int64Counter(string, ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return counter{m: m}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		closed      bool // Whether the server is closed before the request.
+		post        bool // Create an order instead of getting a product.
+		requestBody bool
+		endpoint    string
+		errorType   string // Expected error.type, empty if the call succeeds.
+		reason      string
+	}{
+		{name: "success", status: http.StatusOK, body: `{"product_id":"BTC-USD"}`, endpoint: "GetProduct"},
+		{name: "coinbase error", status: http.StatusNotFound, body: `{"error":"NOT_FOUND","code":5,"message":"product not found"}`, endpoint: "GetProduct", errorType: "NOT_FOUND", reason: "NOT_FOUND"},
+		{name: "undocumented error", status: http.StatusBadGateway, body: `<html>bad gateway</html>`, endpoint: "GetProduct", errorType: "502"},
+		{name: "undecodable response", status: http.StatusOK, body: `{"product_id":`, endpoint: "GetProduct", errorType: otelcoinbase.ErrorTypeResponse},
+		{name: "no response", closed: true, endpoint: "GetProduct", errorType: otelcoinbase.ErrorTypeTransport},
+		{name: "request body not recorded", status: http.StatusOK, body: `{"success":true}`, post: true, endpoint: "CreateOrder"},
+		{name: "request body recorded", status: http.StatusOK, body: `{"success":true}`, post: true, requestBody: true, endpoint: "CreateOrder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "request-1")
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			if tt.closed {
+				srv.Close()
+			}
+
+			tr := &tracer{}
+			m := &meter{}
+
+			opts := []otelcoinbase.Option{otelcoinbase.WithTracerProvider(tracerProvider{t: tr}), otelcoinbase.WithMeterProvider(meterProvider{m: m})}
+			if tt.requestBody {
+				opts = append(opts, otelcoinbase.WithRequestBody())
+			}
+
+			client := coinbase.NewWithLegacy("key", "secret", coinbase.WithBaseURL(srv.URL), coinbase.WithMiddleware(otelcoinbase.Middleware(opts...)))
+
+			var err error
+
+			if tt.post {
+				side := coinbase.SideBuy
+
+				_, err = client.Orders.Create(context.Background(), coinbase.CreateOrderOptions{
+					ProductID:          "BTC-USD",
+					Side:               &side,
+					OrderConfiguration: coinbase.NewMarketIOC(coinbase.QuoteSize("10")),
+				})
+			} else {
+				_, err = client.Products.Get(context.Background(), "BTC-USD")
+			}
+
+			if (err != nil) != (tt.errorType != "") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(tr.spans) != 1 {
+				t.Fatalf("%d spans, want 1", len(tr.spans))
+			}
+
+			s := tr.spans[0]
+
+			if s.name != "coinbase."+tt.endpoint || s.kind != trace.SpanKindClient || !s.ended {
+				t.Fatalf("span %s of kind %s, ended %t, want an ended client span coinbase.%s", s.name, s.kind, s.ended, tt.endpoint)
+			}
+
+			want := map[attribute.Key]string{
+				otelcoinbase.EndpointKey:    tt.endpoint,
+				otelcoinbase.AttemptKey:     "1",
+				"http.request.method":       http.MethodGet,
+				"server.address":            "127.0.0.1",
+				"error.type":                tt.errorType,
+				otelcoinbase.ErrorReasonKey: tt.reason,
+			}
+
+			if tt.post {
+				want["http.request.method"] = http.MethodPost
+			}
+
+			if !tt.closed {
+				want["http.response.status_code"] = attribute.IntValue(tt.status).Emit()
+				want[otelcoinbase.RequestIDKey] = "request-1"
+			}
+
+			for key, value := range want {
+				var got string
+				if v, ok := s.attrs[key]; ok {
+					got = v.Emit()
+				}
+
+				if got != value {
+					t.Errorf("attribute %s is '%s', want '%s'", key, got, value)
+				}
+			}
+
+			if body := s.attrs[otelcoinbase.RequestBodyKey].AsString(); (body != "") != tt.requestBody {
+				t.Errorf("request body is '%s', recorded: %t", body, tt.requestBody)
+			}
+
+			// The URL holds IDs, it must not be recorded.
+			for key := range s.attrs {
+				if key == "url.full" || key == "url.path" {
+					t.Errorf("span has attribute %s", key)
+				}
+			}
+
+			wantStatus := codes.Unset
+			if tt.errorType != "" {
+				wantStatus = codes.Error
+			}
+
+			if s.status != wantStatus {
+				t.Errorf("span status is %s, want %s", s.status, wantStatus)
+			}
+
+			if len(m.durations) != 1 {
+				t.Fatalf("%d durations recorded, want 1", len(m.durations))
+			}
+
+			failures := 0
+			if tt.errorType != "" {
+				failures = 1
+			}
+
+			if len(m.errors) != failures {
+				t.Fatalf("%d errors counted, want %d", len(m.errors), failures)
+			}
+
+			if endpoint, _ := m.durations[0].Value(otelcoinbase.EndpointKey); endpoint.AsString() != tt.endpoint {
+				t.Errorf("duration recorded for endpoint '%s', want '%s'", endpoint.AsString(), tt.endpoint)
+			}
+
+			if errorType, _ := m.durations[0].Value("error.type"); errorType.AsString() != tt.errorType {
+				t.Errorf("duration recorded with error.type '%s', want '%s'", errorType.AsString(), tt.errorType)
+			}
+		})
+	}
+}
+
+// failingTransport fails every request, as a transport error quoting the URL.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMiddlewareTransportErrorOmitsURL(t *testing.T) {
+	tr := &tracer{}
+
+	client := coinbase.NewWithLegacy("key", "secret",
+		coinbase.WithBaseURL("https://coinbase.test"),
+		coinbase.WithHTTPClient(&http.Client{Transport: failingTransport{}}),
+		coinbase.WithMiddleware(otelcoinbase.Middleware(otelcoinbase.WithTracerProvider(tracerProvider{t: tr}), otelcoinbase.WithMeterProvider(meterProvider{m: &meter{}}))),
+	)
+
+	_, err := client.Orders.Get(context.Background(), "secret-order-id")
+	if err == nil || !strings.Contains(err.Error(), "secret-order-id") {
+		t.Fatalf("error is %v, want a transport error quoting the URL", err)
+	}
+
+	if len(tr.spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(tr.spans))
+	}
+
+	s := tr.spans[0]
+
+	if s.status != codes.Error || !strings.Contains(s.desc, "connection refused") {
+		t.Fatalf("span status is %s '%s', want an error describing the transport failure", s.status, s.desc)
+	}
+
+	if strings.Contains(s.desc, "secret-order-id") || strings.Contains(s.desc, "coinbase.test") {
+		t.Fatalf("span status '%s' contains the URL", s.desc)
+	}
+}
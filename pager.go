@@ -0,0 +1,134 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+)
+
+// Fetches a single page of results starting at the cursor, returns the cursor of the next page
+// or nil if this was the last page.
+type pageFetcher[T any] func(ctx context.Context, cursor *string) (items []T, next *string, err error)
+
+type pagerOptions struct {
+	maxItems int
+}
+
+type PagerOption func(*pagerOptions)
+
+// WithMaxItems stops the pager after n items have been returned, no further pages are fetched.
+func WithMaxItems(n int) PagerOption {
+	return func(o *pagerOptions) {
+		o.maxItems = n
+	}
+}
+
+// Pager follows the cursor of a list endpoint, fetching the next page only once the current one
+// has been consumed.
+//
+//	pager := client.Orders.All(ctx, nil)
+//	for pager.Next() {
+//		order := pager.Value()
+//		// ...
+//	}
+//	if err := pager.Err(); err != nil {
+//		return err
+//	}
+type Pager[T any] struct {
+	ctx     context.Context
+	fetch   pageFetcher[T]
+	options pagerOptions
+
+	page    []T
+	current T
+	cursor  *string
+	done    bool
+	count   int
+	err     error
+}
+
+func newPager[T any](ctx context.Context, cursor *string, fetch pageFetcher[T], opts []PagerOption) *Pager[T] {
+	p := &Pager[T]{ctx: ctx, fetch: fetch, cursor: cursor}
+
+	for _, opt := range opts {
+		opt(&p.options)
+	}
+
+	return p
+}
+
+// NewPager returns a pager over the pages returned by fetch, for example to return from a mock of a service.
+// Fetch is called with the cursor of the page, nil for the first one, and returns its items and the cursor
+// of the next page. A nil or empty cursor ends the iteration.
+func NewPager[T any](ctx context.Context, fetch func(ctx context.Context, cursor *string) (items []T, next *string, err error), opts ...PagerOption) *Pager[T] {
+	return newPager(ctx, nil, fetch, opts)
+}
+
+// Next advances to the next item, fetching the next page if required. It returns false once every
+// page has been consumed, the max items cap is reached, the context is cancelled or a request fails.
+func (p *Pager[T]) Next() bool {
+	if p.err != nil {
+		return false
+	}
+
+	if p.options.maxItems > 0 && p.count >= p.options.maxItems {
+		return false
+	}
+
+	if err := p.ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	for len(p.page) == 0 {
+		if p.done {
+			return false
+		}
+
+		items, next, err := p.fetch(p.ctx, p.cursor)
+		if err != nil {
+			p.err = err
+			return false
+		}
+
+		// Stop if the cursor does not move on, otherwise the same page would be fetched forever.
+		if next == nil || *next == "" || (p.cursor != nil && *next == *p.cursor) {
+			p.done = true
+		}
+
+		p.page = items
+		p.cursor = next
+	}
+
+	p.current = p.page[0]
+	p.page = p.page[1:]
+	p.count++
+
+	return true
+}
+
+// Value returns the item the pager is positioned at by the last call to Next.
+func (p *Pager[T]) Value() T {
+	return p.current
+}
+
+// Err returns the error that stopped the pager, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// Collect consumes the remaining items of the pager. The items collected before an error are returned along with it.
+func (p *Pager[T]) Collect() ([]T, error) {
+	var items []T
+
+	for p.Next() {
+		items = append(items, p.Value())
+	}
+
+	return items, p.Err()
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+// pages serves the pages in order, the cursor of each page is its index.
+func pages(fetched *int, err error, items ...[]int) func(context.Context, *string) ([]int, *string, error) {
+	return func(ctx context.Context, cursor *string) ([]int, *string, error) {
+		i := 0
+		if cursor != nil {
+			i, _ = strconv.Atoi(*cursor)
+		}
+
+		*fetched++
+
+		if i >= len(items) {
+			return nil, nil, err
+		}
+
+		var next *string
+		if i+1 < len(items) || err != nil {
+			next = coinbase.String(strconv.Itoa(i + 1))
+		}
+
+		return items[i], next, nil
+	}
+}
+
+func TestPager(t *testing.T) {
+	failure := errors.New("request failed")
+
+	tests := []struct {
+		name    string
+		fetch   func(fetched *int) func(context.Context, *string) ([]int, *string, error)
+		opts    []coinbase.PagerOption
+		want    []int
+		fetched int
+		err     error
+	}{
+		{
+			name: "follows the cursor",
+			fetch: func(n *int) func(context.Context, *string) ([]int, *string, error) {
+				return pages(n, nil, []int{1, 2}, []int{3}, []int{4, 5})
+			},
+			want:    []int{1, 2, 3, 4, 5},
+			fetched: 3,
+		},
+		{
+			name: "skips empty pages",
+			fetch: func(n *int) func(context.Context, *string) ([]int, *string, error) {
+				return pages(n, nil, []int{1}, []int{}, []int{2})
+			},
+			want:    []int{1, 2},
+			fetched: 3,
+		},
+		{
+			name: "max items stops fetching",
+			fetch: func(n *int) func(context.Context, *string) ([]int, *string, error) {
+				return pages(n, nil, []int{1, 2}, []int{3, 4}, []int{5})
+			},
+			opts:    []coinbase.PagerOption{coinbase.WithMaxItems(3)},
+			want:    []int{1, 2, 3},
+			fetched: 2,
+		},
+		{
+			name: "error keeps the items before it",
+			fetch: func(n *int) func(context.Context, *string) ([]int, *string, error) {
+				return pages(n, failure, []int{1, 2})
+			},
+			want:    []int{1, 2},
+			fetched: 2,
+			err:     failure,
+		},
+		{
+			name: "cursor that does not move ends the iteration",
+			fetch: func(n *int) func(context.Context, *string) ([]int, *string, error) {
+				return func(ctx context.Context, cursor *string) ([]int, *string, error) {
+					*n++
+					return []int{*n}, coinbase.String("same"), nil
+				}
+			},
+			want:    []int{1, 2},
+			fetched: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetched int
+
+			got, err := coinbase.NewPager(context.Background(), tt.fetch(&fetched), tt.opts...).Collect()
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) || fetched != tt.fetched {
+				t.Fatalf("collected %v in %d fetches, want %v in %d", got, fetched, tt.want, tt.fetched)
+			}
+		})
+	}
+}
+
+func TestPagerCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var fetched int
+
+	_, err := coinbase.NewPager(ctx, pages(&fetched, nil, []int{1})).Collect()
+	if !errors.Is(err, context.Canceled) || fetched != 0 {
+		t.Fatalf("error %v after %d fetches, want context.Canceled before any", err, fetched)
+	}
+}
+
+func TestAccountsAll(t *testing.T) {
+	srv := coinbasetest.NewServer()
+	defer srv.Close()
+
+	for i := 0; i < 7; i++ {
+		srv.SetBalance(fmt.Sprintf("C%d", i), "1")
+	}
+
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []coinbase.PagerOption
+		want     int
+		requests int
+	}{
+		{name: "every page", want: 7, requests: 3},
+		{name: "max items", opts: []coinbase.PagerOption{coinbase.WithMaxItems(4)}, want: 4, requests: 2},
+	}
+
+	limit := int32(3)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.ResetRequests()
+
+			accounts, err := client.Accounts.All(context.Background(), &coinbase.AccountListOptions{Limit: &limit}, tt.opts...).Collect()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(accounts) != tt.want || len(srv.Requests()) != tt.requests {
+				t.Fatalf("%d accounts in %d requests, want %d in %d", len(accounts), len(srv.Requests()), tt.want, tt.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+// Package pkcs11signer adapts P-256 EC keys kept on a PKCS#11 token, such as an HSM or SoftHSM, to a
+// crypto.Signer for coinbase.NewWithSigner. The private key never leaves the token.
+//
+//	signer, err := pkcs11signer.Open(pkcs11signer.Config{
+//		Module:     "/usr/lib/softhsm/libsofthsm2.so",
+//		TokenLabel: "coinbase",
+//		PIN:        os.Getenv("PKCS11_PIN"),
+//		KeyLabel:   "trading-key",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer signer.Close()
+//
+//	client, err := coinbase.NewWithSigner(apiKey, signer)
+//
+// The package loads the module with cgo, without cgo it is empty.
+//
+// To try it with SoftHSM, create a token and import the EC private key of a cloud API key into it along
+// with its public key, under the same label. The key file can be deleted afterwards.
+//
+//	softhsm2-util --init-token --free --label coinbase --pin 1234 --so-pin 5678
+//	openssl pkcs8 -topk8 -nocrypt -in cdp_api_key.pem -outform DER -out key.der
+//	openssl ec -in cdp_api_key.pem -pubout -outform DER -out pub.der
+//	pkcs11-tool --module /usr/lib/softhsm/libsofthsm2.so --token-label coinbase --login --pin 1234 \
+//		--write-object key.der --type privkey --label trading-key
+//	pkcs11-tool --module /usr/lib/softhsm/libsofthsm2.so --token-label coinbase --login --pin 1234 \
+//		--write-object pub.der --type pubkey --label trading-key
+package pkcs11signer
@@ -0,0 +1,5 @@
+module github.com/justinsimmons/go-coinbase/pkcs11signer
+
+go 1.21.6
+
+require github.com/miekg/pkcs11 v1.1.1
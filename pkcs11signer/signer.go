@@ -0,0 +1,235 @@
+//go:build cgo
+
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package pkcs11signer
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+	"github.com/miekg/pkcs11/p11"
+)
+
+// oidP256 identifies the P-256 curve in the CKA_EC_PARAMS attribute of a key.
+var oidP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+// p256Size is the size of each coordinate of a P-256 point, and of each of r and s in a signature.
+const p256Size = 32
+
+// Config locates the key on a PKCS#11 token.
+type Config struct {
+	Module     string // Path of the PKCS#11 module, such as /usr/lib/softhsm/libsofthsm2.so.
+	TokenLabel string // Label of the token holding the key.
+	PIN        string // User PIN of the token.
+	KeyLabel   string // Label of both the private key and its public key.
+	KeyID      []byte // ID of the keys, optional. Used to tell keys sharing a label apart.
+}
+
+// Signer signs with a P-256 EC private key kept on a PKCS#11 token. It is safe for concurrent use,
+// signatures are made one at a time on a single session.
+type Signer struct {
+	session p11.Session
+	key     p11.PrivateKey
+	public  *ecdsa.PublicKey
+}
+
+var _ crypto.Signer = (*Signer)(nil)
+
+// Open loads the module, logs into the token and finds the key pair. Close the signer once done.
+func Open(cfg Config) (*Signer, error) {
+	module, err := p11.OpenModule(cfg.Module)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PKCS#11 module: %w", err)
+	}
+
+	slot, err := findSlot(module, cfg.TokenLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := slot.OpenSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PKCS#11 session: %w", err)
+	}
+
+	s := &Signer{session: session}
+
+	err = s.open(cfg)
+	if err != nil {
+		session.Close()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Signer) open(cfg Config) error {
+	err := s.session.Login(cfg.PIN)
+
+	// Sessions of an application share their login state.
+	if err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		return fmt.Errorf("failed to log into PKCS#11 token: %w", err)
+	}
+
+	private, err := s.session.FindObject(template(pkcs11.CKO_PRIVATE_KEY, cfg))
+	if err != nil {
+		return fmt.Errorf("failed to find private key '%s': %w", cfg.KeyLabel, err)
+	}
+
+	public, err := s.session.FindObject(template(pkcs11.CKO_PUBLIC_KEY, cfg))
+	if err != nil {
+		return fmt.Errorf("failed to find public key '%s': %w", cfg.KeyLabel, err)
+	}
+
+	s.key = p11.PrivateKey(private)
+
+	s.public, err = publicKey(public)
+	if err != nil {
+		return fmt.Errorf("failed to read public key '%s': %w", cfg.KeyLabel, err)
+	}
+
+	return nil
+}
+
+func findSlot(module p11.Module, label string) (p11.Slot, error) {
+	slots, err := module.Slots()
+	if err != nil {
+		return p11.Slot{}, fmt.Errorf("failed to list PKCS#11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, err := slot.TokenInfo()
+		if err != nil {
+			continue
+		}
+
+		// Labels are padded with spaces to 32 bytes.
+		if strings.TrimRight(info.Label, " \x00") == label {
+			return slot, nil
+		}
+	}
+
+	return p11.Slot{}, fmt.Errorf("no PKCS#11 token labelled '%s'", label)
+}
+
+func template(class uint, cfg Config) []*pkcs11.Attribute {
+	attrs := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, cfg.KeyLabel),
+	}
+
+	if len(cfg.KeyID) > 0 {
+		attrs = append(attrs, pkcs11.NewAttribute(pkcs11.CKA_ID, cfg.KeyID))
+	}
+
+	return attrs
+}
+
+// publicKey reads the P-256 public key from the CKA_EC_PARAMS and CKA_EC_POINT attributes of the object.
+func publicKey(obj p11.Object) (*ecdsa.PublicKey, error) {
+	params, err := obj.Attribute(pkcs11.CKA_EC_PARAMS)
+	if err != nil {
+		return nil, err
+	}
+
+	point, err := obj.Attribute(pkcs11.CKA_EC_POINT)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePublicKey(params, point)
+}
+
+func parsePublicKey(params, point []byte) (*ecdsa.PublicKey, error) {
+	var curve asn1.ObjectIdentifier
+
+	_, err := asn1.Unmarshal(params, &curve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode EC parameters: %w", err)
+	}
+
+	if !curve.Equal(oidP256) {
+		return nil, fmt.Errorf("unsupported EC curve %s, cloud API keys use P-256", curve)
+	}
+
+	// The point should be wrapped in a DER octet string, though some tokens leave it out.
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err != nil || len(rest) > 0 {
+		raw = point
+	}
+
+	// Validates the point is on the curve.
+	if _, err := ecdh.P256().NewPublicKey(raw); err != nil {
+		return nil, fmt.Errorf("invalid EC point: %w", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(raw[1 : 1+p256Size]),
+		Y:     new(big.Int).SetBytes(raw[1+p256Size:]),
+	}, nil
+}
+
+// Public returns the public key of the key pair.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign signs the SHA-256 digest on the token. As crypto.Signer requires, the signature is ASN.1 DER
+// encoded, while PKCS#11 tokens return r||s.
+func (s *Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.SHA256 || len(digest) != crypto.SHA256.Size() {
+		return nil, fmt.Errorf("unsupported digest, only SHA-256 is supported")
+	}
+
+	raw, err := s.key.Sign(pkcs11.Mechanism{Mechanism: pkcs11.CKM_ECDSA}, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign on PKCS#11 token: %w", err)
+	}
+
+	return rawToDER(raw)
+}
+
+// rawToDER converts an r||s ECDSA signature to ASN.1 DER.
+func rawToDER(raw []byte) ([]byte, error) {
+	if len(raw) != 2*p256Size {
+		return nil, fmt.Errorf("unexpected signature size %d", len(raw))
+	}
+
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(raw[:p256Size]),
+		S: new(big.Int).SetBytes(raw[p256Size:]),
+	})
+}
+
+// Close logs out of the token and closes the session.
+func (s *Signer) Close() error {
+	logoutErr := s.session.Logout()
+	closeErr := s.session.Close()
+
+	// The module logs out on its own once the last session closes.
+	if errors.Is(logoutErr, pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)) {
+		logoutErr = nil
+	}
+
+	return errors.Join(logoutErr, closeErr)
+}
@@ -0,0 +1,161 @@
+//go:build cgo
+
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package pkcs11signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestRawToDER(t *testing.T) {
+	high := "80" + strings.Repeat("00", 31) // 32 byte integer with its top bit set, DER pads it with a zero byte.
+
+	tests := []struct {
+		name string
+		raw  string
+		der  string // Empty if encoding fails.
+	}{
+		{name: "leading zeros dropped", raw: strings.Repeat("00", 31) + "01" + strings.Repeat("00", 31) + "02", der: "3006020101020102"},
+		{name: "top bit set is zero padded", raw: high + strings.Repeat("00", 31) + "01", der: "3026022100" + high + "020101"},
+		{name: "both full size", raw: strings.Repeat("7f", 32) + strings.Repeat("ff", 32), der: "3045" + "0220" + strings.Repeat("7f", 32) + "022100" + strings.Repeat("ff", 32)},
+		{name: "too short", raw: strings.Repeat("01", 63)},
+		{name: "too long", raw: strings.Repeat("01", 65)},
+		{name: "empty", raw: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := hex.DecodeString(tt.raw)
+			if err != nil {
+				t.Fatalf("invalid test vector: %v", err)
+			}
+
+			der, err := rawToDER(raw)
+
+			if tt.der == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %x", der)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := hex.EncodeToString(der); got != tt.der {
+				t.Fatalf("DER signature is %s, want %s", got, tt.der)
+			}
+		})
+	}
+}
+
+func TestRawToDERVerifies(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("header.claims"))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	// The r||s a token returns.
+	raw := make([]byte, 2*p256Size)
+	r.FillBytes(raw[:p256Size])
+	s.FillBytes(raw[p256Size:])
+
+	der, err := rawToDER(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], der) {
+		t.Fatal("signature does not verify")
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	pub, err := key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatalf("failed to convert key: %v", err)
+	}
+
+	point := pub.Bytes()
+
+	p256, err := asn1.Marshal(oidP256)
+	if err != nil {
+		t.Fatalf("failed to marshal curve: %v", err)
+	}
+
+	p384, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 34})
+	if err != nil {
+		t.Fatalf("failed to marshal curve: %v", err)
+	}
+
+	wrapped, err := asn1.Marshal(point)
+	if err != nil {
+		t.Fatalf("failed to marshal point: %v", err)
+	}
+
+	offCurve := append([]byte(nil), point...)
+	offCurve[len(offCurve)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		params []byte
+		point  []byte
+		ok     bool
+	}{
+		{name: "point in an octet string", params: p256, point: wrapped, ok: true},
+		{name: "bare point", params: p256, point: point, ok: true},
+		{name: "p-384", params: p384, point: wrapped},
+		{name: "invalid parameters", params: []byte{0x06}, point: wrapped},
+		{name: "point not on the curve", params: p256, point: offCurve},
+		{name: "truncated point", params: p256, point: point[:1+p256Size]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePublicKey(tt.params, tt.point)
+
+			if !tt.ok {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !got.Equal(&key.PublicKey) {
+				t.Fatal("parsed the wrong public key")
+			}
+		})
+	}
+}
@@ -20,6 +20,10 @@ type EditPortfolioOptions struct {
 	Name string `json:"name"`
 }
 
+type editPortfolioResponse struct {
+	Portfolio *Portfolio `json:"portfolio"`
+}
+
 // Edit modifies a portfolio by portfolio ID.
 func (s *PortfoliosService) Edit(ctx context.Context, id uuid.UUID, options EditPortfolioOptions) (*Portfolio, error) {
 	b, err := json.Marshal(options)
@@ -27,12 +31,12 @@ func (s *PortfoliosService) Edit(ctx context.Context, id uuid.UUID, options Edit
 		return nil, fmt.Errorf("failed to marshal edit portfolio options to JSON: %w", err)
 	}
 
-	var portfolio Portfolio
+	var portfolioResp editPortfolioResponse
 
-	err = s.client.put(ctx, fmt.Sprintf("%s/api/v3/brokerage/portfolios/%s", s.client.baseURL, id.String()), bytes.NewReader(b), &portfolio)
+	err = s.client.put(ctx, fmt.Sprintf("%s/api/v3/brokerage/portfolios/%s", s.client.baseURL, id.String()), bytes.NewReader(b), &portfolioResp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to edit protfolio '%s': %w", id.String(), err)
 	}
 
-	return &portfolio, nil
+	return portfolioResp.Portfolio, nil
 }
@@ -39,3 +39,8 @@ type Funds struct {
 	Value    string `json:"value"`    // These two values fully represent the monetary amount. Non-localized amount in decimal notation (e.g. "1.234").
 	Currency string `json:"currency"` // Currency symbol (USD, BTC, etc). Not an asset UUID.
 }
+
+// Decimal parses the monetary amount.
+func (f Funds) Decimal() (Decimal, error) {
+	return parseDecimalField("funds", &f.Value)
+}
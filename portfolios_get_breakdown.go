@@ -9,6 +9,7 @@ package coinbase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -25,16 +26,41 @@ type PortfolioBalances struct {
 }
 
 type SpotPosition struct {
-	Asset                *string    `json:"asset"`
-	AccountUUID          *uuid.UUID `json:"account_uuid"`
-	TotalBalanceFiat     *float32   `json:"total_balance_fiat"`
-	TotalBalanceCrypto   *float32   `json:"total_balance_crypto"`
-	AvailableToTradeFiat *float32   `json:"available_to_trade_fiat"`
-	Allocation           *float32   `json:"allocation"`
-	OneDayChange         *float32   `json:"one_day_change"`
-	CostBasis            Funds      `json:"cost_basis"` // Represents a monetary amount.
-	AssetImageURL        *string    `json:"asset_img_url"`
-	IsCash               *bool      `json:"is_cash"`
+	Asset                *string      `json:"asset"`
+	AccountUUID          *uuid.UUID   `json:"account_uuid"`
+	TotalBalanceFiat     *json.Number `json:"total_balance_fiat"`
+	TotalBalanceCrypto   *json.Number `json:"total_balance_crypto"`
+	AvailableToTradeFiat *json.Number `json:"available_to_trade_fiat"`
+	Allocation           *json.Number `json:"allocation"`
+	OneDayChange         *json.Number `json:"one_day_change"`
+	CostBasis            Funds        `json:"cost_basis"` // Represents a monetary amount.
+	AssetImageURL        *string      `json:"asset_img_url"`
+	IsCash               *bool        `json:"is_cash"`
+}
+
+// TotalBalanceFiatDecimal parses the fiat value of the position.
+func (p SpotPosition) TotalBalanceFiatDecimal() (Decimal, error) {
+	return parseNumberField("total_balance_fiat", p.TotalBalanceFiat)
+}
+
+// TotalBalanceCryptoDecimal parses the size of the position.
+func (p SpotPosition) TotalBalanceCryptoDecimal() (Decimal, error) {
+	return parseNumberField("total_balance_crypto", p.TotalBalanceCrypto)
+}
+
+// AvailableToTradeFiatDecimal parses the fiat value of the position available to trade.
+func (p SpotPosition) AvailableToTradeFiatDecimal() (Decimal, error) {
+	return parseNumberField("available_to_trade_fiat", p.AvailableToTradeFiat)
+}
+
+// AllocationDecimal parses the share of the portfolio held in the position.
+func (p SpotPosition) AllocationDecimal() (Decimal, error) {
+	return parseNumberField("allocation", p.Allocation)
+}
+
+// OneDayChangeDecimal parses the change in value of the position over the last day.
+func (p SpotPosition) OneDayChangeDecimal() (Decimal, error) {
+	return parseNumberField("one_day_change", p.OneDayChange)
 }
 
 type Currency struct {
@@ -43,30 +69,40 @@ type Currency struct {
 }
 
 type PerpPosition struct {
-	ProductID        *string              `json:""`
-	ProductUUID      *uuid.UUID           `json:""`
-	Symbol           *string              `json:""`
+	ProductID        *string              `json:"product_id"`
+	ProductUUID      *uuid.UUID           `json:"product_uuid"`
+	Symbol           *string              `json:"symbol"`
 	AssetImageURL    *string              `json:"asset_img_url"`
-	VWAP             *Currency            `json:""`
-	PositionSide     *FuturesPositionSide `json:""`
-	NetSize          *string              `json:""`
-	BuyOrderSize     *string              `json:""`
-	SellOrderSize    *string              `json:""`
-	IMContribution   *string              `json:""`
-	UnrealizedPNL    *Currency
-	MarkPrice        *Currency
-	LiquidationPrice *Currency
-	Leverage         *string
-	IMNotional       *Currency
-	MMNotional       *Currency
-	PositionNotional *Currency
+	VWAP             *Currency            `json:"vwap"`
+	PositionSide     *FuturesPositionSide `json:"position_side"`
+	NetSize          *string              `json:"net_size"`
+	BuyOrderSize     *string              `json:"buy_order_size"`
+	SellOrderSize    *string              `json:"sell_order_size"`
+	IMContribution   *string              `json:"im_contribution"`
+	UnrealizedPNL    *Currency            `json:"unrealized_pnl"`
+	MarkPrice        *Currency            `json:"mark_price"`
+	LiquidationPrice *Currency            `json:"liquidation_price"`
+	Leverage         *string              `json:"leverage"`
+	IMNotional       *Currency            `json:"im_notional"`
+	MMNotional       *Currency            `json:"mm_notional"`
+	PositionNotional *Currency            `json:"position_notional"`
+}
+
+// NetSizeDecimal parses the net size of the position.
+func (p PerpPosition) NetSizeDecimal() (Decimal, error) {
+	return parseDecimalField("net_size", p.NetSize)
+}
+
+// LeverageDecimal parses the leverage of the position.
+func (p PerpPosition) LeverageDecimal() (Decimal, error) {
+	return parseDecimalField("leverage", p.Leverage)
 }
 
 type PortfolioBreakdown struct {
-	Portfolio     *Portfolio         `json:""`
-	Balances      *PortfolioBalances `json:""`
+	Portfolio     *Portfolio         `json:"portfolio"`
+	Balances      *PortfolioBalances `json:"portfolio_balances"`
 	SpotPositions []SpotPosition     `json:"spot_positions"`
-	PerpPositions []PerpPosition     `json:""`
+	PerpPositions []PerpPosition     `json:"perp_positions"`
 }
 
 type portfolioBreakdownResponse struct {
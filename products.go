@@ -109,3 +109,58 @@ type Product struct {
 	PriceIncrement                *string                   `json:"price_increment"`      // Minimum amount price can be increased or decreased at once.
 	FutureProductDetails          *FutureProductDetails     `json:"future_product_details"`
 }
+
+// PriceDecimal parses the price of the level.
+func (b BidAsk) PriceDecimal() (Decimal, error) {
+	return parseDecimalField("price", b.Price)
+}
+
+// SizeDecimal parses the size resting at the level.
+func (b BidAsk) SizeDecimal() (Decimal, error) {
+	return parseDecimalField("size", b.Size)
+}
+
+// PriceDecimal parses the current price of the product.
+func (p Product) PriceDecimal() (Decimal, error) {
+	return parseDecimalField("price", &p.Price)
+}
+
+// PriceIncrementDecimal parses the minimum amount the price can change by, falling back to the
+// quote increment for products that do not report a price increment.
+func (p Product) PriceIncrementDecimal() (Decimal, error) {
+	if p.PriceIncrement != nil && *p.PriceIncrement != "" {
+		return parseDecimalField("price_increment", p.PriceIncrement)
+	}
+
+	return p.QuoteIncrementDecimal()
+}
+
+// BaseIncrementDecimal parses the minimum amount the base size can change by.
+func (p Product) BaseIncrementDecimal() (Decimal, error) {
+	return parseDecimalField("base_increment", &p.BaseIncrement)
+}
+
+// QuoteIncrementDecimal parses the minimum amount the quote size can change by.
+func (p Product) QuoteIncrementDecimal() (Decimal, error) {
+	return parseDecimalField("quote_increment", &p.QuoteIncrement)
+}
+
+// BaseMinimumSizeDecimal parses the smallest order size in base currency.
+func (p Product) BaseMinimumSizeDecimal() (Decimal, error) {
+	return parseDecimalField("base_min_size", &p.BaseMinimimSize)
+}
+
+// BaseMaximumSizeDecimal parses the largest order size in base currency.
+func (p Product) BaseMaximumSizeDecimal() (Decimal, error) {
+	return parseDecimalField("base_max_size", &p.BaseMaximumSize)
+}
+
+// QuoteMinimumSizeDecimal parses the smallest order size in quote currency.
+func (p Product) QuoteMinimumSizeDecimal() (Decimal, error) {
+	return parseDecimalField("quote_min_size", &p.QuoteMinimumSize)
+}
+
+// QuoteMaximumSizeDecimal parses the largest order size in quote currency.
+func (p Product) QuoteMaximumSizeDecimal() (Decimal, error) {
+	return parseDecimalField("quote_max_size", &p.QuoteMaximumSize)
+}
@@ -62,3 +62,27 @@ func (s *ProductsService) GetProductCandles(ctx context.Context, options GetProd
 
 	return candlesResp.Candles, err
 }
+
+// Duration returns the length of a single candle, zero if the granularity is unknown.
+func (g TimeGranularity) Duration() time.Duration {
+	switch g {
+	case TimeGranularityOneMinute:
+		return time.Minute
+	case TimeGranularityFiveMinutes:
+		return 5 * time.Minute
+	case TimeGranularityFifteenMinutes:
+		return 15 * time.Minute
+	case TimeGranularityThirtyMinutes:
+		return 30 * time.Minute
+	case TimeGranularityOneHour:
+		return time.Hour
+	case TimeGranularityTwoHours:
+		return 2 * time.Hour
+	case TimeGranularitySixHours:
+		return 6 * time.Hour
+	case TimeGranularityOneDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
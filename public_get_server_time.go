@@ -34,6 +34,38 @@ func (ut CoinbaseServerTime) UnixMilli() (time.Time, error) {
 	return time.UnixMilli(i), nil
 }
 
+// Unix returns the local Time corresponding to the given Unix time, sec seconds since January 1, 1970 UTC.
+func (ut CoinbaseServerTime) Unix() (time.Time, error) {
+	if ut.EpochSeconds == nil {
+		return time.Time{}, fmt.Errorf("unable to determine time from null EpochSeconds")
+	}
+
+	i, err := strconv.ParseInt(*ut.EpochSeconds, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse epoch-seconds: '%s' as int: %w", *ut.EpochSeconds, err)
+	}
+
+	return time.Unix(i, 0), nil
+}
+
+// Time returns the time parsed from the ISO-8601 representation, which is the most precise. If Coinbase
+// left it out the epoch milliseconds are used instead, then the epoch seconds.
+func (ut CoinbaseServerTime) Time() (time.Time, error) {
+	switch {
+	case ut.ISO != nil:
+		t, err := time.Parse(time.RFC3339Nano, *ut.ISO)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse ISO-8601 time: '%s': %w", *ut.ISO, err)
+		}
+
+		return t, nil
+	case ut.EpochMillis != nil:
+		return ut.UnixMilli()
+	default:
+		return ut.Unix()
+	}
+}
+
 // GetServerTime gets the current time from the Coinbase Advanced API.
 // https://docs.cdp.coinbase.com/advanced-trade/reference/retailbrokerageapi_getservertime/
 func (s *PublicService) GetServerTime(ctx context.Context) (*CoinbaseServerTime, error) {
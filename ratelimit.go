@@ -0,0 +1,196 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPublicRequestsPerSecond  = 10 // Coinbase limits public endpoints to 10 requests per second per IP.
+	defaultPrivateRequestsPerSecond = 30 // Coinbase limits private endpoints to 30 requests per second per user.
+)
+
+// RateLimit configures a token bucket that allows Rate requests per second on average, and up to
+// Burst requests at once after the bucket had time to fill.
+type RateLimit struct {
+	Rate  float64 // Requests per second, a rate of 0 disables the limit.
+	Burst int     // Maximum number of requests sent at once, defaults to the rate rounded up.
+}
+
+// RateLimits configures the limits of the public and private endpoints separately, as Coinbase does.
+type RateLimits struct {
+	Public  RateLimit // Limit of the unauthenticated market data endpoints used by PublicService.
+	Private RateLimit // Limit of every other endpoint.
+}
+
+// DefaultRateLimits returns the limits Coinbase enforces on the Advanced Trade REST API.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Public:  RateLimit{Rate: defaultPublicRequestsPerSecond, Burst: defaultPublicRequestsPerSecond},
+		Private: RateLimit{Rate: defaultPrivateRequestsPerSecond, Burst: defaultPrivateRequestsPerSecond},
+	}
+}
+
+// WithRateLimit throttles the requests made by the client so they stay within the given limits, which
+// makes it safe to share a single client between many goroutines. Requests over the limit block until
+// they are allowed or their context is done. By default requests are not throttled.
+func WithRateLimit(limits RateLimits) func(*Client) {
+	return func(c *Client) {
+		c.publicLimiter = newTokenBucket(limits.Public)
+		c.privateLimiter = newTokenBucket(limits.Private)
+	}
+}
+
+// RateLimitUsage is a snapshot of a rate limiter.
+type RateLimitUsage struct {
+	Rate      float64 // Requests per second allowed on average.
+	Burst     int     // Maximum number of requests sent at once.
+	Available float64 // Requests that can be sent right now without waiting.
+	Used      float64 // Part of the burst used by recent requests, Burst minus Available.
+	Waiting   int     // Number of requests currently blocked by the limiter.
+}
+
+// RateLimitsUsage is a snapshot of the client's public and private rate limiters.
+type RateLimitsUsage struct {
+	Public  RateLimitUsage
+	Private RateLimitUsage
+}
+
+// RateLimitUsage returns the current usage of the client's rate limiters. Limiters that are not
+// configured report zero usage.
+func (c *Client) RateLimitUsage() RateLimitsUsage {
+	return RateLimitsUsage{
+		Public:  c.publicLimiter.usage(),
+		Private: c.privateLimiter.usage(),
+	}
+}
+
+// limiter returns the rate limiter the request counts against, or nil if it is not throttled.
+func (c *Client) limiter(r *http.Request) *tokenBucket {
+	if isPublicEndpoint(r.URL.Path) {
+		return c.publicLimiter
+	}
+
+	return c.privateLimiter
+}
+
+// isPublicEndpoint reports whether the path is one of the unauthenticated endpoints that Coinbase
+// rate limits separately.
+func isPublicEndpoint(path string) bool {
+	return strings.Contains(path, "/api/v3/brokerage/market/") || strings.HasSuffix(path, "/api/v3/brokerage/time")
+}
+
+// tokenBucket is a token bucket rate limiter safe for concurrent use. A nil bucket allows everything.
+type tokenBucket struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	tokens  float64
+	last    time.Time
+	waiting int
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(limit.Rate))
+	}
+
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens accumulated since the last refill, the lock must be held.
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// wait blocks until a request is allowed or the context is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	// A request that is already cancelled must not take a token from the ones that will be sent.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+
+	b.refill(time.Now())
+
+	// Take the token straight away, going into debt if the bucket is empty. Later requests wait for the
+	// debt to be paid off as well, so waiting requests are served in order.
+	b.tokens--
+
+	if b.tokens >= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.waiting++
+
+	b.mu.Unlock()
+
+	err := sleep(ctx, delay)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.waiting--
+
+	if err != nil {
+		// The request was never sent, give its token back.
+		b.refill(time.Now())
+		b.tokens = math.Min(float64(b.burst), b.tokens+1)
+
+		return err
+	}
+
+	return nil
+}
+
+func (b *tokenBucket) usage() RateLimitUsage {
+	if b == nil {
+		return RateLimitUsage{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+
+	available := math.Max(0, b.tokens)
+
+	return RateLimitUsage{
+		Rate:      b.rate,
+		Burst:     b.burst,
+		Available: available,
+		Used:      float64(b.burst) - available,
+		Waiting:   b.waiting,
+	}
+}
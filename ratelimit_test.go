@@ -0,0 +1,181 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurst(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit RateLimit
+		burst int // Requests allowed without waiting.
+	}{
+		{"explicit burst", RateLimit{Rate: 10, Burst: 3}, 3},
+		{"burst defaults to the rate", RateLimit{Rate: 5}, 5},
+		{"fractional rate is rounded up", RateLimit{Rate: 2.5}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(tt.limit)
+
+			start := time.Now()
+
+			for i := 0; i < tt.burst; i++ {
+				if err := b.wait(context.Background()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+				t.Fatalf("burst of %d took %s", tt.burst, elapsed)
+			}
+
+			if u := b.usage(); u.Available >= 1 || u.Burst != tt.burst {
+				t.Fatalf("usage %+v after the burst", u)
+			}
+		})
+	}
+}
+
+func TestTokenBucketThrottles(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 100, Burst: 1})
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := b.wait(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// One request is sent straight away, the others wait 10ms each.
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Fatalf("6 requests at 100/s sent in %s", elapsed)
+	}
+}
+
+func TestTokenBucketContext(t *testing.T) {
+	t.Run("cancelled context takes no token", func(t *testing.T) {
+		b := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := b.wait(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("error %v, want %v", err, context.Canceled)
+		}
+
+		if u := b.usage(); u.Available < 1 {
+			t.Fatalf("token taken by a cancelled request, usage %+v", u)
+		}
+	})
+
+	t.Run("token is given back when the wait is interrupted", func(t *testing.T) {
+		b := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+
+		if err := b.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := b.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("error %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		if u := b.usage(); u.Waiting != 0 || u.Available > 0.1 || b.tokens < -0.1 {
+			t.Fatalf("usage %+v with %f tokens after the interrupted wait", u, b.tokens)
+		}
+	})
+}
+
+func TestNilTokenBucket(t *testing.T) {
+	var b *tokenBucket
+
+	if newTokenBucket(RateLimit{}) != nil {
+		t.Fatal("zero rate must disable the limit")
+	}
+
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if u := b.usage(); u != (RateLimitUsage{}) {
+		t.Fatalf("usage %+v, want zero", u)
+	}
+}
+
+func TestIsPublicEndpoint(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/api/v3/brokerage/market/products":         true,
+		"/api/v3/brokerage/market/products/BTC-USD": true,
+		"/api/v3/brokerage/time":                    true,
+		"/api/v3/brokerage/products":                false,
+		"/api/v3/brokerage/orders":                  false,
+		"/api/v3/brokerage/accounts":                false,
+	} {
+		if isPublicEndpoint(path) != want {
+			t.Errorf("isPublicEndpoint(%s) = %t, want %t", path, !want, want)
+		}
+	}
+}
+
+func TestClientRateLimitsPublicAndPrivateSeparately(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"product_id":"BTC-USD"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimit(RateLimits{
+		Public:  RateLimit{Rate: 0.1, Burst: 1},
+		Private: RateLimit{Rate: 0.1, Burst: 1},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Products.Get(ctx, "BTC-USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The private limit is used up, the public one is not.
+	_, err = c.Public.GetProduct(ctx, "BTC-USD")
+	if err != nil {
+		t.Fatalf("public request throttled by the private limit: %v", err)
+	}
+
+	_, err = c.Products.Get(ctx, "BTC-USD")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want the request to wait for the rate limit", err)
+	}
+
+	if u := c.RateLimitUsage(); u.Private.Used < 0.9 || u.Public.Used < 0.9 {
+		t.Fatalf("usage %+v", u)
+	}
+}
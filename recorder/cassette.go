@@ -0,0 +1,211 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Cassette is the content of a cassette file, the interactions in the order they were recorded.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. The query and body are canonicalised so requests match regardless of
+// the order of query parameters and JSON fields.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// matches reports whether the requests are the same: same method, path, query and body.
+func (r Request) matches(other Request) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.Query == other.Query && r.Body == other.Body
+}
+
+// Matcher reports whether a request matches a recorded one. The query and body of both are canonicalised.
+type Matcher func(recorded Request, req Request) bool
+
+// DefaultMatcher matches requests with the same method, path, query and body.
+func DefaultMatcher(recorded Request, req Request) bool {
+	return recorded.matches(req)
+}
+
+// IgnoreBodyFields matches requests like DefaultMatcher, except that the fields of JSON bodies with one of
+// the names are ignored wherever they appear. Use it for values that change on every run, e.g. the
+// client_order_id generated by Place:
+//
+//	rec, err := recorder.New(path, recorder.ModeAuto, recorder.WithMatcher(recorder.IgnoreBodyFields("client_order_id")))
+func IgnoreBodyFields(fields ...string) Matcher {
+	ignored := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		ignored[f] = true
+	}
+
+	return func(recorded Request, req Request) bool {
+		return recorded.Method == req.Method && recorded.Path == req.Path && recorded.Query == req.Query &&
+			withoutFields(recorded.Body, ignored) == withoutFields(req.Body, ignored)
+	}
+}
+
+// withoutFields removes the fields from the JSON body and re-encodes it canonically. Other bodies are
+// returned as they are.
+func withoutFields(body string, fields map[string]bool) string {
+	d := json.NewDecoder(strings.NewReader(body))
+	d.UseNumber()
+
+	var v any
+
+	if err := d.Decode(&v); err != nil || d.More() {
+		return body
+	}
+
+	b, err := json.Marshal(removeFields(v, fields))
+	if err != nil {
+		return body
+	}
+
+	return string(b)
+}
+
+func removeFields(v any, fields map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if fields[key] {
+				delete(v, key)
+				continue
+			}
+
+			v[key] = removeFields(value, fields)
+		}
+	case []any:
+		for n, value := range v {
+			v[n] = removeFields(value, fields)
+		}
+	}
+
+	return v
+}
+
+// newRequest records the request with the body that was read from it.
+func newRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  canonicalQuery(req.URL.Query()),
+		Header: req.Header.Clone(),
+		Body:   canonicalBody(body),
+	}
+}
+
+// canonicalQuery encodes the query sorted by key, and the values of every key sorted too.
+func canonicalQuery(query url.Values) string {
+	for _, values := range query {
+		sort.Strings(values)
+	}
+
+	return query.Encode()
+}
+
+// canonicalBody re-encodes JSON bodies with sorted keys and without insignificant whitespace. Other bodies
+// are kept as they are.
+func canonicalBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+
+	var v any
+
+	if err := d.Decode(&v); err != nil || d.More() {
+		return string(body)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+
+	return string(b)
+}
+
+// load reads the cassette file.
+func load(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cassette '%s': %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// save writes the cassette file, creating its directory if needed.
+func (c *Cassette) save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette to JSON: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// exists reports whether the cassette file exists.
+func exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to stat cassette: %w", err)
+	}
+}
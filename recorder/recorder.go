@@ -0,0 +1,293 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+// Package recorder records HTTP interactions with Coinbase to a cassette file and replays them, so
+// integration tests can run offline and deterministically against traffic captured once.
+//
+// A Recorder is an http.RoundTripper, it is used through the HTTP client of the coinbase client:
+//
+//	rec, err := recorder.New("testdata/accounts.json", recorder.ModeAuto)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//
+//	client, err := coinbase.NewWithCloud(apiKey, apiSecret, coinbase.WithHTTPClient(rec.Client()))
+//
+// Requests are matched on their method, path, query and body. Queries are compared regardless of the
+// order of their parameters and JSON bodies regardless of the order of their fields and of whitespace.
+// Use WithMatcher to match requests differently, e.g. IgnoreBodyFields to ignore generated client order IDs.
+//
+// Credentials never reach the cassette: the Authorization, CB-ACCESS-KEY and CB-ACCESS-SIGN headers are
+// removed and account UUIDs are replaced by placeholders. Use WithScrubber to remove anything else.
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned in replay mode for a request that matches no unused recorded interaction.
+var ErrNoInteraction = errors.New("recorder: no recorded interaction matches the request")
+
+// Mode determines whether requests are sent to Coinbase or replayed from the cassette.
+type Mode int
+
+const (
+	ModeReplay Mode = iota // Replay the interactions of the cassette, requests never leave the process.
+	ModeRecord             // Send requests and record them, replacing the content of the cassette.
+	ModeAuto               // Replay if the cassette exists, otherwise record.
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModeAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithTransport sets the transport requests are sent with in record mode, http.DefaultTransport by default.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		if transport != nil {
+			r.transport = transport
+		}
+	}
+}
+
+// WithScrubber adds a scrubber applied to every interaction before it is written to the cassette, after
+// the credentials and account UUIDs were scrubbed.
+func WithScrubber(scrubber Scrubber) Option {
+	return func(r *Recorder) {
+		if scrubber != nil {
+			r.scrubbers = append(r.scrubbers, scrubber)
+		}
+	}
+}
+
+// WithMatcher sets how replayed requests are matched with the recorded ones, DefaultMatcher by default.
+func WithMatcher(matcher Matcher) Option {
+	return func(r *Recorder) {
+		if matcher != nil {
+			r.matcher = matcher
+		}
+	}
+}
+
+// Recorder is an http.RoundTripper recording interactions to or replaying them from a cassette file.
+// It is safe for concurrent use.
+type Recorder struct {
+	path      string
+	mode      Mode // ModeReplay or ModeRecord, ModeAuto is resolved by New.
+	transport http.RoundTripper
+	scrubbers []Scrubber
+	matcher   Matcher
+
+	mu           sync.Mutex
+	interactions []Interaction // Recorded interactions, before scrubbing.
+	cassette     *Cassette     // Replayed cassette.
+	used         []bool        // Interactions of the cassette that were replayed.
+}
+
+// New returns a recorder for the cassette file at the path. In replay mode the cassette must exist, in
+// record mode it is created or overwritten as soon as the first interaction is recorded.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := Recorder{path: path, mode: mode, transport: http.DefaultTransport, matcher: DefaultMatcher}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&r)
+		}
+	}
+
+	if r.mode == ModeAuto {
+		ok, err := exists(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mode = ModeRecord
+		if ok {
+			r.mode = ModeReplay
+		}
+	}
+
+	switch r.mode {
+	case ModeReplay:
+		c, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("invalid recorder mode %s", r.mode)
+	}
+
+	return &r, nil
+}
+
+// Mode returns the mode of the recorder, ModeAuto resolved to the mode in use.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client using the recorder as transport, pass it to coinbase.WithHTTPClient.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays the request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		body = b
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	recorded := newRequest(req, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for n, i := range r.cassette.Interactions {
+		if r.used[n] || !r.matcher(i.Request, recorded) {
+			continue
+		}
+
+		r.used[n] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w in cassette '%s': %s %s query=%q body=%q",
+		ErrNoInteraction, r.path, recorded.Method, recorded.Path, recorded.Query, recorded.Body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	i := Interaction{
+		Request: newRequest(req, body),
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, i)
+
+	// The whole cassette is written every time, account UUIDs found in a later response must be scrubbed
+	// from the earlier interactions too.
+	if err := r.scrubbed().save(r.path); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// scrubbed returns the cassette of the recorded interactions, scrubbed.
+func (r *Recorder) scrubbed() *Cassette {
+	interactions := make([]Interaction, len(r.interactions))
+
+	for n, i := range r.interactions {
+		interactions[n] = cloneInteraction(i)
+		scrubHeaders(&interactions[n])
+	}
+
+	scrubAccounts(interactions)
+
+	for n := range interactions {
+		for _, scrub := range r.scrubbers {
+			scrub(&interactions[n])
+		}
+	}
+
+	return &Cassette{Interactions: interactions}
+}
+
+// Unused returns the interactions of the cassette that were not replayed, so tests can check every
+// recorded request was made. It returns nil in record mode.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cassette == nil {
+		return nil
+	}
+
+	var unused []Interaction
+
+	for n, i := range r.cassette.Interactions {
+		if !r.used[n] {
+			unused = append(unused, i)
+		}
+	}
+
+	return unused
+}
@@ -0,0 +1,262 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package recorder_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/recorder"
+)
+
+const accountID = "8bfc20d7-f7c6-4422-bf07-8243ca4169fe"
+
+// newCoinbase starts a server answering the account endpoints with a fixed account and other requests with a
+// created order, it counts the requests it receives.
+func newCoinbase(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+
+	var requests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/api/v3/brokerage/accounts":
+			io.WriteString(w, `{"accounts":[{"uuid":"`+accountID+`","name":"BTC Wallet","currency":"BTC"}],"has_next":false}`)
+		case strings.HasPrefix(r.URL.Path, "/api/v3/brokerage/accounts/"):
+			io.WriteString(w, `{"account":{"uuid":"`+strings.TrimPrefix(r.URL.Path, "/api/v3/brokerage/accounts/")+`","name":"BTC Wallet","currency":"BTC"}}`)
+		default:
+			io.WriteString(w, `{"success":true,"order_id":"1"}`)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func newRecorder(t *testing.T, path string, mode recorder.Mode, opts ...recorder.Option) *recorder.Recorder {
+	t.Helper()
+
+	rec, err := recorder.New(path, mode, opts...)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	return rec
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv, requests := newCoinbase(t)
+	path := filepath.Join(t.TempDir(), "cassettes", "accounts.json")
+
+	rec := newRecorder(t, path, recorder.ModeAuto)
+	if rec.Mode() != recorder.ModeRecord {
+		t.Fatalf("mode is %s without a cassette, want %s", rec.Mode(), recorder.ModeRecord)
+	}
+
+	client := coinbase.NewWithLegacy("key", "secret", coinbase.WithBaseURL(srv.URL), coinbase.WithHTTPClient(rec.Client()))
+
+	accounts, err := client.Accounts.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to list accounts: %v", err)
+	}
+
+	account, err := client.Accounts.Get(context.Background(), accountID)
+	if err != nil {
+		t.Fatalf("failed to get account: %v", err)
+	}
+
+	// Replays happen without the server, with other credentials.
+	srv.Close()
+
+	rec = newRecorder(t, path, recorder.ModeAuto)
+	if rec.Mode() != recorder.ModeReplay {
+		t.Fatalf("mode is %s with a cassette, want %s", rec.Mode(), recorder.ModeReplay)
+	}
+
+	client = coinbase.NewWithLegacy("other", "secret", coinbase.WithBaseURL(srv.URL), coinbase.WithHTTPClient(rec.Client()))
+
+	if len(rec.Unused()) != 2 {
+		t.Fatalf("%d unused interactions before replaying, want 2", len(rec.Unused()))
+	}
+
+	replayed, err := client.Accounts.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to replay accounts: %v", err)
+	}
+
+	// The account UUID was scrubbed, the request for it is replayed with the placeholder.
+	placeholder := replayed.Accounts[0].ID.String()
+	if placeholder == accountID {
+		t.Fatal("account UUID not scrubbed")
+	}
+
+	replayedAccount, err := client.Accounts.Get(context.Background(), placeholder)
+	if err != nil {
+		t.Fatalf("failed to replay account: %v", err)
+	}
+
+	if replayedAccount.ID.String() != placeholder || *replayedAccount.Name != *account.Name || len(replayed.Accounts) != len(accounts.Accounts) {
+		t.Fatalf("replayed %+v and %+v, recorded %+v and %+v", replayed, replayedAccount, accounts, account)
+	}
+
+	if len(rec.Unused()) != 0 {
+		t.Fatalf("%d unused interactions after replaying, want 0", len(rec.Unused()))
+	}
+
+	// Every interaction is replayed once.
+	_, err = client.Accounts.List(context.Background(), nil)
+	if !errors.Is(err, recorder.ErrNoInteraction) {
+		t.Fatalf("error is %v, want %v", err, recorder.ErrNoInteraction)
+	}
+
+	if *requests != 2 {
+		t.Fatalf("server received %d requests, want 2", *requests)
+	}
+}
+
+func TestRecordScrubs(t *testing.T) {
+	srv, _ := newCoinbase(t)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+
+	rec := newRecorder(t, path, recorder.ModeRecord, recorder.WithScrubber(func(i *recorder.Interaction) {
+		i.Response.Body = strings.ReplaceAll(i.Response.Body, "BTC Wallet", "Wallet")
+	}))
+
+	client := coinbase.NewWithLegacy("my-key", "my-secret", coinbase.WithBaseURL(srv.URL), coinbase.WithHTTPClient(rec.Client()))
+
+	_, err := client.Accounts.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to list accounts: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+
+	cassette := string(b)
+
+	for _, secret := range []string{"my-key", "CB-ACCESS-SIGN", "Cb-Access-Sign", "Cb-Access-Key", accountID, "BTC Wallet"} {
+		if strings.Contains(cassette, secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, cassette)
+		}
+	}
+
+	// The timestamp is kept, it is not a credential.
+	if !strings.Contains(cassette, "Cb-Access-Timestamp") {
+		t.Errorf("cassette lost the timestamp header:\n%s", cassette)
+	}
+}
+
+func TestReplayMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded string // Request made while recording, a query string or a JSON body.
+		replayed string
+		opts     []recorder.Option
+		match    bool
+	}{
+		{name: "same query", recorded: "?a=1&b=2", replayed: "?a=1&b=2", match: true},
+		{name: "query order", recorded: "?a=1&b=2&b=3", replayed: "?b=3&a=1&b=2", match: true},
+		{name: "other query", recorded: "?a=1", replayed: "?a=2"},
+		{name: "same body", recorded: `{"a":1,"b":"x"}`, replayed: `{"a":1,"b":"x"}`, match: true},
+		{name: "body field order and whitespace", recorded: `{"a":1,"b":"x"}`, replayed: "{ \"b\": \"x\",\n \"a\": 1 }", match: true},
+		{name: "other body", recorded: `{"a":1}`, replayed: `{"a":2}`},
+		{name: "numbers kept exact", recorded: `{"a":0.10000000000000001}`, replayed: `{"a":0.1}`},
+		{name: "generated client order id", recorded: `{"client_order_id":"1","product_id":"BTC-USD"}`, replayed: `{"client_order_id":"2","product_id":"BTC-USD"}`},
+		{
+			name:     "ignored client order id",
+			recorded: `{"client_order_id":"1","product_id":"BTC-USD"}`,
+			replayed: `{"product_id":"BTC-USD","client_order_id":"2"}`,
+			opts:     []recorder.Option{recorder.WithMatcher(recorder.IgnoreBodyFields("client_order_id"))},
+			match:    true,
+		},
+		{
+			name:     "ignored nested field",
+			recorded: `{"orders":[{"client_order_id":"1","size":"1"}]}`,
+			replayed: `{"orders":[{"client_order_id":"2","size":"1"}]}`,
+			opts:     []recorder.Option{recorder.WithMatcher(recorder.IgnoreBodyFields("client_order_id"))},
+			match:    true,
+		},
+		{
+			name:     "other fields still compared",
+			recorded: `{"client_order_id":"1","product_id":"BTC-USD"}`,
+			replayed: `{"client_order_id":"2","product_id":"ETH-USD"}`,
+			opts:     []recorder.Option{recorder.WithMatcher(recorder.IgnoreBodyFields("client_order_id"))},
+		},
+		{
+			name:     "custom matcher",
+			recorded: `{"a":1}`,
+			replayed: `{"a":2}`,
+			opts: []recorder.Option{recorder.WithMatcher(func(recorded recorder.Request, req recorder.Request) bool {
+				return recorded.Method == req.Method && recorded.Path == req.Path
+			})},
+			match: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newCoinbase(t)
+			path := filepath.Join(t.TempDir(), "cassette.json")
+
+			send := func(rec *recorder.Recorder, request string) error {
+				method, url, body := http.MethodGet, srv.URL+"/api/v3/brokerage/orders"+request, ""
+				if strings.HasPrefix(request, "{") {
+					method, url, body = http.MethodPost, srv.URL+"/api/v3/brokerage/orders", request
+				}
+
+				req, err := http.NewRequest(method, url, strings.NewReader(body))
+				if err != nil {
+					return err
+				}
+
+				resp, err := rec.Client().Do(req)
+				if err != nil {
+					return err
+				}
+
+				return resp.Body.Close()
+			}
+
+			if err := send(newRecorder(t, path, recorder.ModeRecord, tt.opts...), tt.recorded); err != nil {
+				t.Fatalf("failed to record: %v", err)
+			}
+
+			err := send(newRecorder(t, path, recorder.ModeReplay, tt.opts...), tt.replayed)
+
+			if tt.match && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !tt.match && !errors.Is(err, recorder.ErrNoInteraction) {
+				t.Fatalf("error is %v, want %v", err, recorder.ErrNoInteraction)
+			}
+		})
+	}
+}
+
+func TestReplayWithoutCassette(t *testing.T) {
+	_, err := recorder.New(filepath.Join(t.TempDir(), "missing.json"), recorder.ModeReplay)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package recorder
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// scrubbedHeaders are the request headers carrying credentials, they are never written to a cassette.
+var scrubbedHeaders = []string{"Authorization", "CB-ACCESS-KEY", "CB-ACCESS-SIGN"}
+
+// scrubNamespace is the namespace of the UUIDs replacing account UUIDs.
+var scrubNamespace = uuid.MustParse("9f4c3b1e-7a47-4c55-9a1d-2f4e8f3c6b10")
+
+// Scrubber modifies an interaction before it is written to the cassette, e.g. to remove personal data.
+type Scrubber func(*Interaction)
+
+// scrubHeaders removes the credentials from the request headers.
+func scrubHeaders(i *Interaction) {
+	for _, name := range scrubbedHeaders {
+		i.Request.Header.Del(name)
+	}
+}
+
+// scrubAccounts replaces every account UUID found in the responses with a placeholder wherever it
+// appears in the interactions. Placeholders are derived from the UUID they replace, so a UUID is replaced
+// by the same placeholder in every interaction and every recording.
+func scrubAccounts(interactions []Interaction) {
+	ids := make(map[string]bool)
+
+	for _, i := range interactions {
+		var v any
+
+		if err := json.Unmarshal([]byte(i.Response.Body), &v); err == nil {
+			collectAccountIDs(v, false, ids)
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	pairs := make([]string, 0, 2*len(ids))
+	for id := range ids {
+		pairs = append(pairs, id, uuid.NewSHA1(scrubNamespace, []byte(id)).String())
+	}
+
+	r := strings.NewReplacer(pairs...)
+
+	for n := range interactions {
+		i := &interactions[n]
+
+		i.Request.Path = r.Replace(i.Request.Path)
+		i.Request.Query = r.Replace(i.Request.Query)
+		i.Request.Body = r.Replace(i.Request.Body)
+		i.Response.Body = r.Replace(i.Response.Body)
+	}
+}
+
+// collectAccountIDs collects the UUIDs of accounts: the uuid of the objects of an accounts list or in an
+// account field, and the values of account_uuid and account_id fields.
+func collectAccountIDs(v any, account bool, ids map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch {
+			case key == "uuid" && account, key == "account_uuid", key == "account_id":
+				if s, ok := value.(string); ok && isUUID(s) {
+					ids[s] = true
+				}
+			case key == "accounts" || key == "account":
+				collectAccountIDs(value, true, ids)
+			default:
+				collectAccountIDs(value, false, ids)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			collectAccountIDs(value, account, ids)
+		}
+	}
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+
+	return err == nil && len(s) == 36
+}
+
+// cloneInteraction returns a deep copy of the interaction, so scrubbing leaves the original untouched.
+func cloneInteraction(i Interaction) Interaction {
+	i.Request.Header = i.Request.Header.Clone()
+	i.Response.Header = i.Response.Header.Clone()
+
+	if i.Request.Header == nil {
+		i.Request.Header = http.Header{}
+	}
+
+	return i
+}
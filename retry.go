@@ -0,0 +1,155 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 4
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+	defaultRetryMaxRetryAfter  = time.Minute
+)
+
+// RetryPolicy configures how the client retries requests that failed with a transient error: a network
+// error, 429 Too Many Requests or a 5xx response.
+//
+// Only requests that are safe to repeat are retried. That is every GET request and the few POST requests
+// Coinbase deduplicates, such as OrdersService.Create when a client order ID is set. Every attempt is
+// authenticated again, so JWTs and legacy signatures are always fresh.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total number of attempts including the first, defaults to 4.
+	InitialBackoff time.Duration // Wait before the first retry, doubled for every retry after that. Defaults to 500ms.
+	MaxBackoff     time.Duration // Upper bound of the wait between attempts, defaults to 30s.
+	MaxRetryAfter  time.Duration // Longest Retry-After or RetryInfo delay Coinbase may ask for, the request fails instead of waiting any longer. Defaults to 1m.
+}
+
+// DefaultRetryPolicy returns the policy used when WithRetryPolicy is given a zero RetryPolicy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		MaxRetryAfter:  defaultRetryMaxRetryAfter,
+	}
+}
+
+// WithRetryPolicy enables retries of requests that failed with a transient error.
+// Zero fields of the policy are set to their defaults. By default requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) func(*Client) {
+	return func(c *Client) {
+		defaults := DefaultRetryPolicy()
+
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaults.MaxAttempts
+		}
+
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = defaults.InitialBackoff
+		}
+
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = defaults.MaxBackoff
+		}
+
+		if policy.MaxRetryAfter <= 0 {
+			policy.MaxRetryAfter = defaults.MaxRetryAfter
+		}
+
+		c.retryPolicy = &policy
+	}
+}
+
+type idempotentKey struct{}
+
+// withIdempotent marks requests made with the context as safe to retry, even if the method is not.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent reports whether sending the request more than once has the same effect as sending it once.
+func isIdempotent(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+
+	marked, _ := r.Context().Value(idempotentKey{}).(bool)
+
+	return marked
+}
+
+// isRetryableStatus reports whether the status code indicates a transient failure.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the wait before the given retry, starting at 1. Half the wait is random so clients
+// that failed together do not retry together.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+
+		return d, true
+	}
+
+	return 0, false
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
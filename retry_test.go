@@ -0,0 +1,236 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinsimmons/go-coinbase"
+)
+
+// scriptedResponse is one response of a scriptedServer.
+type scriptedResponse struct {
+	status     int
+	retryAfter string // Retry-After header, if any.
+	hangUp     bool   // Close the connection without answering.
+}
+
+// scriptedServer answers requests with the responses in order, repeating the last one.
+type scriptedServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses []scriptedResponse
+	requests  int
+}
+
+func newScriptedServer(t *testing.T, responses ...scriptedResponse) *scriptedServer {
+	t.Helper()
+
+	s := &scriptedServer{responses: responses}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		resp := s.responses[min(s.requests, len(s.responses)-1)]
+		s.requests++
+		s.mu.Unlock()
+
+		if resp.hangUp {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+
+			return
+		}
+
+		if resp.retryAfter != "" {
+			w.Header().Set("Retry-After", resp.retryAfter)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+
+		if resp.status == http.StatusOK {
+			w.Write([]byte(`{"product_id":"BTC-USD"}`))
+		} else {
+			w.Write([]byte(`{"error":"ERROR","message":"failed"}`))
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *scriptedServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// fastRetries retries without waiting noticeably.
+var fastRetries = coinbase.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, MaxRetryAfter: 2 * time.Second}
+
+func TestRetryPolicy(t *testing.T) {
+	ok := scriptedResponse{status: http.StatusOK}
+
+	tests := []struct {
+		name      string
+		policy    *coinbase.RetryPolicy
+		responses []scriptedResponse
+		post      bool // Send a POST that is not safe to repeat instead of a GET.
+		requests  int  // Expected number of requests received.
+		status    int  // Expected status of the error, 0 if the call succeeds.
+		minWait   time.Duration
+	}{
+		{
+			name:      "disabled by default",
+			responses: []scriptedResponse{{status: http.StatusServiceUnavailable}, ok},
+			requests:  1,
+			status:    http.StatusServiceUnavailable,
+		},
+		{
+			name:      "5xx is retried",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{status: http.StatusInternalServerError}, {status: http.StatusBadGateway}, ok},
+			requests:  3,
+		},
+		{
+			name:      "gives up after the last attempt",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{status: http.StatusServiceUnavailable}},
+			requests:  3,
+			status:    http.StatusServiceUnavailable,
+		},
+		{
+			name:      "network errors are retried",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{hangUp: true}, ok},
+			requests:  2,
+		},
+		{
+			name:      "4xx is not retried",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{status: http.StatusBadRequest}, ok},
+			requests:  1,
+			status:    http.StatusBadRequest,
+		},
+		{
+			name:      "unsafe requests are not retried",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{status: http.StatusServiceUnavailable}, ok},
+			post:      true,
+			requests:  1,
+			status:    http.StatusServiceUnavailable,
+		},
+		{
+			name:      "retry after is honoured",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{status: http.StatusTooManyRequests, retryAfter: "1"}, ok},
+			requests:  2,
+			minWait:   time.Second,
+		},
+		{
+			name:      "retry after beyond the maximum fails",
+			policy:    &fastRetries,
+			responses: []scriptedResponse{{status: http.StatusTooManyRequests, retryAfter: "120"}, ok},
+			requests:  1,
+			status:    http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newScriptedServer(t, tt.responses...)
+
+			client := coinbase.NewClient(coinbase.WithBaseURL(srv.URL))
+			if tt.policy != nil {
+				coinbase.WithRetryPolicy(*tt.policy)(client)
+			}
+
+			start := time.Now()
+
+			var err error
+			if tt.post {
+				_, err = client.Orders.Cancel(context.Background(), "1")
+			} else {
+				_, err = client.Products.Get(context.Background(), "BTC-USD")
+			}
+
+			if srv.count() != tt.requests {
+				t.Fatalf("%d requests, want %d", srv.count(), tt.requests)
+			}
+
+			var cbError *coinbase.CoinbaseError
+
+			switch {
+			case tt.status == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.status != 0 && (!errors.As(err, &cbError) || cbError.StatusCode != tt.status):
+				t.Fatalf("error %v, want status %d", err, tt.status)
+			}
+
+			if elapsed := time.Since(start); elapsed < tt.minWait {
+				t.Fatalf("retried after %s, want at least %s", elapsed, tt.minWait)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyStopsWhenContextIsDone(t *testing.T) {
+	srv := newScriptedServer(t, scriptedResponse{status: http.StatusServiceUnavailable})
+
+	client := coinbase.NewClient(coinbase.WithBaseURL(srv.URL))
+	coinbase.WithRetryPolicy(coinbase.RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour, MaxBackoff: time.Hour})(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err := client.Products.Get(ctx, "BTC-USD")
+	if err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("error %v, want the last failure", err)
+	}
+
+	if srv.count() != 1 || time.Since(start) > 5*time.Second {
+		t.Fatalf("%d requests in %s, want the backoff to be interrupted", srv.count(), time.Since(start))
+	}
+}
+
+func TestCreateOrderIsRetriedWithClientOrderID(t *testing.T) {
+	srv := newScriptedServer(t, scriptedResponse{status: http.StatusBadGateway}, scriptedResponse{status: http.StatusOK})
+
+	client := coinbase.NewClient(coinbase.WithBaseURL(srv.URL))
+	coinbase.WithRetryPolicy(fastRetries)(client)
+
+	side := coinbase.SideBuy
+
+	_, err := client.Orders.Create(context.Background(), coinbase.CreateOrderOptions{
+		ClientOrderID:      "abc",
+		ProductID:          "BTC-USD",
+		Side:               &side,
+		OrderConfiguration: coinbase.NewMarketIOC(coinbase.QuoteSize("10")),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if srv.count() != 2 {
+		t.Fatalf("%d requests, want the order to be sent again", srv.count())
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	productionWebSocketURI     = "wss://advanced-trade-ws.coinbase.com"
+	productionUserWebSocketURI = "wss://advanced-trade-ws-user.coinbase.com"
+
+	defaultStreamBufferSize  = 1024             // Number of messages buffered before the stream applies back pressure.
+	defaultMaxReconnectDelay = time.Second * 30 // Upper bound on the delay between reconnection attempts.
+	minReconnectDelay        = time.Second      // Delay before the first reconnection attempt.
+	streamReadLimit          = 1 << 24          // Level2 snapshots can be several megabytes for busy products.
+)
+
+var (
+	// ErrStreamClosed - the stream has been closed and can no longer be used.
+	ErrStreamClosed = errors.New("stream has been closed")
+	// ErrStreamAuthenticationRequired - the channel requires a client authenticated with Cloud API Trading Keys.
+	ErrStreamAuthenticationRequired = errors.New("websocket channel requires a client authenticated with cloud API trading keys")
+)
+
+// webSocketAuthenticator is implemented by authenticators able to sign subscriptions to the WebSocket feed.
+type webSocketAuthenticator interface {
+	webSocketJWT() (string, error)
+}
+
+// Channel of the Advanced Trade WebSocket feed.
+type Channel string
+
+const (
+	ChannelHeartbeats    Channel = "heartbeats"    // Real-time server pings to keep all connections open.
+	ChannelCandles       Channel = "candles"       // Real-time updates on product candles.
+	ChannelStatus        Channel = "status"        // Sends all products and currencies on a preset interval.
+	ChannelTicker        Channel = "ticker"        // Real-time price updates every time a match happens.
+	ChannelTickerBatch   Channel = "ticker_batch"  // Real-time price updates every 5000 milli-seconds.
+	ChannelLevel2        Channel = "level2"        // All updates and easiest way to keep order book snapshot.
+	ChannelMarketTrades  Channel = "market_trades" // Real-time updates every time a market trade happens.
+	ChannelUser          Channel = "user"          // Only sends messages that are specific to the user of the authenticated client.
+	ChannelSubscriptions Channel = "subscriptions" // Confirmation of the channels the connection is subscribed to.
+
+	// Coinbase publishes level2 updates on a different channel name than the one subscribed to.
+	channelLevel2Data Channel = "l2_data"
+)
+
+// Subscription to a channel of the Advanced Trade WebSocket feed for a set of products.
+type Subscription struct {
+	Channel    Channel  // Channel to subscribe to.
+	ProductIDs []string // Products to receive updates for, i.e. 'BTC-USD'. Not required for the heartbeats channel.
+}
+
+type subscribeMessage struct {
+	Type       string   `json:"type"` // Possible values: [subscribe, unsubscribe].
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Channel    Channel  `json:"channel"`
+	JWT        string   `json:"jwt,omitempty"` // Required for the user channel, recommended for all others.
+}
+
+// Interface for interacting with the Advanced Trade WebSocket feed.
+// https://docs.cdp.coinbase.com/advanced-trade/docs/ws-overview
+type StreamService service
+
+type StreamOptions struct {
+	Subscriptions     []Subscription // Channels to subscribe to once connected. Subscriptions are signed if the client uses Cloud API Trading Keys.
+	BufferSize        int            // Number of messages buffered before the stream applies back pressure, defaults to 1024.
+	MaxReconnectDelay time.Duration  // Upper bound on the delay between reconnection attempts, defaults to 30 seconds.
+	DisableReconnect  bool           // Close the stream instead of reconnecting when the connection is lost.
+}
+
+// Stream is a connection to the Advanced Trade WebSocket feed.
+//
+// If the connection is lost it is automatically re-established and all subscriptions are
+// replayed, Coinbase will send a fresh snapshot for every channel that supports them.
+// Messages are delivered in the order they were received on Messages, transport errors and
+// sequence gaps are reported on Errors. Gaps and reconnects are also flagged on the messages
+// themselves, so state built from the feed does not depend on reading Errors.
+type Stream struct {
+	client  *Client
+	url     string
+	options StreamOptions
+
+	messages chan *StreamMessage
+	errs     chan error
+	dropped  atomic.Int64 // Errors dropped because the error channel was full.
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	subscriptions map[Channel]map[string]struct{}
+	closed        bool
+
+	lastSequence int64       // Only accessed by the read loop.
+	reconnected  bool        // Only accessed by the read loop.
+	fills        fillTracker // Only accessed by the read loop.
+	reconciling  sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Connect opens a connection to the Advanced Trade WebSocket feed and subscribes to the
+// requested channels. The stream is closed when the context is cancelled or Close is called.
+func (s *StreamService) Connect(ctx context.Context, options StreamOptions) (*Stream, error) {
+	return s.client.connectStream(ctx, s.client.webSocketURL, options)
+}
+
+// ConnectUser opens a connection to the user order data endpoint of the WebSocket feed and subscribes to
+// the user channel, in addition to any other requested channels. The feed sends a snapshot of the user's
+// open orders followed by an update every time one of their orders changes.
+//
+// The user channel requires a client authenticated with Cloud API Trading Keys.
+func (s *StreamService) ConnectUser(ctx context.Context, options StreamOptions) (*Stream, error) {
+	if _, ok := s.client.authenticator.(webSocketAuthenticator); !ok {
+		return nil, ErrStreamAuthenticationRequired
+	}
+
+	subscriptions := make([]Subscription, 0, len(options.Subscriptions)+1)
+	subscriptions = append(subscriptions, options.Subscriptions...)
+
+	if !hasChannel(subscriptions, ChannelUser) {
+		subscriptions = append(subscriptions, Subscription{Channel: ChannelUser})
+	}
+
+	options.Subscriptions = subscriptions
+
+	return s.client.connectStream(ctx, s.client.userWebSocketURL, options)
+}
+
+func hasChannel(subscriptions []Subscription, channel Channel) bool {
+	for _, sub := range subscriptions {
+		if sub.Channel == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Client) connectStream(ctx context.Context, url string, options StreamOptions) (*Stream, error) {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultStreamBufferSize
+	}
+
+	if options.MaxReconnectDelay <= 0 {
+		options.MaxReconnectDelay = defaultMaxReconnectDelay
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	st := &Stream{
+		client:        c,
+		url:           url,
+		options:       options,
+		messages:      make(chan *StreamMessage, options.BufferSize),
+		errs:          make(chan error, options.BufferSize),
+		subscriptions: map[Channel]map[string]struct{}{},
+		fills:         newFillTracker(),
+		ctx:           streamCtx,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+
+	st.addSubscriptions(options.Subscriptions)
+
+	conn, err := st.dial()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go st.run(conn)
+
+	return st, nil
+}
+
+// Messages returns the channel messages from the feed are delivered on.
+// The channel is closed once the stream has been closed.
+func (st *Stream) Messages() <-chan *StreamMessage {
+	return st.messages
+}
+
+// Errors returns the channel errors encountered while reading the feed are delivered on. The stream
+// never waits for the channel to be drained, errors are dropped if it is full and counted by
+// DroppedErrors. The channel is closed once the stream has been closed.
+func (st *Stream) Errors() <-chan error {
+	return st.errs
+}
+
+// DroppedErrors returns the number of errors dropped because the error channel was full.
+func (st *Stream) DroppedErrors() int64 {
+	return st.dropped.Load()
+}
+
+// Connected reports whether the stream is currently connected to the feed.
+func (st *Stream) Connected() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return st.conn != nil
+}
+
+// Subscribe adds subscriptions to the stream. Subscriptions are kept across reconnects.
+func (st *Stream) Subscribe(ctx context.Context, subscriptions ...Subscription) error {
+	st.mu.Lock()
+
+	if st.closed || st.ctx.Err() != nil {
+		st.mu.Unlock()
+		return ErrStreamClosed
+	}
+
+	st.addSubscriptions(subscriptions)
+	conn := st.conn
+
+	st.mu.Unlock()
+
+	return st.sendAll(ctx, conn, "subscribe", subscriptions)
+}
+
+// Unsubscribe removes subscriptions from the stream.
+func (st *Stream) Unsubscribe(ctx context.Context, subscriptions ...Subscription) error {
+	st.mu.Lock()
+
+	if st.closed || st.ctx.Err() != nil {
+		st.mu.Unlock()
+		return ErrStreamClosed
+	}
+
+	for _, sub := range subscriptions {
+		products := st.subscriptions[sub.Channel]
+
+		for _, id := range sub.ProductIDs {
+			delete(products, id)
+		}
+
+		if len(sub.ProductIDs) == 0 || len(products) == 0 {
+			delete(st.subscriptions, sub.Channel)
+		}
+	}
+
+	conn := st.conn
+
+	st.mu.Unlock()
+
+	return st.sendAll(ctx, conn, "unsubscribe", subscriptions)
+}
+
+// Resubscribe unsubscribes and subscribes to the channel again, this causes Coinbase to send
+// a fresh snapshot for channels that support them.
+func (st *Stream) Resubscribe(ctx context.Context, sub Subscription) error {
+	err := st.Unsubscribe(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	return st.Subscribe(ctx, sub)
+}
+
+// Close closes the connection to the feed and waits for the stream to shut down.
+func (st *Stream) Close() error {
+	st.mu.Lock()
+	conn := st.conn
+	st.conn = nil
+	st.closed = true
+	st.mu.Unlock()
+
+	// Close the connection before cancelling the context so the close handshake can complete.
+	if conn != nil {
+		conn.Close(websocket.StatusNormalClosure, "")
+	}
+
+	st.cancel()
+	<-st.done
+
+	return nil
+}
+
+// Must be called with the lock held.
+func (st *Stream) addSubscriptions(subscriptions []Subscription) {
+	for _, sub := range subscriptions {
+		products, ok := st.subscriptions[sub.Channel]
+		if !ok {
+			products = map[string]struct{}{}
+			st.subscriptions[sub.Channel] = products
+		}
+
+		for _, id := range sub.ProductIDs {
+			products[id] = struct{}{}
+		}
+	}
+}
+
+// sendAll writes the messages to the connection. The lock must not be held, so a slow connection
+// never blocks the read loop or other callers.
+func (st *Stream) sendAll(ctx context.Context, conn *websocket.Conn, typ string, subscriptions []Subscription) error {
+	if conn == nil {
+		// Not connected, subscriptions will be sent once the connection is re-established.
+		return nil
+	}
+
+	for _, sub := range subscriptions {
+		err := st.send(ctx, conn, typ, sub)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (st *Stream) send(ctx context.Context, conn *websocket.Conn, typ string, sub Subscription) error {
+	msg := subscribeMessage{
+		Type:       typ,
+		ProductIDs: sub.ProductIDs,
+		Channel:    sub.Channel,
+	}
+
+	// Tokens are only valid for two minutes, sign every message so resubscribing after a reconnect
+	// never uses an expired token.
+	if a, ok := st.client.authenticator.(webSocketAuthenticator); ok {
+		jwt, err := a.webSocketJWT()
+		if err != nil {
+			return fmt.Errorf("failed to sign %s message: %w", typ, err)
+		}
+
+		msg.JWT = jwt
+	} else if sub.Channel == ChannelUser {
+		return ErrStreamAuthenticationRequired
+	}
+
+	b, err := json.Marshal(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s message to JSON: %w", typ, err)
+	}
+
+	err = conn.Write(ctx, websocket.MessageText, b)
+	if err != nil {
+		return fmt.Errorf("failed to %s to channel '%s': %w", typ, sub.Channel, err)
+	}
+
+	return nil
+}
+
+// dial opens a new connection to the feed and replays all of the subscriptions.
+func (st *Stream) dial() (*websocket.Conn, error) {
+	conn, _, err := websocket.Dial(st.ctx, st.url, &websocket.DialOptions{HTTPClient: st.client.httpClient})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket feed '%s': %w", st.url, err)
+	}
+
+	conn.SetReadLimit(streamReadLimit)
+
+	st.mu.Lock()
+
+	if st.closed {
+		st.mu.Unlock()
+		conn.Close(websocket.StatusNormalClosure, "")
+
+		return nil, ErrStreamClosed
+	}
+
+	// Subscriptions added from here on are sent by Subscribe itself, so none are missed or sent twice.
+	st.conn = conn
+	st.lastSequence = -1
+	subscriptions := st.subscriptionList()
+
+	st.mu.Unlock()
+
+	err = st.sendAll(st.ctx, conn, "subscribe", subscriptions)
+	if err != nil {
+		st.mu.Lock()
+		if st.conn == conn {
+			st.conn = nil
+		}
+		st.mu.Unlock()
+
+		conn.Close(websocket.StatusInternalError, "")
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// subscriptionList returns a subscription per channel. Must be called with the lock held.
+func (st *Stream) subscriptionList() []Subscription {
+	channels := make([]Channel, 0, len(st.subscriptions))
+	for channel := range st.subscriptions {
+		channels = append(channels, channel)
+	}
+
+	// Keep the replay order stable, makes the feed easier to reason about when debugging.
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+
+	subscriptions := make([]Subscription, 0, len(channels))
+
+	for _, channel := range channels {
+		sub := Subscription{Channel: channel}
+
+		for id := range st.subscriptions[channel] {
+			sub.ProductIDs = append(sub.ProductIDs, id)
+		}
+
+		sort.Strings(sub.ProductIDs)
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	return subscriptions
+}
+
+func (st *Stream) run(conn *websocket.Conn) {
+	defer close(st.done)
+	defer close(st.messages)
+	defer close(st.errs)
+	defer st.reconciling.Wait()
+	defer st.cancel()
+
+	for {
+		err := st.read(conn)
+
+		st.mu.Lock()
+		closed := st.closed
+		st.conn = nil
+		st.mu.Unlock()
+
+		if closed || st.ctx.Err() != nil {
+			return
+		}
+
+		st.reportError(&StreamDisconnectedError{Err: err})
+
+		if st.options.DisableReconnect {
+			return
+		}
+
+		conn = st.reconnect()
+		if conn == nil {
+			return
+		}
+
+		st.reconnected = true
+		st.fills.expectSnapshot()
+	}
+}
+
+// reconnect keeps dialing the feed with an exponential backoff until it succeeds or the stream is closed.
+func (st *Stream) reconnect() *websocket.Conn {
+	delay := minReconnectDelay
+
+	for {
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-st.ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		conn, err := st.dial()
+		if err == nil {
+			return conn
+		}
+
+		if st.ctx.Err() != nil {
+			return nil
+		}
+
+		st.reportError(err)
+
+		delay *= 2
+		if delay > st.options.MaxReconnectDelay {
+			delay = st.options.MaxReconnectDelay
+		}
+	}
+}
+
+func (st *Stream) read(conn *websocket.Conn) error {
+	for {
+		_, data, err := conn.Read(st.ctx)
+		if err != nil {
+			return err
+		}
+
+		msg, err := decodeStreamMessage(data)
+		if err != nil {
+			st.reportError(err)
+			continue
+		}
+
+		if st.lastSequence >= 0 && msg.SequenceNumber != st.lastSequence+1 {
+			msg.Gap = &SequenceGapError{Expected: st.lastSequence + 1, Received: msg.SequenceNumber}
+			st.reportError(msg.Gap)
+		}
+
+		st.lastSequence = msg.SequenceNumber
+
+		msg.Reconnected, st.reconnected = st.reconnected, false
+
+		// Totals are kept across reconnects, so the snapshot sent after resubscribing reports
+		// anything that was filled while the stream was disconnected. Orders missing from it
+		// completed in the meantime, and are fetched in the background to report their final fills
+		// on a message delivered after this one.
+		var missing []string
+
+		for _, event := range msg.User {
+			for _, order := range event.Orders {
+				fill, err := st.fills.track(order, event.Type == StreamEventTypeSnapshot, msg.Timestamp)
+				if err != nil {
+					st.reportError(err)
+				} else if fill != nil {
+					msg.Fills = append(msg.Fills, *fill)
+				}
+			}
+
+			missing = append(missing, st.fills.endOfSnapshot(event)...)
+		}
+
+		select {
+		case st.messages <- msg:
+		case <-st.ctx.Done():
+			return st.ctx.Err()
+		}
+
+		if len(missing) > 0 {
+			st.reconcileFills(missing, msg.Timestamp)
+		}
+	}
+}
+
+// reportError delivers the error without blocking, errors are dropped and counted if the channel is full.
+func (st *Stream) reportError(err error) {
+	select {
+	case st.errs <- err:
+	default:
+		st.dropped.Add(1)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Whether the event contains the full state or the changes since the last event.
+type StreamEventType string
+
+const (
+	StreamEventTypeSnapshot StreamEventType = "snapshot"
+	StreamEventTypeUpdate   StreamEventType = "update"
+)
+
+// Side of the order book a level2 update applies to.
+type Level2Side string
+
+const (
+	Level2SideBid   Level2Side = "bid"
+	Level2SideOffer Level2Side = "offer"
+)
+
+// SequenceGapError is reported when one or more messages from the feed were missed.
+// Any state built from the feed, such as an order book, should be rebuilt from a fresh snapshot.
+type SequenceGapError struct {
+	Expected int64 // Sequence number that should have been received.
+	Received int64 // Sequence number that was actually received.
+}
+
+func (e *SequenceGapError) Error() string {
+	return fmt.Sprintf("websocket feed sequence gap: expected sequence number %d but received %d", e.Expected, e.Received)
+}
+
+// StreamDisconnectedError is reported when the connection to the feed is lost. Messages published while
+// disconnected are never received, so any state built from the feed should be treated as stale until
+// a fresh snapshot arrives after reconnecting.
+type StreamDisconnectedError struct {
+	Err error
+}
+
+func (e *StreamDisconnectedError) Error() string {
+	return fmt.Sprintf("lost connection to websocket feed: %v", e.Err)
+}
+
+func (e *StreamDisconnectedError) Unwrap() error {
+	return e.Err
+}
+
+// StreamError is an error message sent by Coinbase over the feed, typically in response to an invalid subscription.
+type StreamError struct {
+	Message string
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("websocket feed returned an error: %s", e.Message)
+}
+
+// Real-time price update for a product.
+type Ticker struct {
+	Type                  *string `json:"type"`                   // Always 'ticker'.
+	ProductID             string  `json:"product_id"`             // The trading pair.
+	Price                 *string `json:"price"`                  // Price of the last trade, in quote currency.
+	Volume24Hours         *string `json:"volume_24_h"`            // Trading volume in the last 24 hours.
+	Low24Hours            *string `json:"low_24_h"`               // Lowest price in the last 24 hours.
+	High24Hours           *string `json:"high_24_h"`              // Highest price in the last 24 hours.
+	Low52Weeks            *string `json:"low_52_w"`               // Lowest price in the last 52 weeks.
+	High52Weeks           *string `json:"high_52_w"`              // Highest price in the last 52 weeks.
+	PricePercentChange24H *string `json:"price_percent_chg_24_h"` // Percent the price has changed in the last 24 hours.
+	BestBid               *string `json:"best_bid"`               // The best bid, in quote currency.
+	BestBidQuantity       *string `json:"best_bid_quantity"`      // Size available at the best bid.
+	BestAsk               *string `json:"best_ask"`               // The best ask, in quote currency.
+	BestAskQuantity       *string `json:"best_ask_quantity"`      // Size available at the best ask.
+}
+
+type TickerEvent struct {
+	Type    StreamEventType `json:"type"`
+	Tickers []Ticker        `json:"tickers"`
+}
+
+// Candle for a product, the channel sends 5 minute candles.
+type StreamCandle struct {
+	Candles
+	ProductID string `json:"product_id"` // The trading pair.
+}
+
+type CandlesEvent struct {
+	Type    StreamEventType `json:"type"`
+	Candles []StreamCandle  `json:"candles"`
+}
+
+type MarketTradesEvent struct {
+	Type   StreamEventType `json:"type"`
+	Trades []Trade         `json:"trades"`
+}
+
+// Product as reported by the status channel.
+type StatusProduct struct {
+	ProductType    *ProductType `json:"product_type"`
+	ID             string       `json:"id"`               // The trading pair.
+	BaseCurrency   string       `json:"base_currency"`    // Symbol of the base currency.
+	QuoteCurrency  string       `json:"quote_currency"`   // Symbol of the quote currency.
+	BaseIncrement  string       `json:"base_increment"`   // Minimum amount base value can be increased or decreased at once.
+	QuoteIncrement string       `json:"quote_increment"`  // Minimum amount quote value can be increased or decreased at once.
+	DisplayName    string       `json:"display_name"`     // Name of the product, i.e. 'BTC/USD'.
+	Status         string       `json:"status"`           // Status of the product, i.e. 'online'.
+	StatusMessage  string       `json:"status_message"`   // Message explaining the status of the product.
+	MinMarketFunds string       `json:"min_market_funds"` // Minimum amount of quote currency for a market order.
+}
+
+// Product converts the status update into the Product model used by the REST API.
+// Only the fields published on the status channel are populated.
+func (p StatusProduct) Product() Product {
+	return Product{
+		ID:               p.ID,
+		BaseIncrement:    p.BaseIncrement,
+		QuoteIncrement:   p.QuoteIncrement,
+		QuoteMinimumSize: p.MinMarketFunds,
+		Status:           p.Status,
+		Type:             p.ProductType,
+		BaseCurrencyID:   String(p.BaseCurrency),
+		QuoteCurrencyID:  String(p.QuoteCurrency),
+	}
+}
+
+type StatusEvent struct {
+	Type     StreamEventType `json:"type"`
+	Products []StatusProduct `json:"products"`
+}
+
+// A single price level change in the order book.
+type Level2Update struct {
+	Side        Level2Side `json:"side"`         // Side of the book the update applies to.
+	EventTime   *time.Time `json:"event_time"`   // Time of the update.
+	PriceLevel  string     `json:"price_level"`  // Price of the level.
+	NewQuantity string     `json:"new_quantity"` // Size now resting at the price level, zero if the level was removed.
+}
+
+type Level2Event struct {
+	Type      StreamEventType `json:"type"`
+	ProductID string          `json:"product_id"`
+	Updates   []Level2Update  `json:"updates"`
+}
+
+type HeartbeatEvent struct {
+	CurrentTime      string      `json:"current_time"`
+	HeartbeatCounter json.Number `json:"heartbeat_counter"` // Increments by one every heartbeat, used to verify no messages were missed.
+}
+
+type SubscriptionsEvent struct {
+	Subscriptions map[Channel][]string `json:"subscriptions"` // Products subscribed to, keyed by channel.
+}
+
+// StreamMessage is a single message received from the Advanced Trade WebSocket feed.
+// Only the events matching the channel of the message are populated.
+type StreamMessage struct {
+	Channel        Channel           // Channel the message was published on.
+	ClientID       string            // ID of the client the message was sent to.
+	Timestamp      time.Time         // Time the message was published.
+	SequenceNumber int64             // Increments by one for every message sent on the connection.
+	Gap            *SequenceGapError // Set if one or more messages were missed before this one.
+	Reconnected    bool              // Set on the first message received after the connection was re-established.
+	Reconciled     bool              // Set on messages that only carry the fills of orders completed while disconnected, they were not received on the feed.
+
+	Tickers       []TickerEvent        // Populated for the ticker and ticker_batch channels.
+	Candles       []CandlesEvent       // Populated for the candles channel.
+	MarketTrades  []MarketTradesEvent  // Populated for the market_trades channel.
+	Status        []StatusEvent        // Populated for the status channel.
+	Level2        []Level2Event        // Populated for the level2 channel.
+	Heartbeats    []HeartbeatEvent     // Populated for the heartbeats channel.
+	Subscriptions []SubscriptionsEvent // Populated when the set of subscriptions changes.
+	User          []UserEvent          // Populated for the user channel.
+
+	// Fills derived from the change in an order's cumulative totals since its previous update on the user channel.
+	// Trade and entry IDs are not published on the feed, use OrdersService.ListFills for the full record.
+	Fills []Fill
+}
+
+type rawStreamMessage struct {
+	Type           *string         `json:"type"`    // Only set on error messages.
+	Message        *string         `json:"message"` // Only set on error messages.
+	Channel        Channel         `json:"channel"`
+	ClientID       string          `json:"client_id"`
+	Timestamp      time.Time       `json:"timestamp"`
+	SequenceNumber int64           `json:"sequence_num"`
+	Events         json.RawMessage `json:"events"`
+}
+
+func decodeStreamMessage(data []byte) (*StreamMessage, error) {
+	var raw rawStreamMessage
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal websocket message '%s': %w", data, err)
+	}
+
+	if raw.Type != nil && *raw.Type == "error" {
+		var message string
+		if raw.Message != nil {
+			message = *raw.Message
+		}
+
+		return nil, &StreamError{Message: message}
+	}
+
+	msg := StreamMessage{
+		Channel:        raw.Channel,
+		ClientID:       raw.ClientID,
+		Timestamp:      raw.Timestamp,
+		SequenceNumber: raw.SequenceNumber,
+	}
+
+	var events any
+
+	switch raw.Channel {
+	case ChannelTicker, ChannelTickerBatch:
+		events = &msg.Tickers
+	case ChannelCandles:
+		events = &msg.Candles
+	case ChannelMarketTrades:
+		events = &msg.MarketTrades
+	case ChannelStatus:
+		events = &msg.Status
+	case ChannelLevel2, channelLevel2Data:
+		msg.Channel = ChannelLevel2
+		events = &msg.Level2
+	case ChannelHeartbeats:
+		events = &msg.Heartbeats
+	case ChannelSubscriptions:
+		events = &msg.Subscriptions
+	case ChannelUser:
+		events = &msg.User
+	default:
+		// Unknown channel, still deliver the envelope so the sequence number is accounted for.
+		return &msg, nil
+	}
+
+	if len(raw.Events) > 0 {
+		err = json.Unmarshal(raw.Events, events)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal '%s' events '%s': %w", raw.Channel, raw.Events, err)
+		}
+	}
+
+	return &msg, nil
+}
@@ -0,0 +1,469 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/justinsimmons/go-coinbase"
+	"github.com/justinsimmons/go-coinbase/coinbasetest"
+)
+
+// feed is a WebSocket server standing in for the Advanced Trade feed, handing each connection to the test.
+type feed struct {
+	url   string
+	conns chan *websocket.Conn
+}
+
+func newFeed(t *testing.T) *feed {
+	t.Helper()
+
+	f := &feed{conns: make(chan *websocket.Conn, 4)}
+	done := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		f.conns <- conn
+
+		<-done
+	}))
+
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+
+	f.url = "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	return f
+}
+
+// accept waits for the stream to connect.
+func (f *feed) accept(t *testing.T) *websocket.Conn {
+	t.Helper()
+
+	select {
+	case conn := <-f.conns:
+		return conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not connect")
+		return nil
+	}
+}
+
+// readSubscription reads the next subscribe or unsubscribe message sent by the stream.
+func readSubscription(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("failed to read subscription: %v", err)
+	}
+
+	var msg map[string]any
+
+	err = json.Unmarshal(data, &msg)
+	if err != nil {
+		t.Fatalf("failed to decode subscription: %v", err)
+	}
+
+	return msg
+}
+
+func writeHeartbeat(t *testing.T, conn *websocket.Conn, sequence int64) {
+	t.Helper()
+
+	msg := fmt.Sprintf(`{"channel":"heartbeats","client_id":"","timestamp":"2024-01-01T00:00:00Z","sequence_num":%d,"events":[{"current_time":"2024-01-01T00:00:00Z","heartbeat_counter":%d}]}`, sequence, sequence)
+
+	err := conn.Write(context.Background(), websocket.MessageText, []byte(msg))
+	if err != nil {
+		t.Fatalf("failed to write message: %v", err)
+	}
+}
+
+func nextMessage(t *testing.T, st *coinbase.Stream) *coinbase.StreamMessage {
+	t.Helper()
+
+	select {
+	case msg, ok := <-st.Messages():
+		if !ok {
+			t.Fatal("stream closed")
+		}
+
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func nextError(t *testing.T, st *coinbase.Stream) error {
+	t.Helper()
+
+	select {
+	case err := <-st.Errors():
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("no error received")
+		return nil
+	}
+}
+
+func TestStreamSequenceGaps(t *testing.T) {
+	tests := []struct {
+		name      string
+		sequences []int64
+		gaps      map[int]coinbase.SequenceGapError // Gap expected on the message at the index.
+	}{
+		{
+			name:      "contiguous",
+			sequences: []int64{0, 1, 2},
+		},
+		{
+			name:      "first message sets the sequence",
+			sequences: []int64{41, 42},
+		},
+		{
+			name:      "skipped message",
+			sequences: []int64{0, 2, 3},
+			gaps:      map[int]coinbase.SequenceGapError{1: {Expected: 1, Received: 2}},
+		},
+		{
+			name:      "sequence going backwards",
+			sequences: []int64{5, 4},
+			gaps:      map[int]coinbase.SequenceGapError{1: {Expected: 6, Received: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeed(t)
+			client := coinbase.NewClient(coinbase.WithWebSocketURL(f.url))
+
+			st, err := client.Stream.Connect(context.Background(), coinbase.StreamOptions{
+				Subscriptions:    []coinbase.Subscription{{Channel: coinbase.ChannelHeartbeats}},
+				DisableReconnect: true,
+			})
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer st.Close()
+
+			conn := f.accept(t)
+			readSubscription(t, conn)
+			conn.CloseRead(context.Background())
+
+			for _, seq := range tt.sequences {
+				writeHeartbeat(t, conn, seq)
+			}
+
+			for i, seq := range tt.sequences {
+				msg := nextMessage(t, st)
+
+				if msg.SequenceNumber != seq {
+					t.Fatalf("message %d: sequence %d, want %d", i, msg.SequenceNumber, seq)
+				}
+
+				want, ok := tt.gaps[i]
+				if !ok {
+					if msg.Gap != nil {
+						t.Fatalf("message %d: unexpected gap %v", i, msg.Gap)
+					}
+
+					continue
+				}
+
+				if msg.Gap == nil || *msg.Gap != want {
+					t.Fatalf("message %d: gap %v, want %v", i, msg.Gap, want)
+				}
+
+				var gap *coinbase.SequenceGapError
+				if err := nextError(t, st); !errors.As(err, &gap) || *gap != want {
+					t.Fatalf("message %d: error %v, want %v", i, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamReplaysSubscriptionsAfterReconnect(t *testing.T) {
+	f := newFeed(t)
+	client := coinbase.NewClient(coinbase.WithWebSocketURL(f.url))
+
+	st, err := client.Stream.Connect(context.Background(), coinbase.StreamOptions{
+		Subscriptions: []coinbase.Subscription{
+			{Channel: coinbase.ChannelTicker, ProductIDs: []string{"ETH-USD", "BTC-USD"}},
+			{Channel: coinbase.ChannelHeartbeats},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer st.Close()
+
+	conn := f.accept(t)
+
+	for i := 0; i < 2; i++ {
+		readSubscription(t, conn)
+	}
+
+	err = st.Subscribe(context.Background(), coinbase.Subscription{Channel: coinbase.ChannelLevel2, ProductIDs: []string{"BTC-USD"}})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	readSubscription(t, conn)
+
+	conn.CloseNow()
+
+	var disconnected *coinbase.StreamDisconnectedError
+	if err := nextError(t, st); !errors.As(err, &disconnected) {
+		t.Fatalf("error %v, want a StreamDisconnectedError", err)
+	}
+
+	conn = f.accept(t)
+
+	want := []string{
+		`heartbeats <nil>`,
+		`level2 [BTC-USD]`,
+		`ticker [BTC-USD ETH-USD]`,
+	}
+
+	for _, w := range want {
+		msg := readSubscription(t, conn)
+
+		if got := fmt.Sprintf("%v %v", msg["channel"], msg["product_ids"]); msg["type"] != "subscribe" || got != w {
+			t.Fatalf("replayed %v %s, want subscribe %s", msg["type"], got, w)
+		}
+	}
+
+	conn.CloseRead(context.Background())
+}
+
+func TestStreamDoesNotWaitForErrors(t *testing.T) {
+	f := newFeed(t)
+	client := coinbase.NewClient(coinbase.WithWebSocketURL(f.url))
+
+	st, err := client.Stream.Connect(context.Background(), coinbase.StreamOptions{
+		Subscriptions:    []coinbase.Subscription{{Channel: coinbase.ChannelHeartbeats}},
+		BufferSize:       1,
+		DisableReconnect: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer st.Close()
+
+	conn := f.accept(t)
+	readSubscription(t, conn)
+
+	// Every other sequence number is skipped, so each message after the first carries a gap.
+	const messages = 5
+
+	for i := 0; i < messages; i++ {
+		writeHeartbeat(t, conn, int64(i*2))
+	}
+
+	conn.CloseNow()
+
+	var received, gaps, reported int
+
+	// Errors are only read once every message was received, the stream must not wait for them.
+	for msg := range st.Messages() {
+		if msg.Gap != nil {
+			gaps++
+		}
+
+		received++
+	}
+
+	for range st.Errors() {
+		reported++
+	}
+
+	if received != messages || gaps != messages-1 {
+		t.Fatalf("received %d messages and %d gaps, want %d and %d", received, gaps, messages, messages-1)
+	}
+
+	// The gaps and the disconnect are either received or counted as dropped.
+	if total := int64(reported) + st.DroppedErrors(); total != messages {
+		t.Fatalf("received %d errors and dropped %d, want %d in total", reported, st.DroppedErrors(), messages)
+	}
+
+	if st.DroppedErrors() == 0 {
+		t.Fatal("expected errors to be dropped")
+	}
+}
+
+func TestStreamDecodesMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		check   func(*coinbase.StreamMessage) bool
+		err     error
+	}{
+		{
+			name:    "level2 data is delivered on the level2 channel",
+			message: `{"channel":"l2_data","sequence_num":0,"events":[{"type":"snapshot","product_id":"BTC-USD","updates":[{"side":"bid","price_level":"100","new_quantity":"1"}]}]}`,
+			check: func(msg *coinbase.StreamMessage) bool {
+				return msg.Channel == coinbase.ChannelLevel2 && len(msg.Level2) == 1 && msg.Level2[0].Updates[0].PriceLevel == "100"
+			},
+		},
+		{
+			name:    "ticker",
+			message: `{"channel":"ticker","sequence_num":0,"events":[{"type":"update","tickers":[{"product_id":"BTC-USD","price":"42"}]}]}`,
+			check: func(msg *coinbase.StreamMessage) bool {
+				return len(msg.Tickers) == 1 && *msg.Tickers[0].Tickers[0].Price == "42"
+			},
+		},
+		{
+			name:    "unknown channels are delivered without events",
+			message: `{"channel":"futures_balance_summary","sequence_num":0,"events":[{}]}`,
+			check: func(msg *coinbase.StreamMessage) bool {
+				return msg.Channel == "futures_balance_summary"
+			},
+		},
+		{
+			name:    "error message",
+			message: `{"type":"error","message":"failure to subscribe"}`,
+			err:     &coinbase.StreamError{Message: "failure to subscribe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeed(t)
+			client := coinbase.NewClient(coinbase.WithWebSocketURL(f.url))
+
+			st, err := client.Stream.Connect(context.Background(), coinbase.StreamOptions{DisableReconnect: true})
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer st.Close()
+
+			conn := f.accept(t)
+			conn.CloseRead(context.Background())
+
+			err = conn.Write(context.Background(), websocket.MessageText, []byte(tt.message))
+			if err != nil {
+				t.Fatalf("failed to write message: %v", err)
+			}
+
+			if tt.err != nil {
+				if err := nextError(t, st); err.Error() != tt.err.Error() {
+					t.Fatalf("error %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if msg := nextMessage(t, st); !tt.check(msg) {
+				t.Fatalf("unexpected message %+v", msg)
+			}
+		})
+	}
+}
+
+func TestUserStreamReconcilesOrdersCompletedWhileDisconnected(t *testing.T) {
+	f := newFeed(t)
+
+	// Order a is only returned once the feed has moved on, it must not hold up the stream.
+	release := make(chan struct{})
+
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/brokerage/orders/historical/a" {
+			http.NotFound(w, r)
+			return
+		}
+
+		<-release
+
+		fmt.Fprint(w, `{"order":{"order_id":"a","product_id":"BTC-USD","status":"FILLED","filled_size":"3","filled_value":"400","total_fees":"2"}}`)
+	}))
+	defer rest.Close()
+
+	name, secret, err := coinbasetest.NewCloudKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	client, err := coinbase.NewWithCloud(name, secret, coinbase.WithUserWebSocketURL(f.url), coinbase.WithBaseURL(rest.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	st, err := client.Stream.ConnectUser(context.Background(), coinbase.StreamOptions{})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer st.Close()
+
+	writeUser := func(conn *websocket.Conn, events string) {
+		t.Helper()
+
+		msg := `{"channel":"user","timestamp":"2024-01-01T00:00:00Z","sequence_num":0,"events":` + events + `}`
+
+		err := conn.Write(context.Background(), websocket.MessageText, []byte(msg))
+		if err != nil {
+			t.Fatalf("failed to write message: %v", err)
+		}
+	}
+
+	conn := f.accept(t)
+	readSubscription(t, conn)
+
+	writeUser(conn, `[{"type":"snapshot","orders":[{"order_id":"a","product_id":"BTC-USD","status":"OPEN","cumulative_quantity":"1","filled_value":"100","total_fees":"0.5"}]}]`)
+
+	if msg := nextMessage(t, st); len(msg.Fills) != 0 {
+		t.Fatalf("snapshot reported fills %v", msg.Fills)
+	}
+
+	conn.CloseNow()
+	nextError(t, st)
+
+	conn = f.accept(t)
+	readSubscription(t, conn)
+	conn.CloseRead(context.Background())
+
+	// Order a completed while disconnected, so the new snapshot no longer has it.
+	writeUser(conn, `[{"type":"snapshot","orders":[]}]`)
+	writeUser(conn, `[{"type":"update","orders":[{"order_id":"b","product_id":"BTC-USD","status":"OPEN","cumulative_quantity":"0","filled_value":"0","total_fees":"0"}]}]`)
+
+	for i := 0; i < 2; i++ {
+		if msg := nextMessage(t, st); len(msg.Fills) != 0 || msg.Reconciled {
+			t.Fatalf("message %d reported fills %v", i, msg.Fills)
+		}
+	}
+
+	close(release)
+
+	msg := nextMessage(t, st)
+	if !msg.Reconciled || len(msg.Fills) != 1 {
+		t.Fatalf("reported %d fills, want the final fill of order a", len(msg.Fills))
+	}
+
+	if fill := msg.Fills[0]; *fill.OrderID != "a" || *fill.Size != "2" || *fill.Price != "150" || *fill.Commission != "1.5" {
+		t.Fatalf("fill %s %s@%s+%s, want a 2@150+1.5", *fill.OrderID, *fill.Size, *fill.Price, *fill.Commission)
+	}
+}
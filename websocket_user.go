@@ -0,0 +1,424 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Order as reported by the user channel of the WebSocket feed.
+type UserOrder struct {
+	OrderID               string         `json:"order_id"`                // The unique id for this order.
+	ClientOrderID         string         `json:"client_order_id"`         // Client specified ID of order.
+	ProductID             string         `json:"product_id"`              // The product this order was created for e.g. 'BTC-USD'.
+	ProductType           *ProductType   `json:"product_type"`            // Possible values: [SPOT, FUTURE].
+	OrderSide             *Side          `json:"order_side"`              // Side the order is on (BUY, SELL).
+	OrderType             *string        `json:"order_type"`              // Type of the order, i.e. 'Limit'.
+	Status                *OrderStatus   `json:"status"`                  // Status of the order.
+	TimeInForce           *TimeInForce   `json:"time_in_force"`           // How long the order remains on the book.
+	TriggerStatus         *TriggerStatus `json:"trigger_status"`          // Trigger status of stop orders.
+	CreationTime          *time.Time     `json:"creation_time"`           // Timestamp for when the order was created.
+	CumulativeQuantity    *string        `json:"cumulative_quantity"`     // Amount the order has been filled, in base currency.
+	LeavesQuantity        *string        `json:"leaves_quantity"`         // Amount remaining to be filled, in base currency.
+	AveragePrice          *string        `json:"avg_price"`               // The average of all prices of fills for this order.
+	FilledValue           *string        `json:"filled_value"`            // The amount -- in quote currency -- of the order that has been filled.
+	NumberOfFills         *string        `json:"number_of_fills"`         // Number of fills that have been posted for this order.
+	CompletionPercentage  *string        `json:"completion_percentage"`   // The percent of total order amount that has been filled.
+	TotalFees             *string        `json:"total_fees"`              // The total fees for the order.
+	TotalValueAfterFees   *string        `json:"total_value_after_fees"`  // Filled value including fees.
+	OutstandingHoldAmount *string        `json:"outstanding_hold_amount"` // The remaining hold amount for the order.
+	LimitPrice            *string        `json:"limit_price"`             // Limit price of the order, zero for market orders.
+	StopPrice             *string        `json:"stop_price"`              // Stop price of the order, zero for orders without a stop.
+	CancelReason          *string        `json:"cancel_reason"`           // Message stating why the order was canceled.
+	RejectReason          *string        `json:"reject_Reason"`           // Message stating why the order was rejected.
+	RetailPortfolioID     *string        `json:"retail_portfolio_id"`     // Retail portfolio the order belongs to.
+}
+
+// Order converts the update into the Order model used by the REST API.
+// Only the fields published on the user channel are populated.
+func (o UserOrder) Order() Order {
+	order := Order{
+		ID:                    o.OrderID,
+		ProductID:             o.ProductID,
+		ClientOrderID:         o.ClientOrderID,
+		Side:                  o.OrderSide,
+		Status:                o.Status,
+		TimeInForce:           o.TimeInForce,
+		FilledSize:            o.CumulativeQuantity,
+		FilledValue:           o.FilledValue,
+		TriggerStatus:         o.TriggerStatus,
+		ProductType:           o.ProductType,
+		RejectMessage:         o.RejectReason,
+		CancelMessage:         o.CancelReason,
+		OutstandingHoldAmount: o.OutstandingHoldAmount,
+		CompletionPercentage:  derefString(o.CompletionPercentage),
+		AverageFilledPrice:    derefString(o.AveragePrice),
+		NumberOfFills:         derefString(o.NumberOfFills),
+		TotalFees:             derefString(o.TotalFees),
+		TotalValueAfterFees:   derefString(o.TotalValueAfterFees),
+	}
+
+	if o.CreationTime != nil {
+		order.CreatedTime = *o.CreationTime
+	}
+
+	if o.OrderType != nil {
+		t := normalizeOrderType(*o.OrderType)
+		order.Type = &t
+	}
+
+	if o.Status != nil {
+		order.Settled = Bool(*o.Status == OrderStatusFilled)
+	}
+
+	return order
+}
+
+// The user channel reports order types in title case ('Limit', 'StopLimit'), unlike the REST API.
+func normalizeOrderType(t string) OrderType {
+	switch strings.ToUpper(strings.ReplaceAll(t, "_", "")) {
+	case "MARKET":
+		return OrderTypeMarket
+	case "LIMIT":
+		return OrderTypeLimt
+	case "STOP":
+		return OrderTypeStop
+	case "STOPLIMIT":
+		return OrderTypeStopLimit
+	case "BRACKET":
+		return OrderTypeBracket
+	default:
+		return OrderType(strings.ToUpper(t))
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+type UserPerpetualFuturesPosition struct {
+	ProductID        *string              `json:"product_id"`
+	PortfolioUUID    *string              `json:"portfolio_uuid"`
+	VWAP             *string              `json:"vwap"`
+	EntryVWAP        *string              `json:"entry_vwap"`
+	PositionSide     *FuturesPositionSide `json:"position_side"`
+	MarginType       *MarginType          `json:"margin_type"`
+	NetSize          *string              `json:"net_size"`
+	BuyOrderSize     *string              `json:"buy_order_size"`
+	SellOrderSize    *string              `json:"sell_order_size"`
+	Leverage         *string              `json:"leverage"`
+	MarkPrice        *string              `json:"mark_price"`
+	LiquidationPrice *string              `json:"liquidation_price"`
+	IMNotional       *string              `json:"im_notional"`
+	MMNotional       *string              `json:"mm_notional"`
+	PositionNotional *string              `json:"position_notional"`
+	UnrealizedPNL    *string              `json:"unrealized_pnl"`
+	AggregatedPNL    *string              `json:"aggregated_pnl"`
+}
+
+type UserExpiringFuturesPosition struct {
+	ProductID         *string      `json:"product_id"`
+	Side              *FuturesSide `json:"side"`
+	NumberOfContracts *string      `json:"number_of_contracts"`
+	RealizedPNL       *string      `json:"realized_pnl"`
+	UnrealizedPNL     *string      `json:"unrealized_pnl"`
+	EntryPrice        *string      `json:"entry_price"`
+}
+
+type UserPositions struct {
+	PerpetualFuturesPositions []UserPerpetualFuturesPosition `json:"perpetual_futures_positions"`
+	ExpiringFuturesPositions  []UserExpiringFuturesPosition  `json:"expiring_futures_positions"`
+}
+
+// The first event on the user channel is a snapshot of all open orders, followed by an update
+// every time one of the user's orders changes.
+type UserEvent struct {
+	Type      StreamEventType `json:"type"`
+	Orders    []UserOrder     `json:"orders"`
+	Positions *UserPositions  `json:"positions"`
+}
+
+// userSnapshotBatchSize is the number of orders in each message of the user channel snapshot, a
+// smaller batch is the last one.
+const userSnapshotBatchSize = 50
+
+const (
+	reconcileTimeout     = 30 * time.Second // Bounds the requests made to reconcile the orders missing from a snapshot.
+	reconcileConcurrency = 4                // Orders fetched at once while reconciling.
+)
+
+// Running totals of an order, used to work out what was filled between two updates.
+type orderProgress struct {
+	quantity *big.Rat
+	value    *big.Rat
+	fees     *big.Rat
+}
+
+// fillTracker derives fills from the cumulative totals reported on the user channel.
+type fillTracker struct {
+	orders  map[string]orderProgress // Totals of the open orders, by ID.
+	missing map[string]struct{}      // Orders not yet seen in the snapshot sent after reconnecting.
+}
+
+func newFillTracker() fillTracker {
+	return fillTracker{orders: map[string]orderProgress{}}
+}
+
+// expectSnapshot is called after reconnecting. Orders that are missing from the snapshot that follows
+// were completed while the stream was disconnected.
+func (t *fillTracker) expectSnapshot() {
+	if len(t.orders) == 0 {
+		return
+	}
+
+	t.missing = make(map[string]struct{}, len(t.orders))
+
+	for id := range t.orders {
+		t.missing[id] = struct{}{}
+	}
+}
+
+// endOfSnapshot returns the orders missing from the snapshot once all of it has been received, which
+// is when a batch is smaller than a full one or the first update arrives.
+func (t *fillTracker) endOfSnapshot(event UserEvent) []string {
+	if t.missing == nil || (event.Type == StreamEventTypeSnapshot && len(event.Orders) >= userSnapshotBatchSize) {
+		return nil
+	}
+
+	ids := make([]string, 0, len(t.missing))
+	for id := range t.missing {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	t.missing = nil
+
+	return ids
+}
+
+// take stops tracking the orders and returns their last known totals.
+func (t *fillTracker) take(ids []string) map[string]orderProgress {
+	progress := make(map[string]orderProgress, len(ids))
+
+	for _, id := range ids {
+		if p, ok := t.orders[id]; ok {
+			progress[id] = p
+		}
+
+		delete(t.orders, id)
+	}
+
+	return progress
+}
+
+// track records the latest totals of the order and returns the fill that happened since the
+// previous update, if any. Orders first seen in a snapshot only have their totals recorded as
+// their fill history is unknown, orders first seen in an update are new and start from zero.
+func (t *fillTracker) track(o UserOrder, snapshot bool, ts time.Time) (*Fill, error) {
+	current, err := parseOrderProgress(o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to track fills of order '%s': %w", o.OrderID, err)
+	}
+
+	previous, seen := t.orders[o.OrderID]
+
+	delete(t.missing, o.OrderID)
+
+	if o.Status != nil && isTerminalOrderStatus(*o.Status) {
+		delete(t.orders, o.OrderID)
+	} else {
+		t.orders[o.OrderID] = current
+	}
+
+	if !seen {
+		if snapshot {
+			return nil, nil
+		}
+
+		previous = orderProgress{quantity: new(big.Rat), value: new(big.Rat), fees: new(big.Rat)}
+	}
+
+	return fillSince(o, previous, current, ts), nil
+}
+
+// fillSince returns the fill between two totals of the order, nil if nothing was filled.
+func fillSince(o UserOrder, previous, current orderProgress, ts time.Time) *Fill {
+	size := new(big.Rat).Sub(current.quantity, previous.quantity)
+	if size.Sign() <= 0 {
+		return nil
+	}
+
+	value := new(big.Rat).Sub(current.value, previous.value)
+	price := new(big.Rat).Quo(value, size)
+	commission := new(big.Rat).Sub(current.fees, previous.fees)
+
+	tradeType := TradeTypeFill
+
+	return &Fill{
+		OrderID:    String(o.OrderID),
+		ProductID:  String(o.ProductID),
+		TradeTime:  Time(ts),
+		TradeType:  &tradeType,
+		Price:      String(formatRat(price)),
+		Size:       String(formatRat(size)),
+		Commission: String(formatRat(commission)),
+		Side:       o.OrderSide,
+	}
+}
+
+// reconcileFills fetches the orders missing from the snapshot sent after reconnecting in the background,
+// and delivers what they were filled while the stream was disconnected on a message of its own. The
+// orders are no longer tracked, those that cannot be fetched are reported on the error channel.
+func (st *Stream) reconcileFills(ids []string, ts time.Time) {
+	progress := st.fills.take(ids)
+
+	st.reconciling.Add(1)
+
+	go func() {
+		defer st.reconciling.Done()
+
+		ctx, cancel := context.WithTimeout(st.ctx, reconcileTimeout)
+		defer cancel()
+
+		fills := make([]*Fill, len(ids))
+		sem := make(chan struct{}, reconcileConcurrency)
+
+		var wg sync.WaitGroup
+
+		for i, id := range ids {
+			previous, ok := progress[id]
+			if !ok {
+				continue
+			}
+
+			wg.Add(1)
+			sem <- struct{}{}
+
+			go func(i int, id string, previous orderProgress) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				fill, err := st.reconcileOrder(ctx, id, previous, ts)
+				if err != nil {
+					st.reportError(err)
+				}
+
+				fills[i] = fill
+			}(i, id, previous)
+		}
+
+		wg.Wait()
+
+		msg := &StreamMessage{Channel: ChannelUser, Timestamp: ts, Reconciled: true}
+
+		for _, fill := range fills {
+			if fill != nil {
+				msg.Fills = append(msg.Fills, *fill)
+			}
+		}
+
+		if len(msg.Fills) == 0 {
+			return
+		}
+
+		select {
+		case st.messages <- msg:
+		case <-st.ctx.Done():
+		}
+	}()
+}
+
+// reconcileOrder fetches the order and returns what was filled since its previous totals.
+func (st *Stream) reconcileOrder(ctx context.Context, id string, previous orderProgress, ts time.Time) (*Fill, error) {
+	order, err := st.client.Orders.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile fills of order '%s': %w", id, err)
+	}
+
+	o := userOrderProgress(*order)
+
+	current, err := parseOrderProgress(o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile fills of order '%s': %w", id, err)
+	}
+
+	return fillSince(o, previous, current, ts), nil
+}
+
+// userOrderProgress converts the fields of the order used to track fills.
+func userOrderProgress(o Order) UserOrder {
+	return UserOrder{
+		OrderID:            o.ID,
+		ProductID:          o.ProductID,
+		OrderSide:          o.Side,
+		Status:             o.Status,
+		CumulativeQuantity: o.FilledSize,
+		FilledValue:        o.FilledValue,
+		TotalFees:          String(o.TotalFees),
+	}
+}
+
+func parseOrderProgress(o UserOrder) (orderProgress, error) {
+	var p orderProgress
+
+	for _, f := range []struct {
+		dst  **big.Rat
+		src  *string
+		name string
+	}{
+		{&p.quantity, o.CumulativeQuantity, "cumulative_quantity"},
+		{&p.value, o.FilledValue, "filled_value"},
+		{&p.fees, o.TotalFees, "total_fees"},
+	} {
+		r := new(big.Rat)
+
+		if f.src != nil && *f.src != "" {
+			if _, ok := r.SetString(*f.src); !ok {
+				return p, fmt.Errorf("invalid %s '%s'", f.name, *f.src)
+			}
+		}
+
+		*f.dst = r
+	}
+
+	return p, nil
+}
+
+// formatRat formats the number as a decimal string without trailing zeros.
+func formatRat(r *big.Rat) string {
+	s := r.FloatString(16)
+
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+
+	if s == "" || s == "-" {
+		return "0"
+	}
+
+	return s
+}
+
+func isTerminalOrderStatus(s OrderStatus) bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCancelled, OrderStatusExpired, OrderStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
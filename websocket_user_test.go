@@ -0,0 +1,159 @@
+// Copyright 2024 Justin Simmons.
+//
+// This file is part of go-coinbase.
+// go-coinbase is free software: you can redistribute it and/or modify it under the terms of the GNU Affero General Public License as published by the Free Software Foundation, either version 3 of the License, or any later version.
+// go-coinbase is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+// You should have received a copy of the GNU Affero General Public License along with go-coinbase. If not, see <https://www.gnu.org/licenses/>.
+
+package coinbase
+
+import (
+	"testing"
+	"time"
+)
+
+func userOrder(id string, status OrderStatus, quantity, value, fees string) UserOrder {
+	return UserOrder{
+		OrderID:            id,
+		ProductID:          "BTC-USD",
+		Status:             &status,
+		CumulativeQuantity: String(quantity),
+		FilledValue:        String(value),
+		TotalFees:          String(fees),
+	}
+}
+
+func TestFillTrackerTrack(t *testing.T) {
+	type update struct {
+		order    UserOrder
+		snapshot bool
+		fill     string // Expected size@price+commission, empty if no fill.
+	}
+
+	tests := []struct {
+		name    string
+		updates []update
+		tracked int
+	}{
+		{
+			name: "new order filled in two steps",
+			updates: []update{
+				{order: userOrder("a", OrderStatusOpen, "0", "0", "0")},
+				{order: userOrder("a", OrderStatusOpen, "1", "100", "0.5"), fill: "1@100+0.5"},
+				{order: userOrder("a", OrderStatusFilled, "3", "400", "2"), fill: "2@150+1.5"},
+			},
+		},
+		{
+			name: "snapshot only records totals",
+			updates: []update{
+				{order: userOrder("a", OrderStatusOpen, "1", "100", "0.5"), snapshot: true},
+				{order: userOrder("a", OrderStatusOpen, "1.5", "160", "0.75"), fill: "0.5@120+0.25"},
+			},
+			tracked: 1,
+		},
+		{
+			name: "order first seen in an update starts from zero",
+			updates: []update{
+				{order: userOrder("a", OrderStatusFilled, "2", "50", "0"), fill: "2@25+0"},
+			},
+		},
+		{
+			name: "unchanged totals are not a fill",
+			updates: []update{
+				{order: userOrder("a", OrderStatusOpen, "1", "100", "0"), snapshot: true},
+				{order: userOrder("a", OrderStatusCancelled, "1", "100", "0")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newFillTracker()
+
+			for i, u := range tt.updates {
+				fill, err := tracker.track(u.order, u.snapshot, time.Time{})
+				if err != nil {
+					t.Fatalf("update %d: %v", i, err)
+				}
+
+				var got string
+				if fill != nil {
+					got = *fill.Size + "@" + *fill.Price + "+" + *fill.Commission
+				}
+
+				if got != u.fill {
+					t.Fatalf("update %d: fill %q, want %q", i, got, u.fill)
+				}
+			}
+
+			if len(tracker.orders) != tt.tracked {
+				t.Fatalf("tracking %d orders, want %d", len(tracker.orders), tt.tracked)
+			}
+		})
+	}
+}
+
+func TestFillTrackerEndOfSnapshot(t *testing.T) {
+	full := make([]UserOrder, userSnapshotBatchSize)
+	for i := range full {
+		full[i] = userOrder("b", OrderStatusOpen, "0", "0", "0")
+	}
+
+	tests := []struct {
+		name    string
+		events  []UserEvent
+		missing []string // Orders reported missing by the last event.
+	}{
+		{
+			name:    "order missing from the snapshot",
+			events:  []UserEvent{{Type: StreamEventTypeSnapshot, Orders: []UserOrder{userOrder("a", OrderStatusOpen, "0", "0", "0")}}},
+			missing: []string{"c"},
+		},
+		{
+			name:   "full batch waits for the rest of the snapshot",
+			events: []UserEvent{{Type: StreamEventTypeSnapshot, Orders: full}},
+		},
+		{
+			name:    "update ends the snapshot",
+			events:  []UserEvent{{Type: StreamEventTypeSnapshot, Orders: full}, {Type: StreamEventTypeUpdate}},
+			missing: []string{"a", "c"},
+		},
+		{
+			name:    "empty snapshot",
+			events:  []UserEvent{{Type: StreamEventTypeSnapshot}},
+			missing: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newFillTracker()
+
+			for _, id := range []string{"a", "c"} {
+				_, _ = tracker.track(userOrder(id, OrderStatusOpen, "0", "0", "0"), true, time.Time{})
+			}
+
+			tracker.expectSnapshot()
+
+			var missing []string
+
+			for _, event := range tt.events {
+				for _, order := range event.Orders {
+					_, _ = tracker.track(order, event.Type == StreamEventTypeSnapshot, time.Time{})
+				}
+
+				missing = tracker.endOfSnapshot(event)
+			}
+
+			if len(missing) != len(tt.missing) {
+				t.Fatalf("missing %v, want %v", missing, tt.missing)
+			}
+
+			for i := range missing {
+				if missing[i] != tt.missing[i] {
+					t.Fatalf("missing %v, want %v", missing, tt.missing)
+				}
+			}
+		})
+	}
+}